@@ -0,0 +1,9 @@
+package capten
+
+import "embed"
+
+// DefaultFiles holds the config and template files shipped with the release,
+// capten init writes them into a new workspace.
+//
+//go:embed config/setup_apps.yaml templates/k3s/values.aws.tmpl templates/k3s/values.azure.tmpl
+var DefaultFiles embed.FS
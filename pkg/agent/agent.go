@@ -2,12 +2,13 @@ package agent
 
 import (
 	"capten/pkg/config"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
-	"time"
-
 	"os"
+	"sync"
+	"time"
 
 	"capten/pkg/agent/pb/agentpb"
 	"capten/pkg/agent/pb/captenpluginspb"
@@ -15,11 +16,28 @@ import (
 	"capten/pkg/agent/pb/pluginstorepb"
 	"capten/pkg/agent/pb/vaultcredpb"
 
-	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/timeout"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/retry"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	DefaultCallTimeout = 60 * time.Second
+	maxCallAttempts    = 3
+	retryBackoff       = 200 * time.Millisecond
+	// the agent server rejects pings more frequent than its keepalive enforcement policy, 5 minutes by default
+	keepaliveTime    = 5 * time.Minute
+	keepaliveTimeout = 20 * time.Second
+)
+
+var (
+	callTimeout   = DefaultCallTimeout
+	connPool      = map[string]*grpc.ClientConn{}
+	connPoolMutex sync.Mutex
 )
 
 func GetAgentClient(config config.CaptenConfig) (agentpb.AgentClient, error) {
@@ -55,60 +73,92 @@ func GetCaptenPluginClient(config config.CaptenConfig) (captenpluginspb.CaptenPl
 }
 
 func getAgentClient(config config.CaptenConfig) (*grpc.ClientConn, error) {
-	agentEndpoint := config.GetCaptenAgentEndpoint()
-	authorityAgentHost := fmt.Sprintf("%s.%s", config.AgentHostName, config.DomainName)
-
-	var conn *grpc.ClientConn
-	var err error
-	if config.AgentSecure {
-		tlsCredentials, err := loadTLSCredentials(config)
-		if err != nil {
-			return nil, errors.WithMessagef(err, "failed to load capten agent client certs")
-		}
-
-		conn, err = grpc.Dial(agentEndpoint, grpc.WithTransportCredentials(tlsCredentials), grpc.WithAuthority(authorityAgentHost))
-		if err != nil {
-			return nil, errors.WithMessagef(err, "failed to connect to capten agent")
-		}
-	} else {
-		conn, err = grpc.Dial(agentEndpoint, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithAuthority(authorityAgentHost))
-		if err != nil {
-			return nil, errors.WithMessagef(err, "failed to connect to capten agent")
-		}
+	conn, err := getConnection(config, fmt.Sprintf("%s.%s", config.AgentHostName, config.DomainName))
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to connect to capten agent")
 	}
 	return conn, nil
 }
 
 func GetVaultClient(config config.CaptenConfig) (vaultcredpb.VaultCredClient, error) {
-	agentEndpoint := config.GetCaptenAgentEndpoint()
-	authorityVaultCredHost := fmt.Sprintf("%s.%s", config.VaultCredHostName, config.DomainName)
-
-	var conn *grpc.ClientConn
-	var err error
-	dialOptions := []grpc.DialOption{
-		grpc.WithUnaryInterceptor(timeout.UnaryClientInterceptor(60 * time.Second)),
-		grpc.WithAuthority(authorityVaultCredHost),
+	conn, err := getConnection(config, fmt.Sprintf("%s.%s", config.VaultCredHostName, config.DomainName))
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to connect to vault client")
+	}
+	return vaultcredpb.NewVaultCredClient(conn), nil
+}
+
+// getConnection returns the connection of the agent endpoint and authority, the connection is
+// dialed once and shared by all the clients until CloseConnections
+func getConnection(config config.CaptenConfig, authority string) (*grpc.ClientConn, error) {
+	endpoint := config.GetCaptenAgentEndpoint()
+	key := fmt.Sprintf("%s|%s|%t|%s", endpoint, authority, config.AgentSecure, config.CertDirPath)
+
+	connPoolMutex.Lock()
+	defer connPoolMutex.Unlock()
+	if conn, ok := connPool[key]; ok {
+		return conn, nil
 	}
+
+	dialOptions := connectionDialOptions(authority)
 	if config.AgentSecure {
 		tlsCredentials, err := loadTLSCredentials(config)
 		if err != nil {
 			return nil, errors.WithMessagef(err, "failed to load capten agent client certs")
 		}
-
 		dialOptions = append(dialOptions, grpc.WithTransportCredentials(tlsCredentials))
-		conn, err = grpc.Dial(agentEndpoint, dialOptions...)
-		if err != nil {
-			return nil, errors.WithMessagef(err, "failed to connect to vault client")
-		}
 	} else {
 		dialOptions = append(dialOptions, grpc.WithTransportCredentials(insecure.NewCredentials()))
-		conn, err = grpc.Dial(agentEndpoint, dialOptions...)
-		if err != nil {
-			return nil, errors.WithMessagef(err, "failed to connect to capten agent")
+	}
+
+	conn, err := grpc.Dial(endpoint, dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+	connPool[key] = conn
+	return conn, nil
+}
+
+// connectionDialOptions keeps the idle connection alive and retries the calls failing
+// with Unavailable with an exponential backoff, the transport credentials are added by the caller
+func connectionDialOptions(authority string) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithAuthority(authority),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{Time: keepaliveTime, Timeout: keepaliveTimeout}),
+		grpc.WithChainUnaryInterceptor(retry.UnaryClientInterceptor(
+			retry.WithCodes(codes.Unavailable),
+			retry.WithMax(maxCallAttempts),
+			retry.WithBackoff(retry.BackoffExponentialWithJitter(retryBackoff, 0.2)),
+		)),
+	}
+}
+
+// CloseConnections closes the agent connections opened by the command
+func CloseConnections() error {
+	connPoolMutex.Lock()
+	defer connPoolMutex.Unlock()
+
+	var closeErr error
+	for key, conn := range connPool {
+		if err := conn.Close(); err != nil && closeErr == nil {
+			closeErr = errors.WithMessagef(err, "failed to close connection to %s", conn.Target())
 		}
+		delete(connPool, key)
 	}
-	return vaultcredpb.NewVaultCredClient(conn), nil
+	return closeErr
+}
+
+func SetCallTimeout(timeout time.Duration) error {
+	if timeout <= 0 {
+		return fmt.Errorf("timeout must be greater than 0, got %s", timeout)
+	}
+	callTimeout = timeout
+	return nil
+}
 
+// callContext bounds a single agent call, including its retries, to the call timeout
+func callContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), callTimeout)
 }
 
 func loadTLSCredentials(captenConfig config.CaptenConfig) (credentials.TransportCredentials, error) {
@@ -4,12 +4,20 @@ import (
 	"capten/pkg/agent/pb/agentpb"
 	"capten/pkg/agent/pb/vaultcredpb"
 	"capten/pkg/config"
+	"context"
+	"net"
 	"os"
+	"sync/atomic"
+	"time"
 
 	//"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 func TestGetAgentClient(t *testing.T) {
@@ -204,3 +212,101 @@ func TestLoadTLSCredentials(t *testing.T) {
 		t.Errorf("Expected no error, got %v", err)
 	}
 }
+
+func TestGetConnectionReused(t *testing.T) {
+	CloseConnections()
+	defer CloseConnections()
+	captenConfig := config.CaptenConfig{
+		AgentHostName:       "captenagent",
+		CaptenClusterHost:   config.CaptenClusterHost{LoadBalancerHost: "127.0.0.1"},
+		CaptenClusterValues: config.CaptenClusterValues{DomainName: "example.com"},
+	}
+
+	agentConn, err := getAgentClient(captenConfig)
+	assert.NoError(t, err)
+	sameConn, err := getAgentClient(captenConfig)
+	assert.NoError(t, err)
+	assert.Same(t, agentConn, sameConn)
+
+	vaultConn, err := getConnection(captenConfig, "vault-cred.example.com")
+	assert.NoError(t, err)
+	assert.NotSame(t, agentConn, vaultConn)
+	assert.Len(t, connPool, 2)
+
+	assert.NoError(t, CloseConnections())
+	assert.Empty(t, connPool)
+}
+
+type testAgentServer struct {
+	agentpb.UnimplementedAgentServer
+	calls       atomic.Int32
+	failedCalls int32
+	delay       time.Duration
+}
+
+func (s *testAgentServer) GetClusterApps(ctx context.Context, req *agentpb.GetClusterAppsRequest) (*agentpb.GetClusterAppsResponse, error) {
+	if s.calls.Add(1) <= s.failedCalls {
+		return nil, status.Error(codes.Unavailable, "agent not ready")
+	}
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return &agentpb.GetClusterAppsResponse{}, nil
+}
+
+func startTestAgentServer(t *testing.T, server *testAgentServer) agentpb.AgentClient {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen, %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	agentpb.RegisterAgentServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial(listener.Addr().String(),
+		append(connectionDialOptions("captenagent"), grpc.WithTransportCredentials(insecure.NewCredentials()))...)
+	if err != nil {
+		t.Fatalf("failed to dial test agent, %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return agentpb.NewAgentClient(conn)
+}
+
+func TestCallRetries(t *testing.T) {
+	tests := []struct {
+		name        string
+		failedCalls int32
+		wantCalls   int32
+		wantCode    codes.Code
+	}{
+		{name: "Recovers after unavailable", failedCalls: 2, wantCalls: 3, wantCode: codes.OK},
+		{name: "Gives up after max retries", failedCalls: 10, wantCalls: maxCallAttempts, wantCode: codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &testAgentServer{failedCalls: tt.failedCalls}
+			client := startTestAgentServer(t, server)
+
+			ctx, cancel := callContext()
+			defer cancel()
+			_, err := client.GetClusterApps(ctx, &agentpb.GetClusterAppsRequest{})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantCalls, server.calls.Load())
+		})
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	defer SetCallTimeout(DefaultCallTimeout)
+	assert.Error(t, SetCallTimeout(0))
+	assert.NoError(t, SetCallTimeout(100*time.Millisecond))
+
+	client := startTestAgentServer(t, &testAgentServer{delay: 5 * time.Second})
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err := client.GetClusterApps(ctx, &agentpb.GetClusterAppsRequest{})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
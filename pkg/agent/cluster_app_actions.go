@@ -4,67 +4,67 @@ import (
 	"capten/pkg/agent/pb/agentpb"
 	"capten/pkg/clog"
 	"capten/pkg/config"
-	"context"
 	"fmt"
-	"os"
 	"time"
-
-	"github.com/olekukonko/tablewriter"
 )
 
-func ListClusterApplications(captenConfig config.CaptenConfig) error {
+func ListClusterApplications(captenConfig config.CaptenConfig) (ClusterApps, error) {
 	client, err := GetAgentClient(captenConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	resp, err := client.GetClusterApps(context.TODO(), &agentpb.GetClusterAppsRequest{})
+	ctx, cancel := callContext()
+	defer cancel()
+	resp, err := client.GetClusterApps(ctx, &agentpb.GetClusterAppsRequest{})
 	if err != nil {
-		return err
-	}
-
-	if len(resp.AppData) == 0 {
-		clog.Logger.Info("No apps found on cluster")
-		return nil
+		return nil, err
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Category", "Name", "Version", "Status"})
+	apps := ClusterApps{}
 	for _, clusterApp := range resp.AppData {
-		table.Append([]string{clusterApp.Config.Category, clusterApp.Config.ReleaseName, clusterApp.Config.Version, clusterApp.Config.InstallStatus})
+		apps = append(apps, ClusterApp{
+			Name:           clusterApp.Config.ReleaseName,
+			Category:       clusterApp.Config.Category,
+			Version:        clusterApp.Config.Version,
+			InstallStatus:  clusterApp.Config.InstallStatus,
+			RuntimeStatus:  clusterApp.Config.RuntimeStatus,
+			Namespace:      clusterApp.Config.Namespace,
+			ChartName:      clusterApp.Config.ChartName,
+			UiEndpoint:     clusterApp.Config.UiEndpoint,
+			LastUpdateTime: clusterApp.Config.LastUpdateTime,
+		})
 	}
-	table.Render()
-	return nil
+	return apps, nil
 }
 
-func ShowClusterAppData(captenConfig config.CaptenConfig, appName string) error {
+func ShowClusterAppData(captenConfig config.CaptenConfig, appName string) (ClusterAppDetails, error) {
 	client, err := GetAgentClient(captenConfig)
 	if err != nil {
-		return err
+		return ClusterAppDetails{}, err
 	}
 
-	resp, err := client.GetClusterAppConfig(context.TODO(), &agentpb.GetClusterAppConfigRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	resp, err := client.GetClusterAppConfig(ctx, &agentpb.GetClusterAppConfigRequest{
 		ReleaseName: appName,
 	})
 	if err != nil {
-		return err
+		return ClusterAppDetails{}, err
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Attribute", "Value"})
-	table.Append([]string{"app-name", resp.AppConfig.ReleaseName})
-	table.Append([]string{"version", resp.AppConfig.Version})
-	table.Append([]string{"helr-repo-url", resp.AppConfig.RepoURL})
-	table.Append([]string{"category", resp.AppConfig.Category})
-	table.Append([]string{"description", resp.AppConfig.Description})
-	table.Append([]string{"namespace", resp.AppConfig.Namespace})
-	table.Append([]string{"ui-module-endpoint", resp.AppConfig.UiModuleEndpoint})
-	table.Append([]string{"ui-endpoint", resp.AppConfig.UiEndpoint})
-	table.Append([]string{"api-endpoint", resp.AppConfig.ApiEndpoint})
-	table.Append([]string{"install-status", resp.AppConfig.InstallStatus})
-
-	table.Render()
-	return err
+	return ClusterAppDetails{
+		Name:             resp.AppConfig.ReleaseName,
+		Version:          resp.AppConfig.Version,
+		HelmRepoURL:      resp.AppConfig.RepoURL,
+		Category:         resp.AppConfig.Category,
+		Description:      resp.AppConfig.Description,
+		Namespace:        resp.AppConfig.Namespace,
+		UiModuleEndpoint: resp.AppConfig.UiModuleEndpoint,
+		UiEndpoint:       resp.AppConfig.UiEndpoint,
+		ApiEndpoint:      resp.AppConfig.ApiEndpoint,
+		InstallStatus:    resp.AppConfig.InstallStatus,
+	}, nil
 }
 
 func DeployDefaultApps(captenConfig config.CaptenConfig) error {
@@ -73,7 +73,9 @@ func DeployDefaultApps(captenConfig config.CaptenConfig) error {
 		return err
 	}
 
-	_, err = client.DeployDefaultApps(context.TODO(), &agentpb.DeployDefaultAppsRequest{})
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = client.DeployDefaultApps(ctx, &agentpb.DeployDefaultAppsRequest{})
 	if err != nil {
 		return err
 	}
@@ -112,7 +114,9 @@ func GetDefaultAppsDeploymentStatus(captenConfig config.CaptenConfig) (bool, str
 		return completed, defaultAppsDeploymentStatus, err
 	}
 
-	resp, err := client.GetDefaultAppsStatus(context.TODO(), &agentpb.GetDefaultAppsStatusRequest{})
+	ctx, cancel := callContext()
+	defer cancel()
+	resp, err := client.GetDefaultAppsStatus(ctx, &agentpb.GetDefaultAppsStatusRequest{})
 	if err != nil {
 		return completed, defaultAppsDeploymentStatus, err
 	}
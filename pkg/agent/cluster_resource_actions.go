@@ -4,75 +4,64 @@ import (
 	"capten/pkg/agent/pb/captenpluginspb"
 	"capten/pkg/clog"
 	"capten/pkg/config"
-	"context"
+	"capten/pkg/output"
 	"fmt"
 	"log"
-	"os"
 	"strings"
-
-	"github.com/olekukonko/tablewriter"
 )
 
-func ListClusterResources(captenConfig config.CaptenConfig, resourceType string) error {
+func ListClusterResources(captenConfig config.CaptenConfig, resourceType string) (output.List, error) {
 	client, err := GetCaptenPluginClient(captenConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
 	switch resourceType {
 	case "git-project":
-		projects, err := client.GetGitProjects(context.TODO(), &captenpluginspb.GetGitProjectsRequest{})
+		ctx, cancel := callContext()
+		defer cancel()
+		resp, err := client.GetGitProjects(ctx, &captenpluginspb.GetGitProjectsRequest{})
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		if len(projects.Projects) == 0 {
-			clog.Logger.Info("No git projects added to cluster")
-			return nil
+		projects := GitProjects{}
+		for _, project := range resp.Projects {
+			projects = append(projects, GitProject{ID: project.Id, ProjectURL: project.ProjectUrl,
+				Labels: project.Labels, LastUpdateTime: project.LastUpdateTime})
 		}
-
-		table.SetHeader([]string{"ID", "Project URL", "Labels"})
-		for _, project := range projects.Projects {
-			table.Append([]string{project.Id, project.ProjectUrl, strings.Join(project.Labels, ",")})
-		}
-		table.Render()
+		return projects, nil
 	case "cloud-provider":
-		providers, err := client.GetCloudProviders(context.TODO(), &captenpluginspb.GetCloudProvidersRequest{})
+		ctx, cancel := callContext()
+		defer cancel()
+		resp, err := client.GetCloudProviders(ctx, &captenpluginspb.GetCloudProvidersRequest{})
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		if len(providers.CloudProviders) == 0 {
-			clog.Logger.Info("No cloud providers added to cluster")
-			return nil
+		providers := CloudProviders{}
+		for _, provider := range resp.CloudProviders {
+			providers = append(providers, CloudProvider{ID: provider.Id, CloudType: provider.CloudType,
+				Labels: provider.Labels, LastUpdateTime: provider.LastUpdateTime})
 		}
-
-		table.SetHeader([]string{"ID", "Cloud Type", "Labels"})
-		for _, provider := range providers.CloudProviders {
-			table.Append([]string{provider.Id, provider.CloudType, strings.Join(provider.Labels, ",")})
-		}
-		table.Render()
+		return providers, nil
 	case "container-registry":
-		registries, err := client.GetContainerRegistry(context.TODO(), &captenpluginspb.GetContainerRegistryRequest{})
+		ctx, cancel := callContext()
+		defer cancel()
+		resp, err := client.GetContainerRegistry(ctx, &captenpluginspb.GetContainerRegistryRequest{})
 		if err != nil {
-			return err
-		}
-
-		if len(registries.Registries) == 0 {
-			clog.Logger.Info("No container registries added to cluster")
-			return nil
+			return nil, err
 		}
 
-		table.SetHeader([]string{"ID", "Registry Type", "Registry URL", "Labels"})
-		for _, registry := range registries.Registries {
-			table.Append([]string{registry.Id, registry.RegistryType, registry.RegistryUrl, strings.Join(registry.Labels, ",")})
+		registries := ContainerRegistries{}
+		for _, registry := range resp.Registries {
+			registries = append(registries, ContainerRegistry{ID: registry.Id, RegistryType: registry.RegistryType,
+				RegistryURL: registry.RegistryUrl, Labels: registry.Labels, LastUpdateTime: registry.LastUpdateTime})
 		}
-		table.Render()
+		return registries, nil
 	default:
-		return fmt.Errorf("invalid resource type: %s", resourceType)
+		return nil, fmt.Errorf("invalid resource type: %s", resourceType)
 	}
-	return nil
 }
 
 func AddClusterResource(captenConfig config.CaptenConfig, resourceType string, attributes map[string]string) error {
@@ -83,7 +72,9 @@ func AddClusterResource(captenConfig config.CaptenConfig, resourceType string, a
 
 	switch resourceType {
 	case "git-project":
-		_, err = client.AddGitProject(context.TODO(), &captenpluginspb.AddGitProjectRequest{
+		ctx, cancel := callContext()
+		defer cancel()
+		_, err = client.AddGitProject(ctx, &captenpluginspb.AddGitProjectRequest{
 			ProjectUrl:  attributes["git-project-url"],
 			Labels:      strings.Split(attributes["labels"], ","),
 			AccessToken: attributes["access-token"],
@@ -96,13 +87,17 @@ func AddClusterResource(captenConfig config.CaptenConfig, resourceType string, a
 			return err
 		}
 
-		_, err = client.AddCloudProvider(context.TODO(), &captenpluginspb.AddCloudProviderRequest{
+		ctx, cancel := callContext()
+		defer cancel()
+		_, err = client.AddCloudProvider(ctx, &captenpluginspb.AddCloudProviderRequest{
 			CloudType:       attributes["cloud-type"],
 			Labels:          strings.Split(attributes["labels"], ","),
 			CloudAttributes: cloudAttributes,
 		})
 	case "container-registry":
-		_, err = client.AddContainerRegistry(context.TODO(), &captenpluginspb.AddContainerRegistryRequest{
+		ctx, cancel := callContext()
+		defer cancel()
+		_, err = client.AddContainerRegistry(ctx, &captenpluginspb.AddContainerRegistryRequest{
 			RegistryUrl:  attributes["registry-url"],
 			Labels:       strings.Split(attributes["labels"], ","),
 			RegistryType: attributes["registry-type"],
@@ -132,7 +127,9 @@ func UpdateClusterResource(captenConfig config.CaptenConfig, resourceType, id st
 
 	switch resourceType {
 	case "git-project":
-		_, err = client.UpdateGitProject(context.TODO(), &captenpluginspb.UpdateGitProjectRequest{
+		ctx, cancel := callContext()
+		defer cancel()
+		_, err = client.UpdateGitProject(ctx, &captenpluginspb.UpdateGitProjectRequest{
 			Id:          id,
 			ProjectUrl:  attributes["git-project-url"],
 			Labels:      strings.Split(attributes["labels"], ","),
@@ -146,14 +143,18 @@ func UpdateClusterResource(captenConfig config.CaptenConfig, resourceType, id st
 			return err
 		}
 
-		_, err = client.UpdateCloudProvider(context.TODO(), &captenpluginspb.UpdateCloudProviderRequest{
+		ctx, cancel := callContext()
+		defer cancel()
+		_, err = client.UpdateCloudProvider(ctx, &captenpluginspb.UpdateCloudProviderRequest{
 			Id:              id,
 			CloudType:       attributes["cloud-type"],
 			Labels:          strings.Split(attributes["labels"], ","),
 			CloudAttributes: cloudAttributes,
 		})
 	case "container-registry":
-		_, err = client.UpdateContainerRegistry(context.TODO(), &captenpluginspb.UpdateContainerRegistryRequest{
+		ctx, cancel := callContext()
+		defer cancel()
+		_, err = client.UpdateContainerRegistry(ctx, &captenpluginspb.UpdateContainerRegistryRequest{
 			Id:           id,
 			RegistryUrl:  attributes["registry-url"],
 			Labels:       strings.Split(attributes["labels"], ","),
@@ -198,15 +199,21 @@ func RemoveClusterResource(captenConfig config.CaptenConfig, resourceType, id st
 
 	switch resourceType {
 	case "git-project":
-		_, err = client.DeleteGitProject(context.TODO(), &captenpluginspb.DeleteGitProjectRequest{
+		ctx, cancel := callContext()
+		defer cancel()
+		_, err = client.DeleteGitProject(ctx, &captenpluginspb.DeleteGitProjectRequest{
 			Id: id,
 		})
 	case "cloud-provider":
-		_, err = client.DeleteCloudProvider(context.TODO(), &captenpluginspb.DeleteCloudProviderRequest{
+		ctx, cancel := callContext()
+		defer cancel()
+		_, err = client.DeleteCloudProvider(ctx, &captenpluginspb.DeleteCloudProviderRequest{
 			Id: id,
 		})
 	case "container-registry":
-		_, err = client.DeleteContainerRegistry(context.TODO(), &captenpluginspb.DeleteContainerRegistryRequest{
+		ctx, cancel := callContext()
+		defer cancel()
+		_, err = client.DeleteContainerRegistry(ctx, &captenpluginspb.DeleteContainerRegistryRequest{
 			Id: id,
 		})
 	default:
@@ -0,0 +1,236 @@
+package agent
+
+import (
+	"capten/pkg/agent/pb/vaultcredpb"
+	"capten/pkg/config"
+	"capten/pkg/types"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	CredentialStored   = "Stored"
+	CredentialNotFound = "NotFound"
+	CredentialError    = "Error"
+
+	credentialMask = "********"
+)
+
+var credentialTypes = []string{genericCredentailType, serviceCredentailType}
+
+type CredentialRef struct {
+	Type       string `json:"type" yaml:"type"`
+	Entity     string `json:"entity" yaml:"entity"`
+	Identifier string `json:"identifier" yaml:"identifier"`
+}
+
+func (ref CredentialRef) String() string {
+	return fmt.Sprintf("%s/%s/%s", ref.Type, ref.Entity, ref.Identifier)
+}
+
+// ParseCredentialRef parses a credential path of the form <type>/<entity>/<identifier>
+func ParseCredentialRef(path string) (CredentialRef, error) {
+	parts := strings.SplitN(path, "/", 3)
+	if len(parts) != 3 || len(parts[0]) == 0 || len(parts[1]) == 0 || len(parts[2]) == 0 {
+		return CredentialRef{}, fmt.Errorf("invalid credential path '%s', expected <type>/<entity>/<identifier>", path)
+	}
+	ref := CredentialRef{Type: parts[0], Entity: parts[1], Identifier: parts[2]}
+	if !slices.Contains(credentialTypes, ref.Type) {
+		return CredentialRef{}, fmt.Errorf("credential type '%s' is not supported, supported types: %s",
+			ref.Type, strings.Join(credentialTypes, ", "))
+	}
+	return ref, nil
+}
+
+func GetCredential(captenConfig config.CaptenConfig, ref CredentialRef) (Credential, error) {
+	vaultClient, err := GetVaultClient(captenConfig)
+	if err != nil {
+		return Credential{}, err
+	}
+	return getCredential(vaultClient, ref)
+}
+
+// PutCredential stores the values as the credential of the ref, the existing values of the
+// credential are replaced unless merge is set, in which case only the given keys are updated
+func PutCredential(captenConfig config.CaptenConfig, ref CredentialRef, values map[string]string, merge bool) error {
+	if len(values) == 0 {
+		return fmt.Errorf("no credential values given")
+	}
+	vaultClient, err := GetVaultClient(captenConfig)
+	if err != nil {
+		return err
+	}
+	return putCredential(vaultClient, ref, values, merge)
+}
+
+func DeleteCredential(captenConfig config.CaptenConfig, ref CredentialRef) error {
+	vaultClient, err := GetVaultClient(captenConfig)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = vaultClient.DeleteCredential(ctx, &vaultcredpb.DeleteCredentialRequest{
+		CredentialType: ref.Type,
+		CredEntityName: ref.Entity,
+		CredIdentifier: ref.Identifier,
+	})
+	if err != nil {
+		return errors.WithMessagef(err, "failed to delete credential %s", ref)
+	}
+	return nil
+}
+
+// ListCredentials looks up the credentials stored by capten, the cluster credentials and the credentials
+// of the app credential configs, vault-cred has no list call so other credentials are not listed
+func ListCredentials(captenConfig config.CaptenConfig) (CredentialStatuses, error) {
+	refs, err := knownCredentialRefs(captenConfig)
+	if err != nil {
+		return nil, err
+	}
+	vaultClient, err := GetVaultClient(captenConfig)
+	if err != nil {
+		return nil, err
+	}
+	return credentialStatuses(vaultClient, refs), nil
+}
+
+func getCredential(vaultClient vaultcredpb.VaultCredClient, ref CredentialRef) (Credential, error) {
+	ctx, cancel := callContext()
+	defer cancel()
+	resp, err := vaultClient.GetCredential(ctx, &vaultcredpb.GetCredentialRequest{
+		CredentialType: ref.Type,
+		CredEntityName: ref.Entity,
+		CredIdentifier: ref.Identifier,
+	})
+	if err != nil {
+		return Credential{}, errors.WithMessagef(err, "failed to get credential %s", ref)
+	}
+	return Credential{CredentialRef: ref, Values: resp.Credential}, nil
+}
+
+func putCredential(vaultClient vaultcredpb.VaultCredClient, ref CredentialRef, values map[string]string, merge bool) error {
+	credential := map[string]string{}
+	if merge {
+		existing, err := getCredential(vaultClient, ref)
+		if err != nil && !isCredentialNotFound(err) {
+			return err
+		}
+		for key, value := range existing.Values {
+			credential[key] = value
+		}
+	}
+	for key, value := range values {
+		credential[key] = value
+	}
+
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err := vaultClient.PutCredential(ctx, &vaultcredpb.PutCredentialRequest{
+		CredentialType: ref.Type,
+		CredEntityName: ref.Entity,
+		CredIdentifier: ref.Identifier,
+		Credential:     credential,
+	})
+	if err != nil {
+		return errors.WithMessagef(err, "failed to store credential %s", ref)
+	}
+	return nil
+}
+
+func credentialStatuses(vaultClient vaultcredpb.VaultCredClient, refs []CredentialRef) CredentialStatuses {
+	statuses := CredentialStatuses{}
+	for _, ref := range refs {
+		credentialStatus := CredentialStatus{CredentialRef: ref, Status: CredentialStored}
+		credential, err := getCredential(vaultClient, ref)
+		switch {
+		case isCredentialNotFound(err):
+			credentialStatus.Status = CredentialNotFound
+		case err != nil:
+			credentialStatus.Status = CredentialError
+			credentialStatus.Error = err.Error()
+		default:
+			credentialStatus.Keys = credential.Keys()
+		}
+		statuses = append(statuses, credentialStatus)
+	}
+	return statuses
+}
+
+func isCredentialNotFound(err error) bool {
+	return err != nil && (status.Code(errors.Cause(err)) == codes.NotFound || strings.Contains(err.Error(), "secret not found"))
+}
+
+func knownCredentialRefs(captenConfig config.CaptenConfig) ([]CredentialRef, error) {
+	refs := []CredentialRef{
+		{Type: genericCredentailType, Entity: k8sCredEntityName, Identifier: kubeconfigCredIdentifier},
+		{Type: genericCredentailType, Entity: captenConfigEntityName, Identifier: globalValuesCredIdentifier},
+		{Type: genericCredentailType, Entity: s3BucketCredEntityName, Identifier: terraformStateCredIdentifier},
+	}
+
+	credConfigs, err := loadAppCredentialConfigs(captenConfig)
+	if err != nil {
+		return nil, err
+	}
+	for _, credConfig := range credConfigs {
+		for _, ref := range appCredentialRefs(credConfig) {
+			if !slices.Contains(refs, ref) {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs, nil
+}
+
+func loadAppCredentialConfigs(captenConfig config.CaptenConfig) ([]types.CredentialAppConfig, error) {
+	dirPath := captenConfig.PrepareDirPath(captenConfig.AppsConfigDirPath + captenConfig.AppsCredentialDirPath)
+	files, err := os.ReadDir(dirPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.WithMessagef(err, "failed to read app credential configs in %s", dirPath)
+	}
+
+	credConfigs := []types.CredentialAppConfig{}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		filePath := filepath.Join(dirPath, file.Name())
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to read app credential config %s", filePath)
+		}
+		var credConfig types.CredentialAppConfig
+		if err := yaml.Unmarshal(data, &credConfig); err != nil {
+			return nil, errors.WithMessagef(err, "failed to parse app credential config %s", filePath)
+		}
+		credConfigs = append(credConfigs, credConfig)
+	}
+	return credConfigs, nil
+}
+
+// appCredentialRefs returns the credentials stored for an app credential config by storeCredentials
+func appCredentialRefs(credConfig types.CredentialAppConfig) []CredentialRef {
+	switch credConfig.CredentialType {
+	case "cosign", "randomkey":
+		return []CredentialRef{{Type: genericCredentailType, Entity: credConfig.CredentialEntity, Identifier: credConfig.CredentialIdentifier}}
+	case "temporal-password":
+		return []CredentialRef{
+			{Type: serviceCredentailType, Entity: credConfig.CredentialEntity, Identifier: credConfig.CredentialIdentifier},
+			{Type: serviceCredentailType, Entity: postgresAdminCredConfig().CredentialEntity, Identifier: postgresAdminCredConfig().CredentialIdentifier},
+		}
+	default:
+		return []CredentialRef{{Type: serviceCredentailType, Entity: credConfig.CredentialEntity, Identifier: credConfig.CredentialIdentifier}}
+	}
+}
@@ -0,0 +1,169 @@
+package agent
+
+import (
+	"capten/pkg/agent/pb/vaultcredpb"
+	"capten/pkg/config"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeVaultCredClient struct {
+	vaultcredpb.VaultCredClient
+	credentials map[string]map[string]string
+	getErr      error
+}
+
+func credentialPath(credentialType, entity, identifier string) string {
+	return CredentialRef{Type: credentialType, Entity: entity, Identifier: identifier}.String()
+}
+
+func (c *fakeVaultCredClient) GetCredential(ctx context.Context, in *vaultcredpb.GetCredentialRequest, opts ...grpc.CallOption) (*vaultcredpb.GetCredentialResponse, error) {
+	if c.getErr != nil {
+		return nil, c.getErr
+	}
+	credential, ok := c.credentials[credentialPath(in.CredentialType, in.CredEntityName, in.CredIdentifier)]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "secret not found")
+	}
+	return &vaultcredpb.GetCredentialResponse{Credential: credential}, nil
+}
+
+func (c *fakeVaultCredClient) PutCredential(ctx context.Context, in *vaultcredpb.PutCredentialRequest, opts ...grpc.CallOption) (*vaultcredpb.PutCredentialResponse, error) {
+	c.credentials[credentialPath(in.CredentialType, in.CredEntityName, in.CredIdentifier)] = in.Credential
+	return &vaultcredpb.PutCredentialResponse{}, nil
+}
+
+func TestParseCredentialRef(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    CredentialRef
+		wantErr bool
+	}{
+		{path: "generic/k8s/kubeconfig", want: CredentialRef{Type: "generic", Entity: "k8s", Identifier: "kubeconfig"}},
+		{path: "service-cred/postgres/postgres-admin", want: CredentialRef{Type: "service-cred", Entity: "postgres", Identifier: "postgres-admin"}},
+		{path: "generic/k8s", wantErr: true},
+		{path: "generic//kubeconfig", wantErr: true},
+		{path: "client-cert/k8s/kubeconfig", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ParseCredentialRef(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCredentialRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCredentialRef() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCredentialMasked(t *testing.T) {
+	credential := Credential{Values: map[string]string{"username": "admin", "password": "secret"}}
+	masked := credential.Masked()
+	if !reflect.DeepEqual(masked.Keys(), []string{"password", "username"}) {
+		t.Errorf("Masked() keys = %v", masked.Keys())
+	}
+	for key, value := range masked.Values {
+		if value != credentialMask {
+			t.Errorf("Masked() %s = %s, want masked", key, value)
+		}
+	}
+	if credential.Values["password"] != "secret" {
+		t.Error("Masked() changed the credential values")
+	}
+}
+
+func TestPutCredential(t *testing.T) {
+	ref := CredentialRef{Type: "service-cred", Entity: "postgres", Identifier: "postgres-admin"}
+	client := &fakeVaultCredClient{credentials: map[string]map[string]string{
+		ref.String(): {"username": "postgres", "password": "old"},
+	}}
+
+	if err := putCredential(client, ref, map[string]string{"password": "new"}, true); err != nil {
+		t.Fatalf("putCredential() merge error = %v", err)
+	}
+	if want := map[string]string{"username": "postgres", "password": "new"}; !reflect.DeepEqual(client.credentials[ref.String()], want) {
+		t.Errorf("putCredential() merge = %v, want %v", client.credentials[ref.String()], want)
+	}
+
+	if err := putCredential(client, ref, map[string]string{"password": "newer"}, false); err != nil {
+		t.Fatalf("putCredential() error = %v", err)
+	}
+	if want := map[string]string{"password": "newer"}; !reflect.DeepEqual(client.credentials[ref.String()], want) {
+		t.Errorf("putCredential() = %v, want %v", client.credentials[ref.String()], want)
+	}
+
+	newRef := CredentialRef{Type: "generic", Entity: "app", Identifier: "token"}
+	if err := putCredential(client, newRef, map[string]string{"token": "abc"}, true); err != nil {
+		t.Errorf("putCredential() merge of a new credential error = %v", err)
+	}
+}
+
+func TestCredentialStatuses(t *testing.T) {
+	stored := CredentialRef{Type: "generic", Entity: "k8s", Identifier: "kubeconfig"}
+	missing := CredentialRef{Type: "generic", Entity: "s3bucket", Identifier: "terraform-state"}
+	client := &fakeVaultCredClient{credentials: map[string]map[string]string{
+		stored.String(): {"kubeconfig": "apiVersion: v1"},
+	}}
+
+	statuses := credentialStatuses(client, []CredentialRef{stored, missing})
+	if statuses[0].Status != CredentialStored || !reflect.DeepEqual(statuses[0].Keys, []string{"kubeconfig"}) {
+		t.Errorf("credentialStatuses() stored = %+v", statuses[0])
+	}
+	if statuses[1].Status != CredentialNotFound {
+		t.Errorf("credentialStatuses() missing = %+v", statuses[1])
+	}
+
+	client.getErr = errors.New("permission denied")
+	if statuses := credentialStatuses(client, []CredentialRef{stored}); statuses[0].Status != CredentialError {
+		t.Errorf("credentialStatuses() error = %+v", statuses[0])
+	}
+}
+
+func TestKnownCredentialRefs(t *testing.T) {
+	captenConfig := config.CaptenConfig{
+		CurrentDirPath:        t.TempDir(),
+		AppsConfigDirPath:     "/apps/conf/",
+		AppsCredentialDirPath: "credentials/",
+	}
+	refs, err := knownCredentialRefs(captenConfig)
+	if err != nil || len(refs) != 3 {
+		t.Fatalf("knownCredentialRefs() without app credential configs = %v, %v", refs, err)
+	}
+
+	credDir := captenConfig.PrepareDirPath(captenConfig.AppsConfigDirPath + captenConfig.AppsCredentialDirPath)
+	if err := os.MkdirAll(credDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for fileName, data := range map[string]string{
+		"nats.yaml":     "credentialEntity: nats\ncredentialIdentifier: auth-token\ncredentialType: randomkey\n",
+		"temporal.yaml": "credentialEntity: temporal\ncredentialIdentifier: temporal-admin\ncredentialType: temporal-password\n",
+	} {
+		if err := os.WriteFile(filepath.Join(credDir, fileName), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	refs, err = knownCredentialRefs(captenConfig)
+	if err != nil {
+		t.Fatalf("knownCredentialRefs() error = %v", err)
+	}
+	want := []string{"generic/k8s/kubeconfig", "generic/capten-config/global-values", "generic/s3bucket/terraform-state",
+		"generic/nats/auth-token", "service-cred/temporal/temporal-admin", "service-cred/postgres/postgres-admin"}
+	got := []string{}
+	for _, ref := range refs {
+		got = append(got, ref.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("knownCredentialRefs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"capten/pkg/agent/pb/agentpb"
+	"capten/pkg/agent/pb/vaultcredpb"
+	"capten/pkg/config"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Ping(captenConfig config.CaptenConfig) error {
+	client, err := GetAgentClient(captenConfig)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := callContext()
+	defer cancel()
+	resp, err := client.Ping(ctx, &agentpb.PingRequest{})
+	if err != nil {
+		return err
+	}
+	if resp.Status != agentpb.StatusCode_OK {
+		return fmt.Errorf("agent ping returned %s", resp.Status)
+	}
+	return nil
+}
+
+// CheckVaultCred reads the kubeconfig credential stored during the cluster setup, an error
+// of the credential lookup is only returned when vault-cred is not reachable
+func CheckVaultCred(captenConfig config.CaptenConfig) (reachable bool, err error) {
+	client, err := GetVaultClient(captenConfig)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = client.GetCredential(ctx, &vaultcredpb.GetCredentialRequest{
+		CredentialType: genericCredentailType,
+		CredEntityName: k8sCredEntityName,
+		CredIdentifier: kubeconfigCredIdentifier,
+	})
+	switch status.Code(err) {
+	case codes.OK:
+		return true, nil
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled, codes.Unimplemented:
+		return false, err
+	default:
+		return true, err
+	}
+}
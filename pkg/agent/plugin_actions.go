@@ -3,38 +3,35 @@ package agent
 import (
 	"capten/pkg/agent/pb/clusterpluginspb"
 	"capten/pkg/agent/pb/pluginstorepb"
-	"capten/pkg/clog"
 	"capten/pkg/config"
-	"context"
 	"fmt"
-	"os"
-
-	"github.com/olekukonko/tablewriter"
 )
 
-func ListClusterPlugins(captenConfig config.CaptenConfig) error {
+func ListClusterPlugins(captenConfig config.CaptenConfig) (ClusterPlugins, error) {
 	client, err := GetClusterPluginClient(captenConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	resp, err := client.GetClusterPlugins(context.TODO(), &clusterpluginspb.GetClusterPluginsRequest{})
+	ctx, cancel := callContext()
+	defer cancel()
+	resp, err := client.GetClusterPlugins(ctx, &clusterpluginspb.GetClusterPluginsRequest{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if len(resp.Plugins) == 0 {
-		clog.Logger.Info("No plugins found on cluster")
-		return nil
-	}
-
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Category", "Name", "Version", "Store Type", "Status"})
+	plugins := ClusterPlugins{}
 	for _, plugin := range resp.Plugins {
-		table.Append([]string{plugin.Category, plugin.PluginName, plugin.Version, plugin.StoreType.String(), plugin.InstallStatus})
+		plugins = append(plugins, ClusterPlugin{
+			Name:          plugin.PluginName,
+			Category:      plugin.Category,
+			Version:       plugin.Version,
+			StoreType:     plugin.StoreType.String(),
+			InstallStatus: plugin.InstallStatus,
+			Description:   plugin.Description,
+		})
 	}
-	table.Render()
-	return nil
+	return plugins, nil
 }
 
 func DeployPlugin(captenConfig config.CaptenConfig, storeType, pluginName, version string) error {
@@ -47,7 +44,9 @@ func DeployPlugin(captenConfig config.CaptenConfig, storeType, pluginName, versi
 	if err != nil {
 		return err
 	}
-	_, err = client.DeployPlugin(context.TODO(), &pluginstorepb.DeployPluginRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = client.DeployPlugin(ctx, &pluginstorepb.DeployPluginRequest{
 		StoreType:  storeTypeEnum,
 		PluginName: pluginName,
 		Version:    version,
@@ -69,7 +68,9 @@ func UnDeployPlugin(captenConfig config.CaptenConfig, storeType, pluginName stri
 		return err
 	}
 
-	_, err = client.UnDeployPlugin(context.TODO(), &pluginstorepb.UnDeployPluginRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = client.UnDeployPlugin(ctx, &pluginstorepb.UnDeployPluginRequest{
 		StoreType:  storeTypeEnum,
 		PluginName: pluginName,
 	})
@@ -4,15 +4,13 @@ import (
 	"capten/pkg/agent/pb/captenpluginspb"
 	"capten/pkg/clog"
 	"capten/pkg/config"
-	"context"
+	"capten/pkg/output"
 	"fmt"
 	"os"
-
-	"github.com/olekukonko/tablewriter"
 )
 
 func ConfigureClusterPlugin(captenConfig config.CaptenConfig, pluginName, action string,
-	actionAttributes map[string]string) error {
+	actionAttributes map[string]string) (output.Tabular, error) {
 
 	switch pluginName {
 	case "crossplane":
@@ -20,90 +18,82 @@ func ConfigureClusterPlugin(captenConfig config.CaptenConfig, pluginName, action
 	case "tekton":
 		return configureTektonPlugin(captenConfig, action)
 	case "proact":
-		return fmt.Errorf("configure actions for plugin is not implemented yet")
+		return nil, fmt.Errorf("configure actions for plugin is not implemented yet")
 	default:
-		return fmt.Errorf("no configure actions for plugin supported")
+		return nil, fmt.Errorf("no configure actions for plugin supported")
 	}
 }
 
+// configureCrossplanePlugin returns the result of the list and show actions, the other actions return nil data
 func configureCrossplanePlugin(captenConfig config.CaptenConfig, action string,
-	actionAttributes map[string]string) error {
+	actionAttributes map[string]string) (output.Tabular, error) {
 	switch action {
 	case "list-actions":
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"Action", "Attributes"})
-		table.SetRowLine(true)
-
-		table.Append([]string{"show-crossplane-project", ""})
-		table.Append([]string{"synch-crossplane-project", ""})
-		table.Append([]string{"create-crossplane-provider", "cloud-type, cloud-provider-id"})
-		table.Append([]string{"update-crossplane-provider", "crossplane-provider-id, cloud-type, cloud-provider-id"})
-		table.Append([]string{"delete-crossplane-provider", "crossplane-provider-id"})
-		table.Append([]string{"list-crossplane-providers", ""})
-		table.Append([]string{"list-managed-clusters", ""})
-		table.Append([]string{"download-kubeconfig", "managed-cluster-id"})
-		table.SetAutoMergeCells(true)
-		table.Render()
+		return PluginActions{
+			{Action: "show-crossplane-project"},
+			{Action: "synch-crossplane-project"},
+			{Action: "create-crossplane-provider", Attributes: []string{"cloud-type", "cloud-provider-id"}},
+			{Action: "update-crossplane-provider", Attributes: []string{"crossplane-provider-id", "cloud-type", "cloud-provider-id"}},
+			{Action: "delete-crossplane-provider", Attributes: []string{"crossplane-provider-id"}},
+			{Action: "list-crossplane-providers"},
+			{Action: "list-managed-clusters"},
+			{Action: "download-kubeconfig", Attributes: []string{"managed-cluster-id"}},
+		}, nil
 	case "show-crossplane-project":
 		return showCrossplaneProject(captenConfig)
 	case "synch-crossplane-project":
-		return synchCrossplaneProject(captenConfig)
+		return nil, synchCrossplaneProject(captenConfig)
 	case "create-crossplane-provider":
-		return createCrossplaneProvider(captenConfig, actionAttributes)
+		return nil, createCrossplaneProvider(captenConfig, actionAttributes)
 	case "update-crossplane-provider":
-		return updateCrossplaneProvider(captenConfig, actionAttributes)
+		return nil, updateCrossplaneProvider(captenConfig, actionAttributes)
 	case "delete-crossplane-provider":
-		return deleteCrossplaneProvider(captenConfig, actionAttributes)
+		return nil, deleteCrossplaneProvider(captenConfig, actionAttributes)
 	case "list-crossplane-providers":
 		return listCrossplaneProviders(captenConfig)
 	case "list-managed-clusters":
 		return listManagedClusters(captenConfig)
 	case "download-kubeconfig":
-		return downloadKubeconfig(captenConfig, actionAttributes)
+		return nil, downloadKubeconfig(captenConfig, actionAttributes)
 	default:
-		return fmt.Errorf("action is not supported for plugin")
+		return nil, fmt.Errorf("action is not supported for plugin")
 	}
-	return nil
 }
 
-func configureTektonPlugin(captenConfig config.CaptenConfig, action string) error {
+func configureTektonPlugin(captenConfig config.CaptenConfig, action string) (output.Tabular, error) {
 	switch action {
 	case "list-actions":
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"Action", "Attributes"})
-		table.SetRowLine(true)
-
-		table.Append([]string{"show-tekton-project", ""})
-		table.Append([]string{"synch-tekton-project", ""})
-		table.SetAutoMergeCells(true)
-		table.Render()
+		return PluginActions{
+			{Action: "show-tekton-project"},
+			{Action: "synch-tekton-project"},
+		}, nil
 	case "show-tekton-project":
 		return showTektonProject(captenConfig)
 	case "synch-tekton-project":
-		return synchTektonProject(captenConfig)
+		return nil, synchTektonProject(captenConfig)
 	default:
-		return fmt.Errorf("action is not supported for plugin")
+		return nil, fmt.Errorf("action is not supported for plugin")
 	}
-	return nil
 }
 
-func showCrossplaneProject(captenConfig config.CaptenConfig) error {
+func showCrossplaneProject(captenConfig config.CaptenConfig) (output.Tabular, error) {
 	client, err := GetCaptenPluginClient(captenConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	resp, err := client.GetCrossplaneProject(context.TODO(), &captenpluginspb.GetCrossplaneProjectsRequest{})
+	ctx, cancel := callContext()
+	defer cancel()
+	resp, err := client.GetCrossplaneProject(ctx, &captenpluginspb.GetCrossplaneProjectsRequest{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Attribute", "Value"})
-	table.Append([]string{"git-project-url", resp.Project.GitProjectUrl})
-	table.Append([]string{"status", resp.Project.Status})
-	table.Render()
-	return nil
+	return PluginProject{
+		GitProjectURL:  resp.Project.GitProjectUrl,
+		Status:         resp.Project.Status,
+		LastUpdateTime: resp.Project.LastUpdateTime,
+	}, nil
 }
 
 func synchCrossplaneProject(captenConfig config.CaptenConfig) error {
@@ -112,7 +102,9 @@ func synchCrossplaneProject(captenConfig config.CaptenConfig) error {
 		return err
 	}
 
-	_, err = client.RegisterCrossplaneProject(context.TODO(), &captenpluginspb.RegisterCrossplaneProjectRequest{})
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = client.RegisterCrossplaneProject(ctx, &captenpluginspb.RegisterCrossplaneProjectRequest{})
 	if err != nil {
 		return err
 	}
@@ -126,7 +118,9 @@ func createCrossplaneProvider(captenConfig config.CaptenConfig, attributes map[s
 		return err
 	}
 
-	_, err = client.AddCrossplanProvider(context.TODO(), &captenpluginspb.AddCrossplanProviderRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = client.AddCrossplanProvider(ctx, &captenpluginspb.AddCrossplanProviderRequest{
 		CloudType:       attributes["cloud-type"],
 		CloudProviderId: attributes["cloud-provider-id"],
 	})
@@ -143,7 +137,9 @@ func updateCrossplaneProvider(captenConfig config.CaptenConfig, attributes map[s
 		return err
 	}
 
-	_, err = client.UpdateCrossplanProvider(context.TODO(), &captenpluginspb.UpdateCrossplanProviderRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = client.UpdateCrossplanProvider(ctx, &captenpluginspb.UpdateCrossplanProviderRequest{
 		Id:              attributes["crossplane-provider-id"],
 		CloudType:       attributes["cloud-type"],
 		CloudProviderId: attributes["cloud-provider-id"],
@@ -160,7 +156,9 @@ func deleteCrossplaneProvider(captenConfig config.CaptenConfig, attributes map[s
 		return err
 	}
 
-	_, err = client.DeleteCrossplanProvider(context.TODO(), &captenpluginspb.DeleteCrossplanProviderRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = client.DeleteCrossplanProvider(ctx, &captenpluginspb.DeleteCrossplanProviderRequest{
 		Id: attributes["crossplane-provider-id"],
 	})
 	if err != nil {
@@ -169,54 +167,57 @@ func deleteCrossplaneProvider(captenConfig config.CaptenConfig, attributes map[s
 	return nil
 }
 
-func listCrossplaneProviders(captenConfig config.CaptenConfig) error {
+func listCrossplaneProviders(captenConfig config.CaptenConfig) (output.Tabular, error) {
 	client, err := GetCaptenPluginClient(captenConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	resp, err := client.GetCrossplanProviders(context.TODO(), &captenpluginspb.GetCrossplanProvidersRequest{})
+	ctx, cancel := callContext()
+	defer cancel()
+	resp, err := client.GetCrossplanProviders(ctx, &captenpluginspb.GetCrossplanProvidersRequest{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if len(resp.Providers) == 0 {
-		clog.Logger.Info("No crossplane providers added to cluster")
-		return nil
-	}
-
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"ID", "Cloud Type", "Cloud Provider ID", "Status"})
+	providers := CrossplaneProviders{}
 	for _, provider := range resp.Providers {
-		table.Append([]string{provider.Id, provider.CloudType, provider.CloudProviderId, provider.Status})
+		providers = append(providers, CrossplaneProvider{
+			ID:              provider.Id,
+			CloudType:       provider.CloudType,
+			CloudProviderID: provider.CloudProviderId,
+			ProviderName:    provider.ProviderName,
+			Status:          provider.Status,
+		})
 	}
-	table.Render()
-	return nil
+	return providers, nil
 }
 
-func listManagedClusters(captenConfig config.CaptenConfig) error {
+func listManagedClusters(captenConfig config.CaptenConfig) (output.Tabular, error) {
 	client, err := GetCaptenPluginClient(captenConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	resp, err := client.GetManagedClusters(context.TODO(), &captenpluginspb.GetManagedClustersRequest{})
+	ctx, cancel := callContext()
+	defer cancel()
+	resp, err := client.GetManagedClusters(ctx, &captenpluginspb.GetManagedClustersRequest{})
 	if err != nil {
-		return err
-	}
-
-	if len(resp.Clusters) == 0 {
-		clog.Logger.Info("No managed clusters added to cluster")
-		return nil
+		return nil, err
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"ID", "Cluster Name", "Cluster Endpoint", "Cluster Deploy Status"})
+	clusters := ManagedClusters{}
 	for _, cluster := range resp.Clusters {
-		table.Append([]string{cluster.Id, cluster.ClusterName, cluster.ClusterEndpoint, cluster.ClusterDeployStatus})
+		clusters = append(clusters, ManagedCluster{
+			ID:                  cluster.Id,
+			ClusterName:         cluster.ClusterName,
+			ClusterEndpoint:     cluster.ClusterEndpoint,
+			ClusterDeployStatus: cluster.ClusterDeployStatus,
+			AppDeployStatus:     cluster.AppDeployStatus,
+			LastUpdateTime:      cluster.LastUpdateTime,
+		})
 	}
-	table.Render()
-	return nil
+	return clusters, nil
 }
 
 func downloadKubeconfig(captenConfig config.CaptenConfig, attributes map[string]string) error {
@@ -225,7 +226,9 @@ func downloadKubeconfig(captenConfig config.CaptenConfig, attributes map[string]
 		return err
 	}
 
-	resp, err := client.GetManagedClusterKubeconfig(context.TODO(), &captenpluginspb.GetManagedClusterKubeconfigRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	resp, err := client.GetManagedClusterKubeconfig(ctx, &captenpluginspb.GetManagedClusterKubeconfigRequest{
 		Id: attributes["managed-cluster-id"],
 	})
 	if err != nil {
@@ -242,23 +245,24 @@ func downloadKubeconfig(captenConfig config.CaptenConfig, attributes map[string]
 	return nil
 }
 
-func showTektonProject(captenConfig config.CaptenConfig) error {
+func showTektonProject(captenConfig config.CaptenConfig) (output.Tabular, error) {
 	client, err := GetCaptenPluginClient(captenConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	resp, err := client.GetTektonProject(context.TODO(), &captenpluginspb.GetTektonProjectRequest{})
+	ctx, cancel := callContext()
+	defer cancel()
+	resp, err := client.GetTektonProject(ctx, &captenpluginspb.GetTektonProjectRequest{})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Attribute", "Value"})
-	table.Append([]string{"git-project-url", resp.Project.GitProjectUrl})
-	table.Append([]string{"status", resp.Project.Status})
-	table.Render()
-	return nil
+	return PluginProject{
+		GitProjectURL:  resp.Project.GitProjectUrl,
+		Status:         resp.Project.Status,
+		LastUpdateTime: resp.Project.LastUpdateTime,
+	}, nil
 }
 
 func synchTektonProject(captenConfig config.CaptenConfig) error {
@@ -267,7 +271,9 @@ func synchTektonProject(captenConfig config.CaptenConfig) error {
 		return err
 	}
 
-	_, err = client.RegisterTektonProject(context.TODO(), &captenpluginspb.RegisterTektonProjectRequest{})
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = client.RegisterTektonProject(ctx, &captenpluginspb.RegisterTektonProjectRequest{})
 	if err != nil {
 		return err
 	}
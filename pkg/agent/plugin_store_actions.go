@@ -2,14 +2,8 @@ package agent
 
 import (
 	"capten/pkg/agent/pb/pluginstorepb"
-	"capten/pkg/clog"
 	"capten/pkg/config"
-	"context"
 	"fmt"
-	"os"
-	"strings"
-
-	"github.com/olekukonko/tablewriter"
 )
 
 func getStoreTypeEnum(storeType string) (pluginstorepb.StoreType, error) {
@@ -25,37 +19,38 @@ func getStoreTypeEnum(storeType string) (pluginstorepb.StoreType, error) {
 	}
 }
 
-func ListPluginStoreApps(captenConfig config.CaptenConfig, storeType string) error {
+func ListPluginStoreApps(captenConfig config.CaptenConfig, storeType string) (StorePlugins, error) {
 	client, err := GetPluginStoreClient(captenConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var storeTypeEnum pluginstorepb.StoreType
 	storeTypeEnum, err = getStoreTypeEnum(storeType)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	resp, err := client.GetPlugins(context.TODO(), &pluginstorepb.GetPluginsRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	resp, err := client.GetPlugins(ctx, &pluginstorepb.GetPluginsRequest{
 		StoreType: storeTypeEnum,
 	})
 	if err != nil {
-		return err
-	}
-
-	if len(resp.Plugins) == 0 {
-		clog.Logger.Info("No plugins found on plugin store")
-		return nil
+		return nil, err
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Category", "Name", "Version"})
+	plugins := StorePlugins{}
 	for _, plugin := range resp.Plugins {
-		table.Append([]string{plugin.Category, plugin.PluginName, strings.Join(plugin.Versions, ",")})
+		plugins = append(plugins, StorePlugin{
+			Name:        plugin.PluginName,
+			Category:    plugin.Category,
+			Versions:    plugin.Versions,
+			Description: plugin.Description,
+			StoreType:   storeType,
+		})
 	}
-	table.Render()
-	return nil
+	return plugins, nil
 }
 
 func ConfigPluginStore(captenConfig config.CaptenConfig, storeType, gitProjectId string) error {
@@ -70,7 +65,9 @@ func ConfigPluginStore(captenConfig config.CaptenConfig, storeType, gitProjectId
 		return err
 	}
 
-	_, err = client.ConfigurePluginStore(context.TODO(), &pluginstorepb.ConfigurePluginStoreRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = client.ConfigurePluginStore(ctx, &pluginstorepb.ConfigurePluginStoreRequest{
 		Config: &pluginstorepb.PluginStoreConfig{
 			StoreType:    storeTypeEnum,
 			GitProjectId: gitProjectId,
@@ -92,40 +89,42 @@ func SynchPluginStore(captenConfig config.CaptenConfig, storeType string) error
 		return err
 	}
 
-	_, err = client.SyncPluginStore(context.TODO(), &pluginstorepb.SyncPluginStoreRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = client.SyncPluginStore(ctx, &pluginstorepb.SyncPluginStoreRequest{
 		StoreType: storeTypeEnum,
 	})
 
 	return err
 }
 
-func ShowPluginStorePlugin(captenConfig config.CaptenConfig, storeType, pluginName string) error {
+func ShowPluginStorePlugin(captenConfig config.CaptenConfig, storeType, pluginName string) (StorePlugin, error) {
 	client, err := GetPluginStoreClient(captenConfig)
 	if err != nil {
-		return err
+		return StorePlugin{}, err
 	}
 
 	var storeTypeEnum pluginstorepb.StoreType
 	storeTypeEnum, err = getStoreTypeEnum(storeType)
 	if err != nil {
-		return err
+		return StorePlugin{}, err
 	}
 
-	resp, err := client.GetPluginData(context.TODO(), &pluginstorepb.GetPluginDataRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	resp, err := client.GetPluginData(ctx, &pluginstorepb.GetPluginDataRequest{
 		StoreType:  storeTypeEnum,
 		PluginName: pluginName,
 	})
 	if err != nil {
-		return err
+		return StorePlugin{}, err
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Attribute", "Value"})
-	table.Append([]string{"plugin-name", resp.PluginData.PluginName})
-	table.Append([]string{"category", resp.PluginData.Category})
-	table.Append([]string{"versions", strings.Join(resp.PluginData.Versions, ",")})
-	table.Append([]string{"description", resp.PluginData.Description})
-	table.Append([]string{"store-type", storeType})
-	table.Render()
-	return err
+	return StorePlugin{
+		Name:        resp.PluginData.PluginName,
+		Category:    resp.PluginData.Category,
+		Versions:    resp.PluginData.Versions,
+		Description: resp.PluginData.Description,
+		StoreType:   storeType,
+	}, nil
 }
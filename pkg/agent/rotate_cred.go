@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"capten/pkg/agent/pb/vaultcredpb"
+	"capten/pkg/clog"
+	"capten/pkg/config"
+	"capten/pkg/k8s"
+	"capten/pkg/types"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RotatedCredential is the credential stored with a new value and the secret configured from it
+type RotatedCredential struct {
+	Name       string
+	Ref        CredentialRef
+	SecretName string
+	Namespaces []string
+	value      string
+}
+
+// RotateCredential generates a new password or token for the credential config of the given name,
+// stores it in vault and configures the vault secret again in each namespace of the config. The password
+// of a database user is not changed in the database, so database passwords are only rotated with force
+func RotateCredential(captenConfig config.CaptenConfig, name string, force bool) (RotatedCredential, error) {
+	credConfigs, err := loadAppCredentialConfigs(captenConfig)
+	if err != nil {
+		return RotatedCredential{}, err
+	}
+	credConfig, temporalConfig, err := findRotatableCredential(credConfigs, name)
+	if err != nil {
+		return RotatedCredential{}, err
+	}
+
+	vaultClient, err := GetVaultClient(captenConfig)
+	if err != nil {
+		return RotatedCredential{}, err
+	}
+	return rotateCredential(captenConfig, vaultClient, credConfig, temporalConfig, force)
+}
+
+// RestartCredentialConsumers waits until the secret of the rotated credential is synced in each namespace
+// and restarts the workloads using it, the names of the restarted workloads are returned
+func RestartCredentialConsumers(captenConfig config.CaptenConfig, rotated RotatedCredential) ([]string, error) {
+	kubeconfigPath := captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.KubeConfigFileName)
+	restarted := []string{}
+	for _, namespace := range rotated.Namespaces {
+		if err := k8s.WaitForSecretValue(kubeconfigPath, namespace, rotated.SecretName, rotated.value); err != nil {
+			return restarted, err
+		}
+		workloads, err := k8s.RestartSecretConsumers(kubeconfigPath, namespace, rotated.SecretName)
+		for _, workload := range workloads {
+			restarted = append(restarted, namespace+"/"+workload)
+		}
+		if err != nil {
+			return restarted, err
+		}
+	}
+	return restarted, nil
+}
+
+// findRotatableCredential returns the credential config of the name, the postgres admin credential is
+// rotated by its own name and returned along with the temporal credential config its secret is shared with
+func findRotatableCredential(credConfigs []types.CredentialAppConfig, name string) (types.CredentialAppConfig, types.CredentialAppConfig, error) {
+	temporalIndex := slices.IndexFunc(credConfigs, func(credConfig types.CredentialAppConfig) bool {
+		return credConfig.CredentialType == "temporal-password"
+	})
+	if name == postgresAdminCredConfig().Name {
+		if temporalIndex < 0 {
+			return types.CredentialAppConfig{}, types.CredentialAppConfig{}, fmt.Errorf("credential %s is only stored along with a temporal-password credential config", name)
+		}
+		return postgresAdminCredConfig(), credConfigs[temporalIndex], nil
+	}
+
+	names := []string{}
+	for _, credConfig := range credConfigs {
+		if credConfig.Name != name {
+			names = append(names, credConfig.Name)
+			continue
+		}
+		switch credConfig.CredentialType {
+		case "cosign":
+			return types.CredentialAppConfig{}, types.CredentialAppConfig{}, fmt.Errorf("cosign keys of %s are not rotated, signatures made with the current key would no longer verify", name)
+		case "temporal-password":
+			return credConfig, credConfig, nil
+		case "randomkey", "clickhouse-password", "qt-password":
+			return credConfig, types.CredentialAppConfig{}, nil
+		default:
+			return types.CredentialAppConfig{}, types.CredentialAppConfig{}, fmt.Errorf("unknown credential type: %s", credConfig.CredentialType)
+		}
+	}
+	if temporalIndex >= 0 {
+		names = append(names, postgresAdminCredConfig().Name)
+	}
+	return types.CredentialAppConfig{}, types.CredentialAppConfig{}, fmt.Errorf("credential config '%s' not found, credential configs: %s", name, strings.Join(names, ", "))
+}
+
+func rotateCredential(captenConfig config.CaptenConfig, vaultClient vaultcredpb.VaultCredClient,
+	credConfig, temporalConfig types.CredentialAppConfig, force bool) (RotatedCredential, error) {
+	if credConfig.CredentialType != "randomkey" {
+		if !force {
+			return RotatedCredential{}, fmt.Errorf("credential %s is the password of database user %s, rotating it does not change "+
+				"the password in the database and the apps would fail to connect, change it there and rotate with force", credConfig.Name, credConfig.UserName)
+		}
+		clog.Logger.Warnf("The password of database user %s is not changed in the database, set it to the new password "+
+			"stored in vault before the apps using it are restarted", credConfig.UserName)
+	}
+
+	rotated := RotatedCredential{
+		Name:       credConfig.Name,
+		Ref:        CredentialRef{Type: serviceCredentailType, Entity: credConfig.CredentialEntity, Identifier: credConfig.CredentialIdentifier},
+		SecretName: credConfig.SecretName,
+		Namespaces: credConfig.Namespaces,
+	}
+
+	var credential map[string]string
+	if credConfig.CredentialType == "randomkey" {
+		token, err := randomTokenGeneration()
+		if err != nil {
+			return rotated, fmt.Errorf("nats Token generation failed, %v", err)
+		}
+		rotated.Ref.Type, rotated.value = genericCredentailType, token
+		credential = map[string]string{"token": token}
+	} else {
+		rotated.value = generatePassword()
+		credential = map[string]string{"username": credConfig.UserName, "password": rotated.value}
+	}
+
+	if err := putCredentialInVault(vaultClient, credConfig, credential, rotated.Ref.Type); err != nil {
+		return rotated, errors.WithMessagef(err, "failed to store credential %s", rotated.Ref)
+	}
+
+	var err error
+	switch credConfig.CredentialType {
+	case "randomkey":
+		err = configureNatsSecret(captenConfig, vaultClient, credConfig)
+	case "temporal-password", "postgres-password":
+		postgresConfig := postgresAdminCredConfig()
+		rotated.SecretName, rotated.Namespaces = postgresConfig.SecretName, postgresConfig.Namespaces
+		err = configurePostgresSecret(captenConfig, vaultClient, temporalConfig)
+	default:
+		err = configureDBPasswordSecret(captenConfig, vaultClient, credConfig)
+	}
+	if err != nil {
+		return rotated, errors.WithMessagef(err, "credential %s is rotated, failed to configure secret %s", rotated.Ref, rotated.SecretName)
+	}
+	return rotated, nil
+}
@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"capten/pkg/config"
+	"capten/pkg/types"
+	"testing"
+)
+
+func testCredentialConfigs() []types.CredentialAppConfig {
+	return []types.CredentialAppConfig{
+		{Name: "clickhouse", SecretName: "clickhouse-secret", CredentialEntity: "clickhouse", CredentialIdentifier: "clickhouse-admin",
+			CredentialType: "clickhouse-password", UserName: "admin"},
+		{Name: "cosign-cred", SecretName: "cosign-keys", CredentialEntity: "cosign", CredentialIdentifier: "signer", CredentialType: "cosign"},
+		{Name: "natscred", SecretName: "nats-token", CredentialEntity: "nats", CredentialIdentifier: "auth-token", CredentialType: "randomkey"},
+		{Name: "temporal-postgres", SecretName: "postgresql-secret", CredentialEntity: "postgres", CredentialIdentifier: "postgres-temporal",
+			CredentialType: "temporal-password", UserName: "temporal"},
+	}
+}
+
+func TestFindRotatableCredential(t *testing.T) {
+	tests := []struct {
+		name         string
+		credConfigs  []types.CredentialAppConfig
+		wantType     string
+		wantTemporal bool
+		wantErr      bool
+	}{
+		{name: "clickhouse", credConfigs: testCredentialConfigs(), wantType: "clickhouse-password"},
+		{name: "natscred", credConfigs: testCredentialConfigs(), wantType: "randomkey"},
+		{name: "temporal-postgres", credConfigs: testCredentialConfigs(), wantType: "temporal-password", wantTemporal: true},
+		{name: "postgres-cred", credConfigs: testCredentialConfigs(), wantType: "postgres-password", wantTemporal: true},
+		{name: "postgres-cred", credConfigs: testCredentialConfigs()[:3], wantErr: true},
+		{name: "cosign-cred", credConfigs: testCredentialConfigs(), wantErr: true},
+		{name: "unknown", credConfigs: testCredentialConfigs(), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credConfig, temporalConfig, err := findRotatableCredential(tt.credConfigs, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findRotatableCredential() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if credConfig.CredentialType != tt.wantType {
+				t.Errorf("findRotatableCredential() type = %s, want %s", credConfig.CredentialType, tt.wantType)
+			}
+			if (temporalConfig.Name == "temporal-postgres") != tt.wantTemporal {
+				t.Errorf("findRotatableCredential() temporal config = %+v", temporalConfig)
+			}
+		})
+	}
+}
+
+func TestRotateCredential(t *testing.T) {
+	tests := []struct {
+		name           string
+		wantPath       string
+		wantKey        string
+		wantSecretName string
+		force          bool
+		wantErr        bool
+	}{
+		{name: "clickhouse", wantPath: "service-cred/clickhouse/clickhouse-admin", wantKey: "password", wantSecretName: "clickhouse-secret", force: true},
+		{name: "natscred", wantPath: "generic/nats/auth-token", wantKey: "token", wantSecretName: "nats-token"},
+		{name: "temporal-postgres", wantPath: "service-cred/postgres/postgres-temporal", wantKey: "password", wantSecretName: "postgres-admin-secret", force: true, wantErr: true},
+		{name: "postgres-cred", wantPath: "service-cred/postgres/postgres-admin", wantKey: "password", wantSecretName: "postgres-admin-secret", force: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeVaultCredClient{credentials: map[string]map[string]string{
+				tt.wantPath: {tt.wantKey: "old"},
+			}}
+			credConfig, temporalConfig, err := findRotatableCredential(testCredentialConfigs(), tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// without namespaces the secrets are not configured on the cluster, the postgres secret
+			// has its own namespaces and fails to be configured after the credential is stored
+			credConfig.Namespaces = nil
+			rotated, err := rotateCredential(config.CaptenConfig{}, client, credConfig, temporalConfig, tt.force)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("rotateCredential() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			value := client.credentials[tt.wantPath][tt.wantKey]
+			if rotated.Ref.String() != tt.wantPath || value == "old" || value != rotated.value {
+				t.Errorf("rotateCredential() = %+v, stored %s = %s", rotated, tt.wantKey, value)
+			}
+			if rotated.SecretName != tt.wantSecretName {
+				t.Errorf("rotateCredential() secret = %s, want %s", rotated.SecretName, tt.wantSecretName)
+			}
+		})
+	}
+}
+
+func TestRotateCredentialDatabasePasswordWithoutForce(t *testing.T) {
+	for _, name := range []string{"clickhouse", "temporal-postgres", "postgres-cred"} {
+		t.Run(name, func(t *testing.T) {
+			client := &fakeVaultCredClient{credentials: map[string]map[string]string{}}
+			credConfig, temporalConfig, err := findRotatableCredential(testCredentialConfigs(), name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := rotateCredential(config.CaptenConfig{}, client, credConfig, temporalConfig, false); err == nil {
+				t.Error("rotateCredential() error = nil, want error")
+			}
+			if len(client.credentials) != 0 {
+				t.Errorf("rotateCredential() stored %v without force", client.credentials)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package agent
 import (
 	"capten/pkg/agent/pb/vaultcredpb"
 	"capten/pkg/clog"
-	"context"
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
@@ -98,7 +97,9 @@ func storeKubeConfig(captenConfig config.CaptenConfig, vaultClient vaultcredpb.V
 		kubeconfigCredIdentifier: string(configContent),
 	}
 
-	_, err = vaultClient.PutCredential(context.Background(), &vaultcredpb.PutCredentialRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = vaultClient.PutCredential(ctx, &vaultcredpb.PutCredentialRequest{
 		CredentialType: genericCredentailType,
 		CredEntityName: k8sCredEntityName,
 		CredIdentifier: kubeconfigCredIdentifier,
@@ -127,7 +128,9 @@ func storeClusterGlobalValues(captenConfig config.CaptenConfig, vaultClient vaul
 		globalValuesCredIdentifier: string(configContent) + "\n" + string(hostValues),
 	}
 
-	_, err = vaultClient.PutCredential(context.Background(), &vaultcredpb.PutCredentialRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = vaultClient.PutCredential(ctx, &vaultcredpb.PutCredentialRequest{
 		CredentialType: genericCredentailType,
 		CredEntityName: captenConfigEntityName,
 		CredIdentifier: globalValuesCredIdentifier,
@@ -153,7 +156,9 @@ func storeTerraformStateConfig(captenConfig config.CaptenConfig, vaultClient vau
 		terraformStateAwsSecretKey:  clusterInfo.AwsSecretKey,
 	}
 
-	_, err = vaultClient.PutCredential(context.Background(), &vaultcredpb.PutCredentialRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err = vaultClient.PutCredential(ctx, &vaultcredpb.PutCredentialRequest{
 		CredentialType: genericCredentailType,
 		CredEntityName: s3BucketCredEntityName,
 		CredIdentifier: terraformStateCredIdentifier,
@@ -248,7 +253,9 @@ func storeCredentials(captenConfig config.CaptenConfig, appGlobalValues map[stri
 	var credential map[string]string
 	switch config.CredentialType {
 	case "cosign":
-		_, err := vaultClient.GetCredential(context.Background(), &vaultcredpb.GetCredentialRequest{
+		ctx, cancel := callContext()
+		defer cancel()
+		_, err := vaultClient.GetCredential(ctx, &vaultcredpb.GetCredentialRequest{
 			CredentialType: genericCredentailType,
 			CredEntityName: config.CredentialEntity,
 			CredIdentifier: config.CredentialIdentifier,
@@ -285,7 +292,9 @@ func storeCredentials(captenConfig config.CaptenConfig, appGlobalValues map[stri
 		appGlobalValues[cosignKeysSecretNameVar] = config.SecretName
 
 	case "randomkey":
-		_, err := vaultClient.GetCredential(context.Background(), &vaultcredpb.GetCredentialRequest{
+		ctx, cancel := callContext()
+		defer cancel()
+		_, err := vaultClient.GetCredential(ctx, &vaultcredpb.GetCredentialRequest{
 			CredentialType: genericCredentailType,
 			CredEntityName: config.CredentialEntity,
 			CredIdentifier: config.CredentialIdentifier,
@@ -329,12 +338,8 @@ func storeCredentials(captenConfig config.CaptenConfig, appGlobalValues map[stri
 		if err != nil {
 			return fmt.Errorf("error while getting and storing password: %v", err)
 		}
-		secretKeyMapping := map[string][]string{
-			"username": {"username"},
-			"password": {"password"},
-		}
 
-		err = configureSecret(captenConfig, vaultClient, config, secretKeyMapping, nil, serviceCredentailType)
+		err = configureDBPasswordSecret(captenConfig, vaultClient, config)
 		if err != nil {
 			return fmt.Errorf("error while configuring secret: %v", err)
 		}
@@ -350,12 +355,7 @@ func storeCredentials(captenConfig config.CaptenConfig, appGlobalValues map[stri
 			return fmt.Errorf("error while getting and storing password: %v", err)
 		}
 
-		secretKeyMapping := map[string][]string{
-			"username": {"username"},
-			"password": {"password"},
-		}
-
-		err = configureSecret(captenConfig, vaultClient, config, secretKeyMapping, nil, serviceCredentailType)
+		err = configureDBPasswordSecret(captenConfig, vaultClient, config)
 		if err != nil {
 			return fmt.Errorf("error while configuring secret: %v", err)
 		}
@@ -369,16 +369,7 @@ func storeCredentials(captenConfig config.CaptenConfig, appGlobalValues map[stri
 		if err != nil {
 			return fmt.Errorf("error while getting and storing password: %v", err)
 		}
-		postgresconfig := types.CredentialAppConfig{
-			Name:       "postgres-cred",
-			SecretName: "postgres-admin-secret",
-			Namespaces: []string{"observability", "platform", "capten", "quality-trace"}, //	"platform", "capten", "quality-trace"
-
-			CredentialEntity:     "postgres",
-			CredentialIdentifier: "postgres-admin",
-			CredentialType:       "postgres-password",
-			UserName:             "postgres",
-		}
+		postgresconfig := postgresAdminCredConfig()
 
 		posgresdbuserkey := map[string]string{
 			"username": postgresconfig.UserName,
@@ -389,20 +380,7 @@ func storeCredentials(captenConfig config.CaptenConfig, appGlobalValues map[stri
 
 		}
 
-		postgressecretPath := fmt.Sprintf("%s/%s/%s", serviceCredentailType, postgresconfig.CredentialEntity, postgresconfig.CredentialIdentifier)
-
-		tempsecretPath := fmt.Sprintf("%s/%s/%s", serviceCredentailType, config.CredentialEntity, config.CredentialIdentifier)
-
-		secretKey := map[string][]string{
-			postgressecretPath: {"password"},
-			tempsecretPath:     {"password"},
-		}
-
-		secretPropertiesMapping := map[string][]string{
-			"password": {"admin-password", "password"},
-		}
-
-		err = configureSecret(captenConfig, vaultClient, postgresconfig, secretKey, secretPropertiesMapping, serviceCredentailType)
+		err = configurePostgresSecret(captenConfig, vaultClient, config)
 		if err != nil {
 			return err
 		}
@@ -416,7 +394,9 @@ func storeCredentials(captenConfig config.CaptenConfig, appGlobalValues map[stri
 }
 
 func putCredentialInVault(vaultClient vaultcredpb.VaultCredClient, config types.CredentialAppConfig, credential map[string]string, credentialType string) error {
-	_, err := vaultClient.PutCredential(context.Background(), &vaultcredpb.PutCredentialRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err := vaultClient.PutCredential(ctx, &vaultcredpb.PutCredentialRequest{
 		CredentialType: credentialType,
 		CredEntityName: config.CredentialEntity,
 		CredIdentifier: config.CredentialIdentifier,
@@ -435,6 +415,49 @@ func configureCosignKeysSecret(captenConfig config.CaptenConfig, vaultClient vau
 	return configureSecret(captenConfig, vaultClient, config, secretKeyMapping, nil, genericCredentailType)
 }
 
+func configureDBPasswordSecret(captenConfig config.CaptenConfig, vaultClient vaultcredpb.VaultCredClient, config types.CredentialAppConfig) error {
+	secretKeyMapping := map[string][]string{
+		"username": {"username"},
+		"password": {"password"},
+	}
+
+	return configureSecret(captenConfig, vaultClient, config, secretKeyMapping, nil, serviceCredentailType)
+}
+
+// postgresAdminCredConfig is the postgres admin credential, stored along with the temporal credential
+func postgresAdminCredConfig() types.CredentialAppConfig {
+	return types.CredentialAppConfig{
+		Name:       "postgres-cred",
+		SecretName: "postgres-admin-secret",
+		Namespaces: []string{"observability", "platform", "capten", "quality-trace"}, //	"platform", "capten", "quality-trace"
+
+		CredentialEntity:     "postgres",
+		CredentialIdentifier: "postgres-admin",
+		CredentialType:       "postgres-password",
+		UserName:             "postgres",
+	}
+}
+
+// configurePostgresSecret configures the postgres admin secret with the postgres admin
+// password and the password of the temporal credential
+func configurePostgresSecret(captenConfig config.CaptenConfig, vaultClient vaultcredpb.VaultCredClient, temporalConfig types.CredentialAppConfig) error {
+	postgresconfig := postgresAdminCredConfig()
+	postgressecretPath := fmt.Sprintf("%s/%s/%s", serviceCredentailType, postgresconfig.CredentialEntity, postgresconfig.CredentialIdentifier)
+
+	tempsecretPath := fmt.Sprintf("%s/%s/%s", serviceCredentailType, temporalConfig.CredentialEntity, temporalConfig.CredentialIdentifier)
+
+	secretKey := map[string][]string{
+		postgressecretPath: {"password"},
+		tempsecretPath:     {"password"},
+	}
+
+	secretPropertiesMapping := map[string][]string{
+		"password": {"admin-password", "password"},
+	}
+
+	return configureSecret(captenConfig, vaultClient, postgresconfig, secretKey, secretPropertiesMapping, serviceCredentailType)
+}
+
 func configureNatsSecret(captenConfig config.CaptenConfig, vaultClient vaultcredpb.VaultCredClient, config types.CredentialAppConfig) error {
 
 	secretPath := fmt.Sprintf("%s/%s/%s", genericCredentailType, config.CredentialEntity, config.CredentialIdentifier)
@@ -488,7 +511,9 @@ func randomTokenGeneration() (string, error) {
 }
 
 func generateAndStoreDBPassword(vaultClient vaultcredpb.VaultCredClient, config types.CredentialAppConfig, passwordKey string, credential map[string]string) error {
-	_, err := vaultClient.GetCredential(context.Background(), &vaultcredpb.GetCredentialRequest{
+	ctx, cancel := callContext()
+	defer cancel()
+	_, err := vaultClient.GetCredential(ctx, &vaultcredpb.GetCredentialRequest{
 		CredentialType: serviceCredentailType,
 		CredEntityName: config.CredentialEntity,
 		CredIdentifier: config.CredentialIdentifier,
@@ -585,7 +610,9 @@ func configureSecret(captenConfig config.CaptenConfig, vaultClient vaultcredpb.V
 			SecretPathData: secretPathData,
 			DomainName:     "capten.svc.cluster.local:8200",
 		}
-		_, err = vaultClient.ConfigureVaultSecret(context.Background(), request)
+		ctx, cancel := callContext()
+		_, err = vaultClient.ConfigureVaultSecret(ctx, request)
+		cancel()
 		if err != nil {
 			return fmt.Errorf("failed to configure vault secret: %v", err)
 		}
@@ -9,7 +9,6 @@ import (
 	"capten/pkg/helm"
 
 	"capten/pkg/types"
-	"context"
 
 	"os"
 	"path/filepath"
@@ -34,39 +33,72 @@ func SyncInstalledAppConfigsOnAgent(captenConfig config.CaptenConfig) error {
 	}
 
 	for _, appConfig := range appConfigs {
-
-		syncAppData, err := appConfig.ToSyncAppData()
-		if err != nil {
-			clog.Logger.Errorf("failed to parse '%s' app config, %v", appConfig.ReleaseName, err)
-			continue
+		if err := syncAppConfig(captenConfig, client, appConfig); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		if len(syncAppData.Config.Icon) != 0 {
-			iconBytes, err := os.ReadFile(captenConfig.PrepareFilePath(captenConfig.AppIconsDirPath, string(syncAppData.Config.Icon)))
-			if err != nil {
-				clog.Logger.Errorf("failed loading icon for app '%s', %v", appConfig.ReleaseName, err)
-			}
-			syncAppData.Config.Icon = iconBytes
-			clog.Logger.Debugf("'%s' app icon added", appConfig.ReleaseName)
-		}
+func SyncAppOnAgent(captenConfig config.CaptenConfig, appConfig types.AppConfig) error {
+	client, err := GetAgentClient(captenConfig)
+	if err != nil {
+		return err
+	}
 
-		templateValues := app.GetAppValuesTemplate(captenConfig, appConfig.ReleaseName)
-		syncAppData.Values.TemplateValues = templateValues
+	appConfig.TemplateValues = nil
+	appConfig.InstallStatus = "Installed"
+	return syncAppConfig(captenConfig, client, appConfig)
+}
 
-		syncAppData.Config.InstallStatus = appConfig.InstallStatus
+func SyncUninstalledAppsOnAgent(captenConfig config.CaptenConfig, appConfigs []types.AppConfig) error {
+	client, err := GetAgentClient(captenConfig)
+	if err != nil {
+		return err
+	}
 
-		res, err := client.SyncApp(context.TODO(), &agentpb.SyncAppRequest{Data: &syncAppData})
-		if err != nil {
+	for _, appConfig := range appConfigs {
+		appConfig.InstallStatus = "Uninstalled"
+		if err := syncAppConfig(captenConfig, client, appConfig); err != nil {
 			return err
-
 		}
+	}
+	return nil
+}
 
-		if res != nil && res.Status != agentpb.StatusCode_OK {
-			clog.Logger.Errorf("failed to synch '%s' app config to synch with agent, %v", appConfig.ReleaseName, res.GetStatusMessage())
-			continue
+func syncAppConfig(captenConfig config.CaptenConfig, client agentpb.AgentClient, appConfig types.AppConfig) error {
+	syncAppData, err := appConfig.ToSyncAppData()
+	if err != nil {
+		clog.Logger.Errorf("failed to parse '%s' app config, %v", appConfig.ReleaseName, err)
+		return nil
+	}
+
+	if len(syncAppData.Config.Icon) != 0 {
+		iconBytes, err := os.ReadFile(captenConfig.PrepareFilePath(captenConfig.AppIconsDirPath, string(syncAppData.Config.Icon)))
+		if err != nil {
+			clog.Logger.Errorf("failed loading icon for app '%s', %v", appConfig.ReleaseName, err)
 		}
-		clog.Logger.Debugf("'%s' app synchronized with agent", appConfig.ReleaseName)
+		syncAppData.Config.Icon = iconBytes
+		clog.Logger.Debugf("'%s' app icon added", appConfig.ReleaseName)
+	}
+
+	templateValues := app.GetAppValuesTemplate(captenConfig, appConfig.ReleaseName)
+	syncAppData.Values.TemplateValues = templateValues
+
+	syncAppData.Config.InstallStatus = appConfig.InstallStatus
+
+	ctx, cancel := callContext()
+	defer cancel()
+	res, err := client.SyncApp(ctx, &agentpb.SyncAppRequest{Data: &syncAppData})
+	if err != nil {
+		return err
+	}
+
+	if res != nil && res.Status != agentpb.StatusCode_OK {
+		clog.Logger.Errorf("failed to synch '%s' app config to synch with agent, %v", appConfig.ReleaseName, res.GetStatusMessage())
+		return nil
 	}
+	clog.Logger.Debugf("'%s' app synchronized with agent", appConfig.ReleaseName)
 	return nil
 }
 
@@ -0,0 +1,381 @@
+package agent
+
+import (
+	"capten/pkg/output"
+	"sort"
+	"strings"
+)
+
+type ClusterApp struct {
+	Name           string `json:"name" yaml:"name"`
+	Category       string `json:"category" yaml:"category"`
+	Version        string `json:"version" yaml:"version"`
+	InstallStatus  string `json:"installStatus" yaml:"installStatus"`
+	RuntimeStatus  string `json:"runtimeStatus,omitempty" yaml:"runtimeStatus,omitempty"`
+	Namespace      string `json:"namespace" yaml:"namespace"`
+	ChartName      string `json:"chartName" yaml:"chartName"`
+	UiEndpoint     string `json:"uiEndpoint,omitempty" yaml:"uiEndpoint,omitempty"`
+	LastUpdateTime string `json:"lastUpdateTime,omitempty" yaml:"lastUpdateTime,omitempty"`
+}
+
+type ClusterApps []ClusterApp
+
+func (apps ClusterApps) Table(wide bool) ([]string, [][]string) {
+	headers := []string{"Category", "Name", "Version", "Status"}
+	if wide {
+		headers = append(headers, "Namespace", "Chart", "UI Endpoint", "Last Updated")
+	}
+	rows := [][]string{}
+	for _, app := range apps {
+		row := []string{app.Category, app.Name, app.Version, app.InstallStatus}
+		if wide {
+			row = append(row, app.Namespace, app.ChartName, app.UiEndpoint, app.LastUpdateTime)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+func (apps ClusterApps) EmptyMessage() string {
+	return "No apps found on cluster"
+}
+
+type ClusterAppDetails struct {
+	Name             string `json:"name" yaml:"name"`
+	Version          string `json:"version" yaml:"version"`
+	HelmRepoURL      string `json:"helmRepoURL" yaml:"helmRepoURL"`
+	Category         string `json:"category" yaml:"category"`
+	Description      string `json:"description" yaml:"description"`
+	Namespace        string `json:"namespace" yaml:"namespace"`
+	UiModuleEndpoint string `json:"uiModuleEndpoint" yaml:"uiModuleEndpoint"`
+	UiEndpoint       string `json:"uiEndpoint" yaml:"uiEndpoint"`
+	ApiEndpoint      string `json:"apiEndpoint" yaml:"apiEndpoint"`
+	InstallStatus    string `json:"installStatus" yaml:"installStatus"`
+}
+
+func (app ClusterAppDetails) Table(wide bool) ([]string, [][]string) {
+	return output.AttributesTable(output.Attributes{
+		{"app-name", app.Name},
+		{"version", app.Version},
+		{"helm-repo-url", app.HelmRepoURL},
+		{"category", app.Category},
+		{"description", app.Description},
+		{"namespace", app.Namespace},
+		{"ui-module-endpoint", app.UiModuleEndpoint},
+		{"ui-endpoint", app.UiEndpoint},
+		{"api-endpoint", app.ApiEndpoint},
+		{"install-status", app.InstallStatus},
+	})
+}
+
+type ClusterPlugin struct {
+	Name          string `json:"name" yaml:"name"`
+	Category      string `json:"category" yaml:"category"`
+	Version       string `json:"version" yaml:"version"`
+	StoreType     string `json:"storeType" yaml:"storeType"`
+	InstallStatus string `json:"installStatus" yaml:"installStatus"`
+	Description   string `json:"description" yaml:"description"`
+}
+
+type ClusterPlugins []ClusterPlugin
+
+func (plugins ClusterPlugins) Table(wide bool) ([]string, [][]string) {
+	headers := []string{"Category", "Name", "Version", "Store Type", "Status"}
+	if wide {
+		headers = append(headers, "Description")
+	}
+	rows := [][]string{}
+	for _, plugin := range plugins {
+		row := []string{plugin.Category, plugin.Name, plugin.Version, plugin.StoreType, plugin.InstallStatus}
+		if wide {
+			row = append(row, plugin.Description)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+func (plugins ClusterPlugins) EmptyMessage() string {
+	return "No plugins found on cluster"
+}
+
+type StorePlugin struct {
+	Name        string   `json:"name" yaml:"name"`
+	Category    string   `json:"category" yaml:"category"`
+	Versions    []string `json:"versions" yaml:"versions"`
+	Description string   `json:"description" yaml:"description"`
+	StoreType   string   `json:"storeType" yaml:"storeType"`
+}
+
+type StorePlugins []StorePlugin
+
+func (plugins StorePlugins) Table(wide bool) ([]string, [][]string) {
+	headers := []string{"Category", "Name", "Version"}
+	if wide {
+		headers = append(headers, "Store Type", "Description")
+	}
+	rows := [][]string{}
+	for _, plugin := range plugins {
+		row := []string{plugin.Category, plugin.Name, strings.Join(plugin.Versions, ",")}
+		if wide {
+			row = append(row, plugin.StoreType, plugin.Description)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+func (plugins StorePlugins) EmptyMessage() string {
+	return "No plugins found on plugin store"
+}
+
+func (plugin StorePlugin) Table(wide bool) ([]string, [][]string) {
+	return output.AttributesTable(output.Attributes{
+		{"plugin-name", plugin.Name},
+		{"category", plugin.Category},
+		{"versions", strings.Join(plugin.Versions, ",")},
+		{"description", plugin.Description},
+		{"store-type", plugin.StoreType},
+	})
+}
+
+type GitProject struct {
+	ID             string   `json:"id" yaml:"id"`
+	ProjectURL     string   `json:"projectURL" yaml:"projectURL"`
+	Labels         []string `json:"labels" yaml:"labels"`
+	LastUpdateTime string   `json:"lastUpdateTime,omitempty" yaml:"lastUpdateTime,omitempty"`
+}
+
+type GitProjects []GitProject
+
+func (projects GitProjects) Table(wide bool) ([]string, [][]string) {
+	headers := []string{"ID", "Project URL", "Labels"}
+	if wide {
+		headers = append(headers, "Last Updated")
+	}
+	rows := [][]string{}
+	for _, project := range projects {
+		row := []string{project.ID, project.ProjectURL, strings.Join(project.Labels, ",")}
+		if wide {
+			row = append(row, project.LastUpdateTime)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+func (projects GitProjects) EmptyMessage() string {
+	return "No git projects added to cluster"
+}
+
+type CloudProvider struct {
+	ID             string   `json:"id" yaml:"id"`
+	CloudType      string   `json:"cloudType" yaml:"cloudType"`
+	Labels         []string `json:"labels" yaml:"labels"`
+	LastUpdateTime string   `json:"lastUpdateTime,omitempty" yaml:"lastUpdateTime,omitempty"`
+}
+
+type CloudProviders []CloudProvider
+
+func (providers CloudProviders) Table(wide bool) ([]string, [][]string) {
+	headers := []string{"ID", "Cloud Type", "Labels"}
+	if wide {
+		headers = append(headers, "Last Updated")
+	}
+	rows := [][]string{}
+	for _, provider := range providers {
+		row := []string{provider.ID, provider.CloudType, strings.Join(provider.Labels, ",")}
+		if wide {
+			row = append(row, provider.LastUpdateTime)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+func (providers CloudProviders) EmptyMessage() string {
+	return "No cloud providers added to cluster"
+}
+
+type ContainerRegistry struct {
+	ID             string   `json:"id" yaml:"id"`
+	RegistryType   string   `json:"registryType" yaml:"registryType"`
+	RegistryURL    string   `json:"registryURL" yaml:"registryURL"`
+	Labels         []string `json:"labels" yaml:"labels"`
+	LastUpdateTime string   `json:"lastUpdateTime,omitempty" yaml:"lastUpdateTime,omitempty"`
+}
+
+type ContainerRegistries []ContainerRegistry
+
+func (registries ContainerRegistries) Table(wide bool) ([]string, [][]string) {
+	headers := []string{"ID", "Registry Type", "Registry URL", "Labels"}
+	if wide {
+		headers = append(headers, "Last Updated")
+	}
+	rows := [][]string{}
+	for _, registry := range registries {
+		row := []string{registry.ID, registry.RegistryType, registry.RegistryURL, strings.Join(registry.Labels, ",")}
+		if wide {
+			row = append(row, registry.LastUpdateTime)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+func (registries ContainerRegistries) EmptyMessage() string {
+	return "No container registries added to cluster"
+}
+
+type PluginAction struct {
+	Action     string   `json:"action" yaml:"action"`
+	Attributes []string `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+}
+
+type PluginActions []PluginAction
+
+func (actions PluginActions) Table(wide bool) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, action := range actions {
+		rows = append(rows, []string{action.Action, strings.Join(action.Attributes, ", ")})
+	}
+	return []string{"Action", "Attributes"}, rows
+}
+
+type PluginProject struct {
+	GitProjectURL  string `json:"gitProjectURL" yaml:"gitProjectURL"`
+	Status         string `json:"status" yaml:"status"`
+	LastUpdateTime string `json:"lastUpdateTime,omitempty" yaml:"lastUpdateTime,omitempty"`
+}
+
+func (project PluginProject) Table(wide bool) ([]string, [][]string) {
+	attributes := output.Attributes{
+		{"git-project-url", project.GitProjectURL},
+		{"status", project.Status},
+	}
+	if wide {
+		attributes = append(attributes, [2]string{"last-update-time", project.LastUpdateTime})
+	}
+	return output.AttributesTable(attributes)
+}
+
+type CrossplaneProvider struct {
+	ID              string `json:"id" yaml:"id"`
+	CloudType       string `json:"cloudType" yaml:"cloudType"`
+	CloudProviderID string `json:"cloudProviderID" yaml:"cloudProviderID"`
+	ProviderName    string `json:"providerName" yaml:"providerName"`
+	Status          string `json:"status" yaml:"status"`
+}
+
+type CrossplaneProviders []CrossplaneProvider
+
+func (providers CrossplaneProviders) Table(wide bool) ([]string, [][]string) {
+	headers := []string{"ID", "Cloud Type", "Cloud Provider ID", "Status"}
+	if wide {
+		headers = append(headers, "Provider Name")
+	}
+	rows := [][]string{}
+	for _, provider := range providers {
+		row := []string{provider.ID, provider.CloudType, provider.CloudProviderID, provider.Status}
+		if wide {
+			row = append(row, provider.ProviderName)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+func (providers CrossplaneProviders) EmptyMessage() string {
+	return "No crossplane providers added to cluster"
+}
+
+type ManagedCluster struct {
+	ID                  string `json:"id" yaml:"id"`
+	ClusterName         string `json:"clusterName" yaml:"clusterName"`
+	ClusterEndpoint     string `json:"clusterEndpoint" yaml:"clusterEndpoint"`
+	ClusterDeployStatus string `json:"clusterDeployStatus" yaml:"clusterDeployStatus"`
+	AppDeployStatus     string `json:"appDeployStatus" yaml:"appDeployStatus"`
+	LastUpdateTime      string `json:"lastUpdateTime,omitempty" yaml:"lastUpdateTime,omitempty"`
+}
+
+type ManagedClusters []ManagedCluster
+
+func (clusters ManagedClusters) Table(wide bool) ([]string, [][]string) {
+	headers := []string{"ID", "Cluster Name", "Cluster Endpoint", "Cluster Deploy Status"}
+	if wide {
+		headers = append(headers, "App Deploy Status", "Last Updated")
+	}
+	rows := [][]string{}
+	for _, cluster := range clusters {
+		row := []string{cluster.ID, cluster.ClusterName, cluster.ClusterEndpoint, cluster.ClusterDeployStatus}
+		if wide {
+			row = append(row, cluster.AppDeployStatus, cluster.LastUpdateTime)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+func (clusters ManagedClusters) EmptyMessage() string {
+	return "No managed clusters added to cluster"
+}
+
+type Credential struct {
+	CredentialRef `yaml:",inline"`
+	Values        map[string]string `json:"values" yaml:"values"`
+}
+
+func (credential Credential) Keys() []string {
+	keys := []string{}
+	for key := range credential.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Masked returns a copy of the credential with the values replaced, the keys are kept
+func (credential Credential) Masked() Credential {
+	masked := Credential{CredentialRef: credential.CredentialRef, Values: map[string]string{}}
+	for key := range credential.Values {
+		masked.Values[key] = credentialMask
+	}
+	return masked
+}
+
+func (credential Credential) Table(wide bool) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, key := range credential.Keys() {
+		rows = append(rows, []string{key, credential.Values[key]})
+	}
+	return []string{"Key", "Value"}, rows
+}
+
+type CredentialStatus struct {
+	CredentialRef `yaml:",inline"`
+	Status        string   `json:"status" yaml:"status"`
+	Keys          []string `json:"keys,omitempty" yaml:"keys,omitempty"`
+	Error         string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type CredentialStatuses []CredentialStatus
+
+func (statuses CredentialStatuses) Table(wide bool) ([]string, [][]string) {
+	headers := []string{"Type", "Entity", "Identifier", "Status"}
+	if wide {
+		headers = append(headers, "Keys", "Error")
+	}
+	rows := [][]string{}
+	for _, status := range statuses {
+		row := []string{status.Type, status.Entity, status.Identifier, status.Status}
+		if wide {
+			row = append(row, strings.Join(status.Keys, ", "), status.Error)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+func (statuses CredentialStatuses) EmptyMessage() string {
+	return "No credentials found"
+}
@@ -98,3 +98,24 @@ func PrepareGlobalVaules(captenConfig config.CaptenConfig) (map[string]interface
 	}
 	return globalValues, err
 }
+
+func RemoveAppConfig(captenConfig config.CaptenConfig, appName string) error {
+	err := os.Remove(captenConfig.PrepareFilePath(captenConfig.AppsTempDirPath, appName+".yaml"))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.WithMessagef(err, "failed to remove %s app config", appName)
+	}
+	return nil
+}
+
+func loadInstalledAppConfig(captenConfig config.CaptenConfig, appName string) (types.AppConfig, error) {
+	installedConfigFilePath := captenConfig.PrepareFilePath(captenConfig.AppsTempDirPath, appName+".yaml")
+	if _, err := os.Stat(installedConfigFilePath); err == nil {
+		return GetAppConfig(installedConfigFilePath, nil)
+	}
+
+	appConfig, err := GetAppConfig(captenConfig.PrepareFilePath(captenConfig.AppsConfigDirPath, appName+".yaml"), nil)
+	if err != nil {
+		return appConfig, errors.WithMessagef(err, "failed load %s config", appName)
+	}
+	return appConfig, nil
+}
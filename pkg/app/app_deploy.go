@@ -8,14 +8,16 @@ import (
 	"capten/pkg/types"
 	"context"
 	"html/template"
+	"strings"
 
 	"capten/pkg/clog"
 
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/strvals"
 )
 
-func DeployApps(captenConfig config.CaptenConfig, globalValues map[string]interface{}, groupFile string) error {
+func DeployApps(ctx context.Context, captenConfig config.CaptenConfig, globalValues map[string]interface{}, groupFile string) error {
 	appGroupAppConfigs, err := prepareAppGroupConfigs(captenConfig, globalValues, groupFile)
 	if err != nil {
 		return err
@@ -27,46 +29,172 @@ func DeployApps(captenConfig config.CaptenConfig, globalValues map[string]interf
 		return err
 	}
 
-	status := installAppGroup(captenConfig, hc, appGroupAppConfigs)
+	status := installAppGroup(ctx, captenConfig, hc, appGroupAppConfigs)
 	if !status {
 		return errors.New("applications deployment failed")
 	}
 	return nil
 }
 
-func installAppGroup(captenConfig config.CaptenConfig, hc *helm.Client, appConfigs []types.AppConfig) bool {
-	successStatus := true
+func installAppGroup(ctx context.Context, captenConfig config.CaptenConfig, hc *helm.Client, appConfigs []types.AppConfig) bool {
+	return installAppsConcurrently(appConfigs, captenConfig.AppDeployWorkers, func(appConfig types.AppConfig) bool {
+		return installApp(ctx, captenConfig, hc, appConfig)
+	})
+}
+
+func installApp(ctx context.Context, captenConfig config.CaptenConfig, hc *helm.Client, appConfig types.AppConfig) bool {
+	if err := ctx.Err(); err != nil {
+		clog.Logger.Errorf("[app: %s] installation not started, %v", appConfig.Name, err)
+		return false
+	}
+
+	if appConfig.PrivilegedNamespace {
+		err := k8s.CreateorUpdateNamespaceWithLabel(captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.KubeConfigFileName),
+			appConfig.Namespace)
+		if err != nil {
+			clog.Logger.Error("failed to patch namespace with privilege", err)
+			return false
+		}
+	}
+	alreadyInstalled, err := hc.Install(ctx, &appConfig)
+	if err != nil {
+		clog.Logger.Errorf("[app: %s] installation failed, %v", appConfig.Name, err)
+		return false
+	}
+	if alreadyInstalled {
+		clog.Logger.Infof("[app: %s] already installed", appConfig.Name)
+	} else {
+		clog.Logger.Infof("[app: %s] installed", appConfig.Name)
+	}
+
+	appConfig.TemplateValues = nil
+	if err := WriteAppConfig(captenConfig, appConfig); err != nil {
+		clog.Logger.Errorf("failed to write %s config, %v", appConfig.Name, err)
+		return false
+	}
+	return true
+}
+
+type appInstallResult struct {
+	name    string
+	success bool
+}
+
+func installAppsConcurrently(appConfigs []types.AppConfig, workers int, install func(types.AppConfig) bool) bool {
+	if workers < 1 {
+		workers = 1
+	}
+
+	groupApps := map[string]bool{}
 	for _, appConfig := range appConfigs {
-		if appConfig.PrivilegedNamespace {
-			err := k8s.CreateorUpdateNamespaceWithLabel(captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.KubeConfigFileName),
-				appConfig.Namespace)
-			if err != nil {
-				clog.Logger.Error("failed to patch namespace with privilege", err)
-				successStatus = false
+		groupApps[appConfig.Name] = true
+	}
+
+	results := make(chan appInstallResult)
+	status := map[string]bool{}
+	started := map[string]bool{}
+	running := 0
+	for len(status) < len(appConfigs) {
+		progressed := false
+		for _, appConfig := range appConfigs {
+			if running >= workers {
+				break
+			}
+			if started[appConfig.Name] {
+				continue
+			}
+
+			ready, failedDependency := appDependencyStatus(appConfig, groupApps, status)
+			if len(failedDependency) != 0 {
+				clog.Logger.Errorf("[app: %s] skipped, dependency %s failed", appConfig.Name, failedDependency)
+				started[appConfig.Name] = true
+				status[appConfig.Name] = false
+				progressed = true
+				continue
+			}
+			if !ready {
 				continue
 			}
+
+			started[appConfig.Name] = true
+			running++
+			progressed = true
+			go func(appConfig types.AppConfig) {
+				results <- appInstallResult{name: appConfig.Name, success: install(appConfig)}
+			}(appConfig)
 		}
-		alreadyInstalled, err := hc.Install(context.Background(), &appConfig)
-		if err != nil {
-			clog.Logger.Errorf("[app: %s] installation failed, %v", appConfig.Name, err)
-			successStatus = false
+
+		if running == 0 {
+			if !progressed {
+				pending := []string{}
+				for _, appConfig := range appConfigs {
+					if !started[appConfig.Name] {
+						pending = append(pending, appConfig.Name)
+					}
+				}
+				clog.Logger.Errorf("apps have cyclic dependencies: %s", strings.Join(pending, ", "))
+				return false
+			}
 			continue
 		}
-		if alreadyInstalled {
-			clog.Logger.Infof("[app: %s] already installed", appConfig.Name)
-		} else {
-			clog.Logger.Infof("[app: %s] installed", appConfig.Name)
+
+		result := <-results
+		running--
+		status[result.name] = result.success
+	}
+
+	for _, success := range status {
+		if !success {
+			return false
 		}
+	}
+	return true
+}
 
-		appConfig.TemplateValues = nil
-		if err := WriteAppConfig(captenConfig, appConfig); err != nil {
-			clog.Logger.Errorf("failed to write %s config, %v", appConfig.Name, err)
-			successStatus = false
+func appDependencyStatus(appConfig types.AppConfig, groupApps map[string]bool, status map[string]bool) (ready bool, failedDependency string) {
+	for _, dependency := range appConfig.DependsOn {
+		if !groupApps[dependency] {
 			continue
 		}
+		success, done := status[dependency]
+		if !done {
+			return false, ""
+		}
+		if !success {
+			return false, dependency
+		}
+	}
+	return true, ""
+}
 
+func DeployApp(captenConfig config.CaptenConfig, globalValues map[string]interface{}, appName, version string,
+	setValues []string) (types.AppConfig, error) {
+	appConfig, err := prepareAppConfig(captenConfig, globalValues, appName)
+	if err != nil {
+		return appConfig, err
+	}
+
+	if len(version) != 0 {
+		appConfig.Version = version
+	}
+
+	for _, setValue := range setValues {
+		if _, err := strvals.Parse(setValue); err != nil {
+			return appConfig, errors.WithMessagef(err, "failed to parse set value '%s'", setValue)
+		}
+	}
+	appConfig.SetValues = setValues
+
+	hc, err := helm.NewClient(captenConfig)
+	if err != nil {
+		return appConfig, err
 	}
-	return successStatus
+
+	captenConfig.UpgradeAppIfInstalled = true
+	if !installApp(context.Background(), captenConfig, hc, appConfig) {
+		return appConfig, errors.Errorf("application %s deployment failed", appName)
+	}
+	return appConfig, nil
 }
 
 func prepareAppGroupConfigs(captenConfig config.CaptenConfig, globalValues map[string]interface{},
@@ -80,32 +208,41 @@ func prepareAppGroupConfigs(captenConfig config.CaptenConfig, globalValues map[s
 	appConfigs = []types.AppConfig{}
 	for _, appName := range apps {
 		var appConfig types.AppConfig
-		appConfig, err = GetAppConfig(captenConfig.PrepareFilePath(captenConfig.AppsConfigDirPath, appName+".yaml"), globalValues)
+		appConfig, err = prepareAppConfig(captenConfig, globalValues, appName)
 		if err != nil {
-			err = errors.WithMessagef(err, "failed load %s config", appName)
 			return
 		}
+		appConfigs = append(appConfigs, appConfig)
+	}
+	return
+}
 
-		appConfig.TemplateValues = GetAppValuesTemplate(captenConfig, appName)
-		appConfig.OverrideValues, err = replaceOverrideTemplateValues(appConfig.OverrideValues, globalValues)
-		if err != nil {
-			err = errors.WithMessagef(err, "failed transform '%s' override values", appName)
-			return
-		}
+func prepareAppConfig(captenConfig config.CaptenConfig, globalValues map[string]interface{},
+	appName string) (appConfig types.AppConfig, err error) {
+	appConfig, err = GetAppConfig(captenConfig.PrepareFilePath(captenConfig.AppsConfigDirPath, appName+".yaml"), globalValues)
+	if err != nil {
+		err = errors.WithMessagef(err, "failed load %s config", appName)
+		return
+	}
 
-		appConfig.UIEndpoint, err = replaceTemplateStringValues(appConfig.UIEndpoint, globalValues)
-		if err != nil {
-			err = errors.WithMessagef(err, "failed transform '%s' string value", appName)
-			return
-		}
-		appConfig.APIEndpoint, err = replaceTemplateStringValues(appConfig.APIEndpoint, globalValues)
-		if err != nil {
-			err = errors.WithMessagef(err, "failed transform '%s' string value", appName)
-			return
-		}
-		appConfigs = append(appConfigs, appConfig)
-		clog.Logger.Debug(appName, " : ", appConfig)
+	appConfig.TemplateValues = GetAppValuesTemplate(captenConfig, appName)
+	appConfig.OverrideValues, err = replaceOverrideTemplateValues(appConfig.OverrideValues, globalValues)
+	if err != nil {
+		err = errors.WithMessagef(err, "failed transform '%s' override values", appName)
+		return
+	}
+
+	appConfig.UIEndpoint, err = replaceTemplateStringValues(appConfig.UIEndpoint, globalValues)
+	if err != nil {
+		err = errors.WithMessagef(err, "failed transform '%s' string value", appName)
+		return
+	}
+	appConfig.APIEndpoint, err = replaceTemplateStringValues(appConfig.APIEndpoint, globalValues)
+	if err != nil {
+		err = errors.WithMessagef(err, "failed transform '%s' string value", appName)
+		return
 	}
+	clog.Logger.Debug(appName, " : ", appConfig)
 	return
 }
 
@@ -4,10 +4,11 @@ import (
 	"capten/pkg/config"
 	"capten/pkg/helm"
 	"capten/pkg/types"
-
-	//"context"
+	"context"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 	//"github.com/pkg/errors"
 )
 
@@ -52,7 +53,7 @@ func TestDeployApps(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := DeployApps(tt.args.captenConfig, tt.args.globalValues, tt.args.groupFile); (err != nil) != tt.wantErr {
+			if err := DeployApps(context.Background(), tt.args.captenConfig, tt.args.globalValues, tt.args.groupFile); (err != nil) != tt.wantErr {
 				t.Errorf("DeployApps() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -190,13 +191,109 @@ func Test_installAppGroup(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := installAppGroup(tt.args.captenConfig, tt.args.hc, tt.args.appConfigs); got != tt.want {
+			if got := installAppGroup(context.Background(), tt.args.captenConfig, tt.args.hc, tt.args.appConfigs); got != tt.want {
 				t.Errorf("installAppGroup() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func Test_installAppsConcurrently(t *testing.T) {
+	tests := []struct {
+		name       string
+		appConfigs []types.AppConfig
+		workers    int
+		failApps   []string
+		wantRun    []string
+		want       bool
+	}{
+		{
+			name: "Dependencies installed before dependents",
+			appConfigs: []types.AppConfig{
+				{Name: "temporal", DependsOn: []string{"postgresql"}},
+				{Name: "postgresql"},
+				{Name: "prometheus"},
+				{Name: "kad", DependsOn: []string{"temporal", "vault"}},
+			},
+			workers: 4,
+			wantRun: []string{"postgresql", "prometheus", "temporal", "kad"},
+			want:    true,
+		},
+		{
+			name: "Dependents of failed app are skipped",
+			appConfigs: []types.AppConfig{
+				{Name: "postgresql"},
+				{Name: "temporal", DependsOn: []string{"postgresql"}},
+				{Name: "kad", DependsOn: []string{"temporal"}},
+				{Name: "prometheus"},
+			},
+			workers:  1,
+			failApps: []string{"postgresql"},
+			wantRun:  []string{"postgresql", "prometheus"},
+			want:     false,
+		},
+		{
+			name: "Cyclic dependencies",
+			appConfigs: []types.AppConfig{
+				{Name: "temporal", DependsOn: []string{"postgresql"}},
+				{Name: "postgresql", DependsOn: []string{"temporal"}},
+			},
+			workers: 2,
+			wantRun: []string{},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mutex sync.Mutex
+			gotRun := []string{}
+			running, maxRunning := 0, 0
+			got := installAppsConcurrently(tt.appConfigs, tt.workers, func(appConfig types.AppConfig) bool {
+				mutex.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mutex.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				mutex.Lock()
+				defer mutex.Unlock()
+				running--
+				gotRun = append(gotRun, appConfig.Name)
+				for _, name := range tt.failApps {
+					if name == appConfig.Name {
+						return false
+					}
+				}
+				return true
+			})
+			if got != tt.want {
+				t.Errorf("installAppsConcurrently() = %v, want %v", got, tt.want)
+			}
+			if maxRunning > tt.workers {
+				t.Errorf("installAppsConcurrently() ran %d apps concurrently, workers %d", maxRunning, tt.workers)
+			}
+			if len(gotRun) != len(tt.wantRun) {
+				t.Fatalf("installAppsConcurrently() installed %v, want %v", gotRun, tt.wantRun)
+			}
+			position := map[string]int{}
+			for index, name := range gotRun {
+				position[name] = index
+			}
+			for _, appConfig := range tt.appConfigs {
+				for _, dependency := range appConfig.DependsOn {
+					depPosition, ok := position[dependency]
+					if ok && depPosition > position[appConfig.Name] {
+						t.Errorf("installAppsConcurrently() installed %s before dependency %s", appConfig.Name, dependency)
+					}
+				}
+			}
+		})
+	}
+}
+
 func Test_prepareAppGroupConfigs(t *testing.T) {
 	type args struct {
 		captenConfig     config.CaptenConfig
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"capten/pkg/clog"
+	"capten/pkg/config"
+	"capten/pkg/helm"
+	"capten/pkg/types"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+func DiffApp(captenConfig config.CaptenConfig, globalValues map[string]interface{}, appName string) (types.AppDiff, error) {
+	appConfig, err := prepareAppConfig(captenConfig, globalValues, appName)
+	if err != nil {
+		return types.AppDiff{Name: appName}, err
+	}
+
+	hc, err := helm.NewClient(captenConfig)
+	if err != nil {
+		return types.AppDiff{Name: appName}, err
+	}
+	return hc.Diff(context.Background(), &appConfig)
+}
+
+func DiffAppGroup(captenConfig config.CaptenConfig, globalValues map[string]interface{}, groupFile string) ([]types.AppDiff, error) {
+	appConfigs, err := prepareAppGroupConfigs(captenConfig, globalValues, groupFile)
+	if err != nil {
+		return nil, err
+	}
+
+	hc, err := helm.NewClient(captenConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	appDiffs := []types.AppDiff{}
+	failed := false
+	for _, appConfig := range appConfigs {
+		appDiff, err := hc.Diff(context.Background(), &appConfig)
+		if err != nil {
+			clog.Logger.Errorf("[app: %s] diff failed, %v", appConfig.Name, err)
+			failed = true
+			continue
+		}
+		appDiffs = append(appDiffs, appDiff)
+	}
+
+	if failed {
+		return appDiffs, errors.New("applications diff failed")
+	}
+	return appDiffs, nil
+}
+
+func FormatAppDiff(appDiff types.AppDiff) string {
+	var sb strings.Builder
+	status := "not installed"
+	if appDiff.Installed {
+		status = "installed"
+	}
+	fmt.Fprintf(&sb, "=== app: %s (%s) ===\n", appDiff.Name, status)
+
+	if len(appDiff.ValuesDiff) == 0 && len(appDiff.ManifestDiff) == 0 {
+		sb.WriteString("no changes\n")
+		return sb.String()
+	}
+	sb.WriteString(appDiff.ValuesDiff)
+	sb.WriteString(appDiff.ManifestDiff)
+	return sb.String()
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"capten/pkg/clog"
+	"capten/pkg/config"
+	"capten/pkg/helm"
+	"capten/pkg/types"
+	"context"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const ReleaseNotInstalled = "not-installed"
+
+func GetAppHistory(captenConfig config.CaptenConfig, appName string) ([]types.AppReleaseRevision, error) {
+	appConfig, err := loadInstalledAppConfig(captenConfig, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	hc, err := helm.NewClient(captenConfig)
+	if err != nil {
+		return nil, err
+	}
+	return hc.History(context.Background(), appConfig.Namespace, appConfig.ReleaseName)
+}
+
+// GetAppsReleaseStatus returns the helm release status of the apps of the group file,
+// an app which can not be checked is reported with the unknown status and the error
+func GetAppsReleaseStatus(captenConfig config.CaptenConfig, groupFile string) ([]types.AppReleaseStatus, error) {
+	apps, err := GetApps(captenConfig.PrepareFilePath(captenConfig.AppsDirPath, groupFile), captenConfig.ClusterType)
+	if err != nil {
+		return nil, err
+	}
+
+	hc, err := helm.NewClient(captenConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []types.AppReleaseStatus{}
+	for _, appName := range apps {
+		appStatus := types.AppReleaseStatus{Name: appName, Status: release.StatusUnknown.String()}
+		appConfig, err := loadInstalledAppConfig(captenConfig, appName)
+		if err != nil {
+			appStatus.Error = err.Error()
+			statuses = append(statuses, appStatus)
+			continue
+		}
+
+		appStatus.Namespace = appConfig.Namespace
+		revision, notInstalled, err := hc.LatestRevision(context.Background(), appConfig.Namespace, appConfig.ReleaseName)
+		switch {
+		case err != nil:
+			appStatus.Error = err.Error()
+		case notInstalled:
+			appStatus.Status = ReleaseNotInstalled
+		default:
+			appStatus.Status, appStatus.ChartVersion = revision.Status, revision.ChartVersion
+		}
+		statuses = append(statuses, appStatus)
+	}
+	return statuses, nil
+}
+
+func RollbackApp(captenConfig config.CaptenConfig, appName string, revision int) (types.AppConfig, error) {
+	appConfig, err := loadInstalledAppConfig(captenConfig, appName)
+	if err != nil {
+		return appConfig, err
+	}
+
+	hc, err := helm.NewClient(captenConfig)
+	if err != nil {
+		return appConfig, err
+	}
+
+	if err := hc.Rollback(context.Background(), appConfig.Namespace, appConfig.ReleaseName, revision); err != nil {
+		return appConfig, err
+	}
+	clog.Logger.Infof("[app: %s] rolled back", appConfig.Name)
+
+	if captenConfig.AppDeployDryRun {
+		return appConfig, nil
+	}
+
+	revisions, err := hc.History(context.Background(), appConfig.Namespace, appConfig.ReleaseName)
+	if err != nil {
+		return appConfig, err
+	}
+	if len(revisions) != 0 && len(revisions[len(revisions)-1].ChartVersion) != 0 {
+		appConfig.Version = revisions[len(revisions)-1].ChartVersion
+	}
+
+	if err := WriteAppConfig(captenConfig, appConfig); err != nil {
+		return appConfig, errors.WithMessagef(err, "failed to write %s config", appConfig.Name)
+	}
+	return appConfig, nil
+}
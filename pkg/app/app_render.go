@@ -0,0 +1,63 @@
+package app
+
+import (
+	"capten/pkg/clog"
+	"capten/pkg/config"
+	"capten/pkg/helm"
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+func RenderAppGroup(captenConfig config.CaptenConfig, globalValues map[string]interface{}, groupFile, outputDir string) error {
+	appConfigs, err := prepareAppGroupConfigs(captenConfig, globalValues, groupFile)
+	if err != nil {
+		return err
+	}
+
+	hc, err := helm.NewClient(captenConfig)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, appConfig := range appConfigs {
+		values, manifest, err := hc.Template(context.Background(), &appConfig)
+		if err != nil {
+			clog.Logger.Errorf("[app: %s] rendering failed, %v", appConfig.Name, err)
+			failed = true
+			continue
+		}
+
+		if err := writeAppRendering(filepath.Join(outputDir, appConfig.Name), values, manifest); err != nil {
+			clog.Logger.Errorf("[app: %s] %v", appConfig.Name, err)
+			failed = true
+			continue
+		}
+		clog.Logger.Infof("[app: %s] rendered", appConfig.Name)
+	}
+
+	if failed {
+		return errors.New("applications rendering failed")
+	}
+	return nil
+}
+
+func writeAppRendering(appDir, values, manifest string) error {
+	if err := os.MkdirAll(appDir, folderPrmission); err != nil {
+		return errors.WithMessagef(err, "failed to create directory %s", appDir)
+	}
+
+	valuesFilePath := filepath.Join(appDir, "values.yaml")
+	if err := os.WriteFile(valuesFilePath, []byte(values), filePrmission); err != nil {
+		return errors.WithMessagef(err, "failed to write %s", valuesFilePath)
+	}
+
+	manifestFilePath := filepath.Join(appDir, "manifest.yaml")
+	if err := os.WriteFile(manifestFilePath, []byte(manifest), filePrmission); err != nil {
+		return errors.WithMessagef(err, "failed to write %s", manifestFilePath)
+	}
+	return nil
+}
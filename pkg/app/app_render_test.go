@@ -0,0 +1,27 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_writeAppRendering(t *testing.T) {
+	appDir := filepath.Join(t.TempDir(), "kad")
+	if err := writeAppRendering(appDir, "DomainName: example.com\n", "kind: Deployment\n"); err != nil {
+		t.Fatalf("writeAppRendering() error = %v", err)
+	}
+
+	for fileName, want := range map[string]string{
+		"values.yaml":   "DomainName: example.com\n",
+		"manifest.yaml": "kind: Deployment\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(appDir, fileName))
+		if err != nil {
+			t.Fatalf("failed to read %s, %v", fileName, err)
+		}
+		if string(got) != want {
+			t.Errorf("writeAppRendering() %s = %q, want %q", fileName, got, want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"capten/pkg/clog"
+	"capten/pkg/config"
+	"capten/pkg/helm"
+	"capten/pkg/types"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+func UninstallApp(captenConfig config.CaptenConfig, appName string) (types.AppConfig, error) {
+	appConfig, err := loadInstalledAppConfig(captenConfig, appName)
+	if err != nil {
+		return appConfig, err
+	}
+
+	hc, err := helm.NewClient(captenConfig)
+	if err != nil {
+		return appConfig, err
+	}
+
+	if err := uninstallApp(captenConfig, hc, appConfig); err != nil {
+		return appConfig, err
+	}
+	return appConfig, nil
+}
+
+func UninstallApps(captenConfig config.CaptenConfig, groupFile string) ([]types.AppConfig, error) {
+	apps, err := GetApps(captenConfig.PrepareFilePath(captenConfig.AppsDirPath, groupFile), captenConfig.ClusterType)
+	if err != nil {
+		return nil, err
+	}
+
+	appConfigs := []types.AppConfig{}
+	for _, appName := range apps {
+		appConfig, err := loadInstalledAppConfig(captenConfig, appName)
+		if err != nil {
+			return nil, err
+		}
+		appConfigs = append(appConfigs, appConfig)
+	}
+
+	hc, err := helm.NewClient(captenConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return uninstallAppsInOrder(appConfigs, func(appConfig types.AppConfig) error {
+		return uninstallApp(captenConfig, hc, appConfig)
+	})
+}
+
+func uninstallApp(captenConfig config.CaptenConfig, hc *helm.Client, appConfig types.AppConfig) error {
+	notInstalled, err := hc.Uninstall(context.Background(), &appConfig)
+	if err != nil {
+		return errors.WithMessagef(err, "[app: %s] uninstallation failed", appConfig.Name)
+	}
+	if notInstalled {
+		clog.Logger.Infof("[app: %s] not installed", appConfig.Name)
+	} else {
+		clog.Logger.Infof("[app: %s] uninstalled", appConfig.Name)
+	}
+
+	if captenConfig.AppDeployDryRun {
+		return nil
+	}
+	return RemoveAppConfig(captenConfig, appConfig.Name)
+}
+
+func uninstallAppsInOrder(appConfigs []types.AppConfig, uninstall func(types.AppConfig) error) ([]types.AppConfig, error) {
+	ordered, err := orderAppsByDependency(appConfigs)
+	if err != nil {
+		return nil, err
+	}
+
+	uninstalled := []types.AppConfig{}
+	failed := map[string]bool{}
+	for index := len(ordered) - 1; index >= 0; index-- {
+		appConfig := ordered[index]
+		if dependent := failedDependent(appConfig.Name, ordered, failed); len(dependent) != 0 {
+			clog.Logger.Errorf("[app: %s] skipped, dependent app %s is not uninstalled", appConfig.Name, dependent)
+			failed[appConfig.Name] = true
+			continue
+		}
+
+		if err := uninstall(appConfig); err != nil {
+			clog.Logger.Error(err)
+			failed[appConfig.Name] = true
+			continue
+		}
+		uninstalled = append(uninstalled, appConfig)
+	}
+
+	if len(failed) != 0 {
+		return uninstalled, errors.New("applications uninstallation failed")
+	}
+	return uninstalled, nil
+}
+
+func orderAppsByDependency(appConfigs []types.AppConfig) ([]types.AppConfig, error) {
+	groupApps := map[string]bool{}
+	for _, appConfig := range appConfigs {
+		groupApps[appConfig.Name] = true
+	}
+
+	ordered := []types.AppConfig{}
+	placed := map[string]bool{}
+	for len(ordered) < len(appConfigs) {
+		progressed := false
+		for _, appConfig := range appConfigs {
+			if placed[appConfig.Name] {
+				continue
+			}
+			if ready, _ := appDependencyStatus(appConfig, groupApps, placed); !ready {
+				continue
+			}
+			ordered = append(ordered, appConfig)
+			placed[appConfig.Name] = true
+			progressed = true
+		}
+
+		if !progressed {
+			pending := []string{}
+			for _, appConfig := range appConfigs {
+				if !placed[appConfig.Name] {
+					pending = append(pending, appConfig.Name)
+				}
+			}
+			return nil, fmt.Errorf("apps have cyclic dependencies: %s", strings.Join(pending, ", "))
+		}
+	}
+	return ordered, nil
+}
+
+func failedDependent(appName string, appConfigs []types.AppConfig, failed map[string]bool) string {
+	for _, appConfig := range appConfigs {
+		if !failed[appConfig.Name] {
+			continue
+		}
+		for _, dependency := range appConfig.DependsOn {
+			if dependency == appName {
+				return appConfig.Name
+			}
+		}
+	}
+	return ""
+}
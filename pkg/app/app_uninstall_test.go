@@ -0,0 +1,69 @@
+package app
+
+import (
+	"capten/pkg/types"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_uninstallAppsInOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		appConfigs []types.AppConfig
+		failApps   []string
+		wantRun    []string
+		wantErr    bool
+	}{
+		{
+			name: "Dependents uninstalled before dependencies",
+			appConfigs: []types.AppConfig{
+				{Name: "pre-install"},
+				{Name: "temporal", DependsOn: []string{"pre-install", "postgresql"}},
+				{Name: "postgresql", DependsOn: []string{"pre-install"}},
+				{Name: "kad", DependsOn: []string{"temporal", "vault"}},
+			},
+			wantRun: []string{"kad", "temporal", "postgresql", "pre-install"},
+		},
+		{
+			name: "Dependencies of failed app are kept",
+			appConfigs: []types.AppConfig{
+				{Name: "postgresql"},
+				{Name: "temporal", DependsOn: []string{"postgresql"}},
+				{Name: "prometheus"},
+			},
+			failApps: []string{"temporal"},
+			wantRun:  []string{"prometheus", "temporal"},
+			wantErr:  true,
+		},
+		{
+			name: "Cyclic dependencies",
+			appConfigs: []types.AppConfig{
+				{Name: "temporal", DependsOn: []string{"postgresql"}},
+				{Name: "postgresql", DependsOn: []string{"temporal"}},
+			},
+			wantRun: []string{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotRun := []string{}
+			_, err := uninstallAppsInOrder(tt.appConfigs, func(appConfig types.AppConfig) error {
+				gotRun = append(gotRun, appConfig.Name)
+				for _, name := range tt.failApps {
+					if name == appConfig.Name {
+						return errors.New("uninstall failed")
+					}
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("uninstallAppsInOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(gotRun, tt.wantRun) {
+				t.Errorf("uninstallAppsInOrder() uninstalled %v, want %v", gotRun, tt.wantRun)
+			}
+		})
+	}
+}
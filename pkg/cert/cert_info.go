@@ -0,0 +1,132 @@
+package cert
+
+import (
+	"capten/pkg/config"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultExpiryWarningDays = 30
+
+	CertValid    = "Valid"
+	CertExpiring = "Expiring"
+	CertExpired  = "Expired"
+	CertMissing  = "Missing"
+	CertInvalid  = "Invalid"
+)
+
+type CertStatus struct {
+	Name     string `json:"name" yaml:"name"`
+	File     string `json:"file" yaml:"file"`
+	Subject  string `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Issuer   string `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	NotAfter string `json:"notAfter,omitempty" yaml:"notAfter,omitempty"`
+	DaysLeft int    `json:"daysLeft" yaml:"daysLeft"`
+	Status   string `json:"status" yaml:"status"`
+	Error    string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type CertStatuses []CertStatus
+
+func (statuses CertStatuses) Table(wide bool) ([]string, [][]string) {
+	headers := []string{"Cert", "Expires", "Days Left", "Status"}
+	if wide {
+		headers = append(headers, "Subject", "Issuer", "File")
+	}
+	rows := [][]string{}
+	for _, status := range statuses {
+		daysLeft := ""
+		if len(status.NotAfter) != 0 {
+			daysLeft = strconv.Itoa(status.DaysLeft)
+		}
+		row := []string{status.Name, status.NotAfter, daysLeft, status.Status}
+		if wide {
+			row = append(row, status.Subject, status.Issuer, status.File)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+func LoadCertificate(certFilePath string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(certFilePath)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to read cert file %s", certFilePath)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("cert file %s does not contain a PEM encoded certificate", certFilePath)
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to parse cert file %s", certFilePath)
+	}
+	return certificate, nil
+}
+
+// CertsStatus reports the expiry of the root CA, intermediate CA, agent and client certs,
+// a cert expiring within the warning window is reported as expiring. The CA certs held only by
+// an external CA are left out for the import and cert-manager cert sources, and the agent cert is left
+// out for cert-manager, which renews it in the cluster secret while the local copy is never updated
+func CertsStatus(captenConfig config.CaptenConfig, warningWindow time.Duration) CertStatuses {
+	now := time.Now()
+	selfSigned := captenConfig.CertSource == CertSourceSelfSigned || len(captenConfig.CertSource) == 0
+	statuses := CertStatuses{}
+	for _, certFile := range []struct {
+		name     string
+		fileName string
+		ca       bool
+	}{
+		{"root-ca", rootCACertFileName, true},
+		{"intermediate-ca", interCACertFileName, true},
+		{"agent", captenConfig.AgentCertFileName, false},
+		{"client", captenConfig.ClientCertFileName, false},
+	} {
+		if certFile.name == "agent" && captenConfig.CertSource == CertSourceCertManager {
+			continue
+		}
+		status := certStatus(certFile.name,
+			captenConfig.PrepareFilePath(captenConfig.CertDirPath, certFile.fileName), warningWindow, now)
+		if certFile.ca && !selfSigned && status.Status == CertMissing {
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func certStatus(name, certFilePath string, warningWindow time.Duration, now time.Time) CertStatus {
+	status := CertStatus{Name: name, File: certFilePath}
+	certificate, err := LoadCertificate(certFilePath)
+	if err != nil {
+		status.Status = CertInvalid
+		if os.IsNotExist(errors.Cause(err)) {
+			status.Status = CertMissing
+		}
+		status.Error = err.Error()
+		return status
+	}
+
+	status.Subject = certificate.Subject.String()
+	status.Issuer = certificate.Issuer.String()
+	status.NotAfter = certificate.NotAfter.Format(time.RFC3339)
+	status.DaysLeft = int(certificate.NotAfter.Sub(now).Hours() / 24)
+	switch {
+	case now.After(certificate.NotAfter):
+		status.Status = CertExpired
+	case certificate.NotAfter.Sub(now) < warningWindow:
+		status.Status = CertExpiring
+	default:
+		status.Status = CertValid
+	}
+	return status
+}
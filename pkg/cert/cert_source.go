@@ -0,0 +1,411 @@
+package cert
+
+import (
+	"bytes"
+	"capten/pkg/clog"
+	"capten/pkg/config"
+	"capten/pkg/k8s"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	CertSourceSelfSigned  = "self-signed"
+	CertSourceImport      = "import"
+	CertSourceCertManager = "cert-manager"
+)
+
+type CertSource interface {
+	// PrepareCerts writes the agent and client certs and the CA cert chain to the cert dir
+	PrepareCerts(captenConfig config.CaptenConfig) error
+	// PublishCerts creates or updates the cert secrets on the cluster
+	PublishCerts(captenConfig config.CaptenConfig) error
+	// SignsCerts is true when the intermediate CA key is in the cert dir to sign certs and CRLs
+	SignsCerts(captenConfig config.CaptenConfig) bool
+}
+
+func getCertSource(captenConfig config.CaptenConfig) (CertSource, error) {
+	switch captenConfig.CertSource {
+	case CertSourceSelfSigned, "":
+		return selfSignedSource{}, nil
+	case CertSourceImport:
+		return importSource{}, nil
+	case CertSourceCertManager:
+		return certManagerSource{}, nil
+	default:
+		return nil, fmt.Errorf("cert source '%s' is not supported", captenConfig.CertSource)
+	}
+}
+
+func PrepareCerts(captenConfig config.CaptenConfig) error {
+	source, err := getCertSource(captenConfig)
+	if err != nil {
+		return err
+	}
+	return source.PrepareCerts(captenConfig)
+}
+
+func PublishCerts(captenConfig config.CaptenConfig) error {
+	source, err := getCertSource(captenConfig)
+	if err != nil {
+		return err
+	}
+	return source.PublishCerts(captenConfig)
+}
+
+// ConfigureClusterIssuer creates the CA cluster issuer from the intermediate CA, a cert source
+// without the intermediate CA key uses the existing cluster issuer of the capten config
+func ConfigureClusterIssuer(captenConfig config.CaptenConfig) error {
+	source, err := getCertSource(captenConfig)
+	if err != nil {
+		return err
+	}
+	if !source.SignsCerts(captenConfig) {
+		clog.Logger.Infof("Using existing cluster issuer %s for cert source %s", captenConfig.ClusterCAIssuer, captenConfig.CertSource)
+		return nil
+	}
+	return k8s.CreateOrUpdateClusterIssuer(captenConfig)
+}
+
+func checkSigningCA(captenConfig config.CaptenConfig) error {
+	source, err := getCertSource(captenConfig)
+	if err != nil {
+		return err
+	}
+	if !source.SignsCerts(captenConfig) {
+		return fmt.Errorf("cert source %s has no intermediate CA key to sign certs", captenConfig.CertSource)
+	}
+	return nil
+}
+
+type selfSignedSource struct{}
+
+func (selfSignedSource) PrepareCerts(captenConfig config.CaptenConfig) error {
+	if !checkCertsExist(captenConfig) || captenConfig.ForceGenerateCerts {
+		return generateCerts(captenConfig)
+	}
+	clog.Logger.Debug("Cert files exist, skipped generating certs")
+	return nil
+}
+
+func (selfSignedSource) PublishCerts(captenConfig config.CaptenConfig) error {
+	return k8s.CreateOrUpdateCertSecrets(captenConfig)
+}
+
+func (selfSignedSource) SignsCerts(captenConfig config.CaptenConfig) bool {
+	return true
+}
+
+// importSource imports the certs of an external CA, either an intermediate CA cert and key
+// used to sign the agent and client certs, or the agent and client certs themselves
+type importSource struct{}
+
+func (importSource) importsCA(captenConfig config.CaptenConfig) bool {
+	return len(captenConfig.ImportCAKeyFilePath) != 0
+}
+
+func (s importSource) PrepareCerts(captenConfig config.CaptenConfig) error {
+	if len(captenConfig.ImportCAChainFilePath) == 0 {
+		return fmt.Errorf("IMPORT_CA_CHAIN_FILE is required for the import cert source")
+	}
+	if certFilesExist(captenConfig, s.certFileNames(captenConfig)) && !captenConfig.ForceGenerateCerts {
+		clog.Logger.Debug("Cert files exist, skipped importing certs")
+		return nil
+	}
+
+	err := os.MkdirAll(captenConfig.PrepareDirPath(captenConfig.CertDirPath), folderPrmission)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to create directory %s", captenConfig.CertDirPath)
+	}
+
+	chainPEM, chain, err := loadCertificates(captenConfig.ImportCAChainFilePath)
+	if err != nil {
+		return err
+	}
+	if s.importsCA(captenConfig) {
+		return importCA(captenConfig, chainPEM, chain)
+	}
+	return importLeafCerts(captenConfig, chainPEM, chain)
+}
+
+func (s importSource) PublishCerts(captenConfig config.CaptenConfig) error {
+	if s.importsCA(captenConfig) {
+		return k8s.CreateOrUpdateCertSecrets(captenConfig)
+	}
+	return k8s.CreateOrUpdateAgentCertSecrets(captenConfig)
+}
+
+func (s importSource) SignsCerts(captenConfig config.CaptenConfig) bool {
+	return s.importsCA(captenConfig)
+}
+
+func (s importSource) certFileNames(captenConfig config.CaptenConfig) []string {
+	fileNames := []string{
+		captenConfig.AgentCertFileName, captenConfig.AgentKeyFileName,
+		captenConfig.ClientCertFileName, captenConfig.ClientKeyFileName,
+		captenConfig.CAFileName,
+	}
+	if s.importsCA(captenConfig) {
+		fileNames = append(fileNames, interCACertFileName, interCAKeyFileName)
+	}
+	return fileNames
+}
+
+func importCA(captenConfig config.CaptenConfig, chainPEM []byte, chain []*x509.Certificate) error {
+	if len(captenConfig.ImportCACertFilePath) == 0 {
+		return fmt.Errorf("IMPORT_CA_CERT_FILE is required to import the CA key")
+	}
+	caCert, err := LoadCertificate(captenConfig.ImportCACertFilePath)
+	if err != nil {
+		return err
+	}
+	caKey, err := loadPrivateKey(captenConfig.ImportCAKeyFilePath)
+	if err != nil {
+		return err
+	}
+
+	if !caCert.IsCA || caCert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return fmt.Errorf("imported CA cert %s can not sign certs", captenConfig.ImportCACertFilePath)
+	}
+	if !publicKeyEqual(caKey, caCert.PublicKey) {
+		return fmt.Errorf("imported CA key %s does not match the CA cert", captenConfig.ImportCAKeyFilePath)
+	}
+	if err := verifyChain(caCert, chain, x509.ExtKeyUsageAny); err != nil {
+		return errors.WithMessage(err, "imported CA cert does not chain to the CA chain")
+	}
+
+	caCertPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert.Raw})
+	err = os.WriteFile(captenConfig.PrepareFilePath(captenConfig.CertDirPath, interCACertFileName), caCertPEM, filePrmission)
+	if err != nil {
+		return errors.WithMessage(err, "error while writing imported CA cert")
+	}
+	err = writePrivateKey(captenConfig.PrepareFilePath(captenConfig.CertDirPath, interCAKeyFileName), caKey)
+	if err != nil {
+		return errors.WithMessage(err, "error while writing imported CA key")
+	}
+
+	if !slices.ContainsFunc(chain, caCert.Equal) {
+		chainPEM = append(chainPEM, caCertPEM...)
+	}
+	err = os.WriteFile(captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.CAFileName), chainPEM, filePrmission)
+	if err != nil {
+		return errors.WithMessage(err, "error while writing to ca cert file")
+	}
+
+	if err := generateAgentCert(captenConfig, caKey, caCert); err != nil {
+		return err
+	}
+	if err := generateClientCert(captenConfig, caKey, caCert); err != nil {
+		return err
+	}
+	return generateCaptenClientCertZip(captenConfig)
+}
+
+func importLeafCerts(captenConfig config.CaptenConfig, chainPEM []byte, chain []*x509.Certificate) error {
+	for _, leaf := range []struct {
+		name         string
+		certFilePath string
+		keyFilePath  string
+		certFileName string
+		keyFileName  string
+		usage        x509.ExtKeyUsage
+		dnsNames     []string
+	}{
+		{"agent", captenConfig.ImportAgentCertFilePath, captenConfig.ImportAgentKeyFilePath,
+			captenConfig.AgentCertFileName, captenConfig.AgentKeyFileName, x509.ExtKeyUsageServerAuth, captenConfig.AgentDNSNames},
+		{"client", captenConfig.ImportClientCertFilePath, captenConfig.ImportClientKeyFilePath,
+			captenConfig.ClientCertFileName, captenConfig.ClientKeyFileName, x509.ExtKeyUsageClientAuth, nil},
+	} {
+		if len(leaf.certFilePath) == 0 || len(leaf.keyFilePath) == 0 {
+			return fmt.Errorf("the %s cert and key files are required to import certs without the CA key", leaf.name)
+		}
+		certPEM, err := os.ReadFile(leaf.certFilePath)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to read %s cert file %s", leaf.name, leaf.certFilePath)
+		}
+		keyPEM, err := os.ReadFile(leaf.keyFilePath)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to read %s key file %s", leaf.name, leaf.keyFilePath)
+		}
+
+		keyPair, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return errors.WithMessagef(err, "invalid %s cert and key", leaf.name)
+		}
+		certificate, err := x509.ParseCertificate(keyPair.Certificate[0])
+		if err != nil {
+			return errors.WithMessagef(err, "failed to parse %s cert", leaf.name)
+		}
+		if err := verifyChain(certificate, chain, leaf.usage); err != nil {
+			return errors.WithMessagef(err, "imported %s cert does not chain to the CA chain", leaf.name)
+		}
+		if err := checkDNSNames(certificate, leaf.dnsNames); err != nil {
+			return errors.WithMessagef(err, "imported %s cert", leaf.name)
+		}
+
+		err = os.WriteFile(captenConfig.PrepareFilePath(captenConfig.CertDirPath, leaf.certFileName), certPEM, filePrmission)
+		if err != nil {
+			return errors.WithMessagef(err, "error while writing imported %s cert", leaf.name)
+		}
+		err = os.WriteFile(captenConfig.PrepareFilePath(captenConfig.CertDirPath, leaf.keyFileName), keyPEM, filePrmission)
+		if err != nil {
+			return errors.WithMessagef(err, "error while writing imported %s key", leaf.name)
+		}
+	}
+
+	err := os.WriteFile(captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.CAFileName), chainPEM, filePrmission)
+	if err != nil {
+		return errors.WithMessage(err, "error while writing to ca cert file")
+	}
+	return generateCaptenClientCertZip(captenConfig)
+}
+
+// certManagerSource requests the agent and client certs from the existing cluster issuer with cert-manager,
+// cert-manager keeps the agent cert secret up to date and renews the certs before they expire
+type certManagerSource struct{}
+
+func (certManagerSource) PrepareCerts(captenConfig config.CaptenConfig) error {
+	err := os.MkdirAll(captenConfig.PrepareDirPath(captenConfig.CertDirPath), folderPrmission)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to create directory %s", captenConfig.CertDirPath)
+	}
+
+	for _, request := range []struct {
+		certificate  k8s.CertificateRequest
+		certFileName string
+		keyFileName  string
+	}{
+		{k8s.CertificateRequest{Name: captenConfig.AgentCertSecretName, Namespace: captenConfig.CaptenNamespace,
+			SecretName: captenConfig.AgentCertSecretName, CommonName: captenConfig.AgentCertCommonName,
+			DNSNames: captenConfig.AgentDNSNames, Usages: []certmanagerv1.KeyUsage{certmanagerv1.UsageDigitalSignature,
+				certmanagerv1.UsageKeyEncipherment, certmanagerv1.UsageServerAuth}},
+			captenConfig.AgentCertFileName, captenConfig.AgentKeyFileName},
+		{k8s.CertificateRequest{Name: captenConfig.ClientCertSecretName, Namespace: captenConfig.CaptenNamespace,
+			SecretName: captenConfig.ClientCertSecretName, CommonName: captenConfig.CaptenClientCertCommonName,
+			Usages: []certmanagerv1.KeyUsage{certmanagerv1.UsageDigitalSignature,
+				certmanagerv1.UsageKeyEncipherment, certmanagerv1.UsageClientAuth}},
+			captenConfig.ClientCertFileName, captenConfig.ClientKeyFileName},
+	} {
+		secretData, err := k8s.RequestCertificate(captenConfig, request.certificate)
+		if err != nil {
+			return err
+		}
+		if len(secretData["ca.crt"]) == 0 {
+			return fmt.Errorf("cluster issuer %s does not provide the CA cert in secret %s",
+				captenConfig.ClusterCAIssuer, request.certificate.SecretName)
+		}
+
+		for fileName, data := range map[string][]byte{
+			request.certFileName:    secretData["tls.crt"],
+			request.keyFileName:     secretData["tls.key"],
+			captenConfig.CAFileName: secretData["ca.crt"],
+		} {
+			err = os.WriteFile(captenConfig.PrepareFilePath(captenConfig.CertDirPath, fileName), data, filePrmission)
+			if err != nil {
+				return errors.WithMessagef(err, "error while writing cert file %s", fileName)
+			}
+		}
+	}
+	return generateCaptenClientCertZip(captenConfig)
+}
+
+func (certManagerSource) PublishCerts(captenConfig config.CaptenConfig) error {
+	return k8s.CreateOrUpdateAgentCACertSecret(captenConfig)
+}
+
+func (certManagerSource) SignsCerts(captenConfig config.CaptenConfig) bool {
+	return false
+}
+
+func certFilesExist(captenConfig config.CaptenConfig, fileNames []string) bool {
+	for _, fileName := range fileNames {
+		if _, err := os.Stat(captenConfig.PrepareFilePath(captenConfig.CertDirPath, fileName)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func loadCertificates(certFilePath string) ([]byte, []*x509.Certificate, error) {
+	data, err := os.ReadFile(certFilePath)
+	if err != nil {
+		return nil, nil, errors.WithMessagef(err, "failed to read cert file %s", certFilePath)
+	}
+
+	certificates, err := parseCertificates(data, "cert file "+certFilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, certificates, nil
+}
+
+func parseCertificates(data []byte, source string) ([]*x509.Certificate, error) {
+	certificates := []*x509.Certificate{}
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to parse %s", source)
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("%s does not contain a PEM encoded certificate", source)
+	}
+	return certificates, nil
+}
+
+// verifyChain verifies the cert against the self-signed certs of the chain as roots
+// and the other certs of the chain as intermediates
+func verifyChain(certificate *x509.Certificate, chain []*x509.Certificate, usage x509.ExtKeyUsage) error {
+	roots := x509.NewCertPool()
+	intermediates := x509.NewCertPool()
+	rootCount := 0
+	for _, chainCert := range chain {
+		if bytes.Equal(chainCert.RawIssuer, chainCert.RawSubject) && chainCert.CheckSignatureFrom(chainCert) == nil {
+			roots.AddCert(chainCert)
+			rootCount++
+		} else {
+			intermediates.AddCert(chainCert)
+		}
+	}
+	if rootCount == 0 {
+		return fmt.Errorf("CA chain has no root CA cert")
+	}
+
+	_, err := certificate.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates,
+		KeyUsages: []x509.ExtKeyUsage{usage}})
+	return err
+}
+
+func checkDNSNames(certificate *x509.Certificate, dnsNames []string) error {
+	missingNames := []string{}
+	for _, dnsName := range dnsNames {
+		if slices.Contains(certificate.DNSNames, dnsName) {
+			continue
+		}
+		if !strings.HasPrefix(dnsName, "*.") && certificate.VerifyHostname(dnsName) == nil {
+			continue
+		}
+		missingNames = append(missingNames, dnsName)
+	}
+	if len(missingNames) != 0 {
+		return fmt.Errorf("cert is not valid for the agent DNS names %s", strings.Join(missingNames, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"capten/pkg/config"
+)
+
+func TestImportCA(t *testing.T) {
+	externalConfig := testCertConfig(t)
+	if err := generateCerts(externalConfig); err != nil {
+		t.Fatalf("generateCerts() error = %v", err)
+	}
+
+	captenConfig := testCertConfig(t)
+	captenConfig.CertSource = CertSourceImport
+	captenConfig.ImportCAChainFilePath = externalConfig.PrepareFilePath(externalConfig.CertDirPath, rootCACertFileName)
+	captenConfig.ImportCACertFilePath = externalConfig.PrepareFilePath(externalConfig.CertDirPath, interCACertFileName)
+	captenConfig.ImportCAKeyFilePath = externalConfig.PrepareFilePath(externalConfig.CertDirPath, externalConfig.AgentKeyFileName)
+	if err := PrepareCerts(captenConfig); err == nil {
+		t.Fatal("PrepareCerts() with a key not matching the CA cert, want error")
+	}
+
+	captenConfig.ImportCAKeyFilePath = externalConfig.PrepareFilePath(externalConfig.CertDirPath, interCAKeyFileName)
+	if err := PrepareCerts(captenConfig); err != nil {
+		t.Fatalf("PrepareCerts() error = %v", err)
+	}
+	verifyCert(t, captenConfig, captenConfig.AgentCertFileName, x509.ExtKeyUsageServerAuth)
+	verifyCert(t, captenConfig, captenConfig.ClientCertFileName, x509.ExtKeyUsageClientAuth)
+
+	if err := RotateCerts(captenConfig, RotateOptions{Client: true}); err != nil {
+		t.Errorf("RotateCerts() client error = %v", err)
+	}
+	if err := RotateCerts(captenConfig, RotateOptions{Intermediate: true}); err == nil {
+		t.Error("RotateCerts() intermediate of imported CA, want error")
+	}
+	if _, err := IssueClientCert(captenConfig, "ci", 24*time.Hour); err != nil {
+		t.Errorf("IssueClientCert() error = %v", err)
+	}
+}
+
+func TestImportLeafCerts(t *testing.T) {
+	externalConfig := testCertConfig(t)
+	otherConfig := testCertConfig(t)
+	for _, cfg := range []config.CaptenConfig{externalConfig, otherConfig} {
+		if err := generateCerts(cfg); err != nil {
+			t.Fatalf("generateCerts() error = %v", err)
+		}
+	}
+
+	importConfig := func(chainConfig config.CaptenConfig, dnsNames []string) config.CaptenConfig {
+		captenConfig := testCertConfig(t)
+		captenConfig.CertSource = CertSourceImport
+		captenConfig.AgentDNSNames = dnsNames
+		captenConfig.ImportCAChainFilePath = chainConfig.PrepareFilePath(chainConfig.CertDirPath, chainConfig.CAFileName)
+		captenConfig.ImportAgentCertFilePath = externalConfig.PrepareFilePath(externalConfig.CertDirPath, externalConfig.AgentCertFileName)
+		captenConfig.ImportAgentKeyFilePath = externalConfig.PrepareFilePath(externalConfig.CertDirPath, externalConfig.AgentKeyFileName)
+		captenConfig.ImportClientCertFilePath = externalConfig.PrepareFilePath(externalConfig.CertDirPath, externalConfig.ClientCertFileName)
+		captenConfig.ImportClientKeyFilePath = externalConfig.PrepareFilePath(externalConfig.CertDirPath, externalConfig.ClientKeyFileName)
+		return captenConfig
+	}
+
+	tests := []struct {
+		name         string
+		captenConfig config.CaptenConfig
+		wantErr      bool
+	}{
+		{name: "Valid", captenConfig: importConfig(externalConfig, externalConfig.AgentDNSNames)},
+		{name: "Other CA chain", captenConfig: importConfig(otherConfig, externalConfig.AgentDNSNames), wantErr: true},
+		{name: "DNS name not in cert", captenConfig: importConfig(externalConfig, []string{"agent.other.com"}), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := PrepareCerts(tt.captenConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PrepareCerts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !bytes.Equal(readCertFile(t, tt.captenConfig, tt.captenConfig.AgentCertFileName),
+				readCertFile(t, externalConfig, externalConfig.AgentCertFileName)) {
+				t.Error("PrepareCerts() did not import the agent cert")
+			}
+			if _, err := IssueClientCert(tt.captenConfig, "ci", 24*time.Hour); err == nil {
+				t.Error("IssueClientCert() without the CA key, want error")
+			}
+		})
+	}
+}
+
+func TestCheckDNSNames(t *testing.T) {
+	certificate := &x509.Certificate{DNSNames: []string{"*.example.com", "agent.example.com"}}
+	tests := []struct {
+		name     string
+		dnsNames []string
+		wantErr  bool
+	}{
+		{name: "Exact names", dnsNames: []string{"*.example.com", "agent.example.com"}},
+		{name: "Covered by wildcard", dnsNames: []string{"vault-cred.example.com"}},
+		{name: "Wildcard not in cert", dnsNames: []string{"*.other.com"}, wantErr: true},
+		{name: "Name not in cert", dnsNames: []string{"agent.other.com"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkDNSNames(certificate, tt.dnsNames); (err != nil) != tt.wantErr {
+				t.Errorf("checkDNSNames() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package cert
+
+import (
+	"archive/zip"
+	"capten/pkg/config"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	clientCertsDirName      = "clients"
+	clientInventoryFileName = "clients.yaml"
+	crlValidity             = 365 * 24 * time.Hour
+)
+
+var clientNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,63}$`)
+
+type ClientCert struct {
+	Name         string     `json:"name" yaml:"name"`
+	SerialNumber string     `json:"serialNumber" yaml:"serialNumber"`
+	NotBefore    time.Time  `json:"notBefore" yaml:"notBefore"`
+	NotAfter     time.Time  `json:"notAfter" yaml:"notAfter"`
+	Revoked      bool       `json:"revoked" yaml:"revoked"`
+	RevokedAt    *time.Time `json:"revokedAt,omitempty" yaml:"revokedAt,omitempty"`
+}
+
+type ClientCertInventory struct {
+	CRLNumber   int64        `json:"crlNumber" yaml:"crlNumber"`
+	ClientCerts []ClientCert `json:"clientCerts" yaml:"clientCerts"`
+	filePath    string
+}
+
+// ParseTTL parses a cert lifetime, in days with the d suffix (e.g. 90d) or as a duration (e.g. 36h)
+func ParseTTL(ttl string) (time.Duration, error) {
+	var duration time.Duration
+	if days, found := strings.CutSuffix(ttl, "d"); found {
+		count, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ttl %q, use days (e.g. 90d) or a duration (e.g. 36h)", ttl)
+		}
+		duration = time.Duration(count) * 24 * time.Hour
+	} else {
+		var err error
+		duration, err = time.ParseDuration(ttl)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ttl %q, use days (e.g. 90d) or a duration (e.g. 36h)", ttl)
+		}
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("ttl %q must be positive", ttl)
+	}
+	return duration, nil
+}
+
+func LoadClientCertInventory(captenConfig config.CaptenConfig) (*ClientCertInventory, error) {
+	inventory := &ClientCertInventory{filePath: captenConfig.PrepareFilePath(captenConfig.CertDirPath, clientInventoryFileName)}
+	data, err := os.ReadFile(inventory.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return inventory, nil
+		}
+		return nil, errors.WithMessagef(err, "failed to read client cert inventory, %s", inventory.filePath)
+	}
+
+	err = yaml.Unmarshal(data, inventory)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to unmarshal client cert inventory, %s", inventory.filePath)
+	}
+	return inventory, nil
+}
+
+func (inventory *ClientCertInventory) Save() error {
+	data, err := yaml.Marshal(inventory)
+	if err != nil {
+		return errors.WithMessage(err, "failed to marshal client cert inventory")
+	}
+
+	err = os.WriteFile(inventory.filePath, data, filePrmission)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to write client cert inventory, %s", inventory.filePath)
+	}
+	return nil
+}
+
+func (inventory *ClientCertInventory) active(name string) (int, bool) {
+	for i, clientCert := range inventory.ClientCerts {
+		if clientCert.Name == name && !clientCert.Revoked {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+func (inventory *ClientCertInventory) hasRevoked() bool {
+	for _, clientCert := range inventory.ClientCerts {
+		if clientCert.Revoked {
+			return true
+		}
+	}
+	return false
+}
+
+// IssueClientCert signs a client cert for the given name with the intermediate CA, records it in the
+// client cert inventory and exports the cert, key and CA cert chain to a zip, the zip path is returned
+func IssueClientCert(captenConfig config.CaptenConfig, name string, ttl time.Duration) (string, error) {
+	if !clientNameRegex.MatchString(name) {
+		return "", fmt.Errorf("invalid client name %q, use up to 64 letters, digits, '.', '_' or '-'", name)
+	}
+	if err := checkSigningCA(captenConfig); err != nil {
+		return "", err
+	}
+
+	inventory, err := LoadClientCertInventory(captenConfig)
+	if err != nil {
+		return "", err
+	}
+	if _, found := inventory.active(name); found {
+		return "", fmt.Errorf("client cert %s is already issued, revoke it before issuing a new one", name)
+	}
+
+	interKey, interCACert, err := loadCAKeyPair(captenConfig, interCACertFileName, interCAKeyFileName)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	notAfter := now.Add(ttl)
+	if notAfter.After(interCACert.NotAfter) {
+		return "", fmt.Errorf("ttl exceeds the expiry of the intermediate CA cert on %s", interCACert.NotAfter.Format(time.RFC3339))
+	}
+
+	serialNumber, err := randomSerialNumber()
+	if err != nil {
+		return "", err
+	}
+
+	clientKey, err := generateKey(captenConfig.CertKeyAlgorithm, captenConfig.CertKeySize, false)
+	if err != nil {
+		return "", errors.WithMessagef(err, "failed to generate key for client certificate %s", name)
+	}
+
+	clientCertTemplate := x509.Certificate{
+		Subject: pkix.Name{
+			Organization: []string{captenConfig.OrgName},
+			CommonName:   name,
+		},
+		SerialNumber:          serialNumber,
+		NotBefore:             now,
+		NotAfter:              notAfter,
+		KeyUsage:              leafKeyUsage(clientKey),
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  false,
+	}
+
+	clientCert, err := x509.CreateCertificate(rand.Reader, &clientCertTemplate, interCACert, clientKey.Public(), interKey)
+	if err != nil {
+		return "", errors.WithMessagef(err, "failed to create client certificate %s", name)
+	}
+
+	err = os.MkdirAll(captenConfig.PrepareFilePath(captenConfig.CertDirPath, clientCertsDirName), folderPrmission)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to create client certs directory")
+	}
+
+	certFilePath := clientCertFilePath(captenConfig, name, ".crt")
+	clientCertPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: clientCert})
+	if err := os.WriteFile(certFilePath, clientCertPEM, filePrmission); err != nil {
+		return "", errors.WithMessagef(err, "error while writing client cert to %s", certFilePath)
+	}
+
+	keyFilePath := clientCertFilePath(captenConfig, name, ".key")
+	if err := writePrivateKey(keyFilePath, clientKey); err != nil {
+		return "", errors.WithMessagef(err, "error while writing client key to %s", keyFilePath)
+	}
+
+	exportFilePath := clientCertFilePath(captenConfig, name, "-"+captenConfig.ClientCertExportFileName)
+	err = writeClientCertZip(captenConfig, exportFilePath, certFilePath, keyFilePath)
+	if err != nil {
+		return "", err
+	}
+
+	inventory.ClientCerts = append(inventory.ClientCerts, ClientCert{
+		Name:         name,
+		SerialNumber: serialNumber.Text(16),
+		NotBefore:    clientCertTemplate.NotBefore,
+		NotAfter:     clientCertTemplate.NotAfter,
+	})
+	if err := inventory.Save(); err != nil {
+		return "", err
+	}
+	return exportFilePath, nil
+}
+
+// RevokeClientCert marks the issued client cert of the given name as revoked
+// and writes the CRL of all revoked client certs signed by the intermediate CA
+func RevokeClientCert(captenConfig config.CaptenConfig, name string) error {
+	if err := checkSigningCA(captenConfig); err != nil {
+		return err
+	}
+
+	inventory, err := LoadClientCertInventory(captenConfig)
+	if err != nil {
+		return err
+	}
+	index, found := inventory.active(name)
+	if !found {
+		return fmt.Errorf("no issued client cert %s to revoke", name)
+	}
+
+	interKey, interCACert, err := loadCAKeyPair(captenConfig, interCACertFileName, interCAKeyFileName)
+	if err != nil {
+		return err
+	}
+
+	revokedAt := time.Now()
+	inventory.ClientCerts[index].Revoked = true
+	inventory.ClientCerts[index].RevokedAt = &revokedAt
+	if err := generateCRL(captenConfig, inventory, interKey, interCACert); err != nil {
+		return err
+	}
+	return inventory.Save()
+}
+
+// refreshCRL re-signs the CRL with the current intermediate CA when client certs have been revoked
+func refreshCRL(captenConfig config.CaptenConfig) error {
+	inventory, err := LoadClientCertInventory(captenConfig)
+	if err != nil {
+		return err
+	}
+	if !inventory.hasRevoked() {
+		return nil
+	}
+
+	interKey, interCACert, err := loadCAKeyPair(captenConfig, interCACertFileName, interCAKeyFileName)
+	if err != nil {
+		return err
+	}
+	if err := generateCRL(captenConfig, inventory, interKey, interCACert); err != nil {
+		return err
+	}
+	return inventory.Save()
+}
+
+func generateCRL(captenConfig config.CaptenConfig, inventory *ClientCertInventory,
+	interKey crypto.Signer, interCACert *x509.Certificate) error {
+	if interCACert.KeyUsage&x509.KeyUsageCRLSign == 0 {
+		return fmt.Errorf("intermediate CA cert can not sign CRLs, rotate it with capten cert rotate --intermediate")
+	}
+
+	entries := []x509.RevocationListEntry{}
+	for _, clientCert := range inventory.ClientCerts {
+		if !clientCert.Revoked {
+			continue
+		}
+		serialNumber, ok := new(big.Int).SetString(clientCert.SerialNumber, 16)
+		if !ok {
+			return fmt.Errorf("invalid serial number %s of client cert %s", clientCert.SerialNumber, clientCert.Name)
+		}
+		entry := x509.RevocationListEntry{SerialNumber: serialNumber}
+		if clientCert.RevokedAt != nil {
+			entry.RevocationTime = *clientCert.RevokedAt
+		}
+		entries = append(entries, entry)
+	}
+
+	now := time.Now()
+	inventory.CRLNumber++
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(inventory.CRLNumber),
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(crlValidity),
+		RevokedCertificateEntries: entries,
+	}, interCACert, interKey)
+	if err != nil {
+		return errors.WithMessage(err, "failed to create CRL")
+	}
+
+	crlFilePath := captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.CRLFileName)
+	crlPEM := pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl})
+	if err := os.WriteFile(crlFilePath, crlPEM, filePrmission); err != nil {
+		return errors.WithMessagef(err, "error while writing CRL to %s", crlFilePath)
+	}
+	return nil
+}
+
+func clientCertFilePath(captenConfig config.CaptenConfig, name, suffix string) string {
+	return captenConfig.PrepareFilePath(captenConfig.CertDirPath, clientCertsDirName+"/"+name+suffix)
+}
+
+func writeClientCertZip(captenConfig config.CaptenConfig, zipFilePath, certFilePath, keyFilePath string) error {
+	zipFile, err := os.Create(zipFilePath)
+	if err != nil {
+		return errors.WithMessage(err, "error while creating client cert export file")
+	}
+	defer zipFile.Close()
+
+	zipWriter := zip.NewWriter(zipFile)
+	defer zipWriter.Close()
+
+	err = addFileToZip(zipWriter, captenConfig.ClientCertFileName, certFilePath)
+	if err != nil {
+		return errors.WithMessage(err, "error while adding client cert file to zip")
+	}
+
+	err = addFileToZip(zipWriter, captenConfig.ClientKeyFileName, keyFilePath)
+	if err != nil {
+		return errors.WithMessage(err, "error while adding client key file to zip")
+	}
+
+	err = addFileToZip(zipWriter, captenConfig.CAFileName,
+		captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.CAFileName))
+	if err != nil {
+		return errors.WithMessage(err, "error while adding ca cert file to zip")
+	}
+	return nil
+}
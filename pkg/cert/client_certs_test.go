@@ -0,0 +1,120 @@
+package cert
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"testing"
+	"time"
+
+	"capten/pkg/config"
+)
+
+func TestParseTTL(t *testing.T) {
+	tests := []struct {
+		ttl     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{ttl: "90d", want: 90 * 24 * time.Hour},
+		{ttl: "36h", want: 36 * time.Hour},
+		{ttl: "0d", wantErr: true},
+		{ttl: "-1h", wantErr: true},
+		{ttl: "xd", wantErr: true},
+		{ttl: "90", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ttl, func(t *testing.T) {
+			got, err := ParseTTL(tt.ttl)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTTL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTTL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func loadCRL(t *testing.T, captenConfig config.CaptenConfig) *x509.RevocationList {
+	block, _ := pem.Decode(readCertFile(t, captenConfig, captenConfig.CRLFileName))
+	if block == nil {
+		t.Fatal("CRL file does not contain a PEM block")
+	}
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	interCACert, err := LoadCertificate(captenConfig.PrepareFilePath(captenConfig.CertDirPath, interCACertFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crl.CheckSignatureFrom(interCACert); err != nil {
+		t.Errorf("CRL is not signed by the intermediate CA, %v", err)
+	}
+	return crl
+}
+
+func TestIssueAndRevokeClientCert(t *testing.T) {
+	captenConfig := testCertConfig(t)
+	if err := generateCerts(captenConfig); err != nil {
+		t.Fatalf("generateCerts() error = %v", err)
+	}
+
+	if _, err := IssueClientCert(captenConfig, "../alice", 24*time.Hour); err == nil {
+		t.Error("IssueClientCert() with invalid name, want error")
+	}
+	if _, err := IssueClientCert(captenConfig, "alice", 3*365*24*time.Hour); err == nil {
+		t.Error("IssueClientCert() with ttl beyond the intermediate CA, want error")
+	}
+
+	exportFilePath, err := IssueClientCert(captenConfig, "alice", 90*24*time.Hour)
+	if err != nil {
+		t.Fatalf("IssueClientCert() error = %v", err)
+	}
+	if _, err := os.Stat(exportFilePath); err != nil {
+		t.Errorf("IssueClientCert() export file, %v", err)
+	}
+	verifyCert(t, captenConfig, "clients/alice.crt", x509.ExtKeyUsageClientAuth)
+	if _, err := IssueClientCert(captenConfig, "alice", 24*time.Hour); err == nil {
+		t.Error("IssueClientCert() for an issued name, want error")
+	}
+	if _, err := IssueClientCert(captenConfig, "ci", 24*time.Hour); err != nil {
+		t.Fatalf("IssueClientCert() error = %v", err)
+	}
+
+	if err := RevokeClientCert(captenConfig, "bob"); err == nil {
+		t.Error("RevokeClientCert() for a name not issued, want error")
+	}
+	if err := RevokeClientCert(captenConfig, "alice"); err != nil {
+		t.Fatalf("RevokeClientCert() error = %v", err)
+	}
+
+	inventory, err := LoadClientCertInventory(captenConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inventory.ClientCerts) != 2 || !inventory.ClientCerts[0].Revoked || inventory.ClientCerts[1].Revoked {
+		t.Fatalf("LoadClientCertInventory() = %+v, want alice revoked and ci active", inventory.ClientCerts)
+	}
+
+	aliceCert, err := LoadCertificate(captenConfig.PrepareFilePath(captenConfig.CertDirPath, "clients/alice.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	crl := loadCRL(t, captenConfig)
+	if len(crl.RevokedCertificateEntries) != 1 || crl.RevokedCertificateEntries[0].SerialNumber.Cmp(aliceCert.SerialNumber) != 0 {
+		t.Errorf("CRL entries = %+v, want the serial number of alice", crl.RevokedCertificateEntries)
+	}
+
+	if _, err := IssueClientCert(captenConfig, "alice", 24*time.Hour); err != nil {
+		t.Errorf("IssueClientCert() for a revoked name error = %v", err)
+	}
+
+	if err := RotateCerts(captenConfig, RotateOptions{Intermediate: true}); err != nil {
+		t.Fatalf("RotateCerts() intermediate error = %v", err)
+	}
+	if rotatedCRL := loadCRL(t, captenConfig); rotatedCRL.Number.Cmp(crl.Number) <= 0 {
+		t.Errorf("CRL number after rotation = %v, want greater than %v", rotatedCRL.Number, crl.Number)
+	}
+}
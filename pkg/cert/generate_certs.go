@@ -4,13 +4,12 @@ import (
 	"archive/zip"
 	"capten/pkg/clog"
 	"capten/pkg/config"
+	"crypto"
 	"crypto/rand"
-	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"io"
-	"math/big"
 	"os"
 	"time"
 
@@ -20,22 +19,12 @@ import (
 const (
 	folderPrmission     os.FileMode = 0755
 	filePrmission       os.FileMode = 0644
-	caBitSize                       = 4096
-	certBitSize                     = 2048
 	rootCAKeyFileName               = "root.key"
 	rootCACertFileName              = "root.crt"
 	interCAKeyFileName              = "inter-ca.key"
 	interCACertFileName             = "inter-ca.crt"
 )
 
-func PrepareCerts(captenConfig config.CaptenConfig) error {
-	if !checkCertsExist(captenConfig) || captenConfig.ForceGenerateCerts {
-		return generateCerts(captenConfig)
-	}
-	clog.Logger.Debug("Cert files exist, skipped generating certs")
-	return nil
-}
-
 func checkCertsExist(captenConfig config.CaptenConfig) bool {
 	certFiles := []string{
 		captenConfig.PrepareFilePath(captenConfig.CertDirPath, rootCACertFileName),
@@ -94,11 +83,16 @@ func generateCerts(captenConfig config.CaptenConfig) error {
 	return nil
 }
 
-func generateCACert(captenConfig config.CaptenConfig) (rootKey *rsa.PrivateKey,
+func generateCACert(captenConfig config.CaptenConfig) (rootKey crypto.Signer,
 	rootCertTemplate *x509.Certificate, err error) {
-	rootKey, err = rsa.GenerateKey(rand.Reader, caBitSize)
+	rootKey, err = generateKey(captenConfig.CertKeyAlgorithm, captenConfig.CertCAKeySize, true)
+	if err != nil {
+		err = errors.WithMessage(err, "failed to generate key for root certificate")
+		return
+	}
+
+	serialNumber, err := randomSerialNumber()
 	if err != nil {
-		err = errors.WithMessage(err, "failed to generate RSA key for root certificate")
 		return
 	}
 
@@ -107,15 +101,15 @@ func generateCACert(captenConfig config.CaptenConfig) (rootKey *rsa.PrivateKey,
 			Organization: []string{captenConfig.OrgName},
 			CommonName:   captenConfig.RootCACommonName,
 		},
-		SerialNumber:          big.NewInt(1),
+		SerialNumber:          serialNumber,
 		NotBefore:             time.Now(),
-		NotAfter:              time.Now().AddDate(5, 0, 0),
+		NotAfter:              time.Now().AddDate(0, 0, captenConfig.RootCAValidityDays),
 		IsCA:                  true,
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
 		BasicConstraintsValid: true,
 	}
 
-	rootCert, err := x509.CreateCertificate(rand.Reader, rootCertTemplate, rootCertTemplate, &rootKey.PublicKey, rootKey)
+	rootCert, err := x509.CreateCertificate(rand.Reader, rootCertTemplate, rootCertTemplate, rootKey.Public(), rootKey)
 	if err != nil {
 		err = errors.WithMessage(err, "failed to create root CA certificate")
 		return
@@ -128,8 +122,7 @@ func generateCACert(captenConfig config.CaptenConfig) (rootKey *rsa.PrivateKey,
 		return
 	}
 
-	rootKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rootKey)})
-	err = os.WriteFile(captenConfig.PrepareFilePath(captenConfig.CertDirPath, rootCAKeyFileName), rootKeyPEM, filePrmission)
+	err = writePrivateKey(captenConfig.PrepareFilePath(captenConfig.CertDirPath, rootCAKeyFileName), rootKey)
 	if err != nil {
 		err = errors.WithMessage(err, "error while writing from root CA key")
 		return
@@ -137,11 +130,16 @@ func generateCACert(captenConfig config.CaptenConfig) (rootKey *rsa.PrivateKey,
 	return
 }
 
-func generateIntermediateCACert(captenConfig config.CaptenConfig, rootKey *rsa.PrivateKey,
-	rootCertTemplate *x509.Certificate) (interKey *rsa.PrivateKey, interCACertTemplate *x509.Certificate, err error) {
-	interKey, err = rsa.GenerateKey(rand.Reader, caBitSize)
+func generateIntermediateCACert(captenConfig config.CaptenConfig, rootKey crypto.Signer,
+	rootCertTemplate *x509.Certificate) (interKey crypto.Signer, interCACertTemplate *x509.Certificate, err error) {
+	interKey, err = generateKey(captenConfig.CertKeyAlgorithm, captenConfig.CertCAKeySize, true)
+	if err != nil {
+		err = errors.WithMessage(err, "failed to generate key for intermediate certificate")
+		return
+	}
+
+	serialNumber, err := randomSerialNumber()
 	if err != nil {
-		err = errors.WithMessage(err, "failed to generate RSA key for intermediate certificate")
 		return
 	}
 
@@ -151,15 +149,15 @@ func generateIntermediateCACert(captenConfig config.CaptenConfig, rootKey *rsa.P
 			CommonName:   captenConfig.IntermediateCACommonName,
 			Locality:     []string{"agent"},
 		},
-		SerialNumber:          big.NewInt(1),
+		SerialNumber:          serialNumber,
 		NotBefore:             time.Now(),
-		NotAfter:              time.Now().AddDate(2, 0, 0),
+		NotAfter:              time.Now().AddDate(0, 0, captenConfig.IntermediateCAValidityDays),
 		IsCA:                  true,
-		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
 		BasicConstraintsValid: true,
 	}
 
-	interCert, err := x509.CreateCertificate(rand.Reader, interCACertTemplate, rootCertTemplate, &interKey.PublicKey, rootKey)
+	interCert, err := x509.CreateCertificate(rand.Reader, interCACertTemplate, rootCertTemplate, interKey.Public(), rootKey)
 	if err != nil {
 		err = errors.WithMessage(err, "failed to create intermediate CA certificate")
 		return
@@ -172,8 +170,7 @@ func generateIntermediateCACert(captenConfig config.CaptenConfig, rootKey *rsa.P
 		return
 	}
 
-	interKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(interKey)})
-	err = os.WriteFile(captenConfig.PrepareFilePath(captenConfig.CertDirPath, interCAKeyFileName), interKeyPEM, filePrmission)
+	err = writePrivateKey(captenConfig.PrepareFilePath(captenConfig.CertDirPath, interCAKeyFileName), interKey)
 	if err != nil {
 		err = errors.WithMessage(err, "error while writing from intermediate CA key")
 		return
@@ -181,11 +178,16 @@ func generateIntermediateCACert(captenConfig config.CaptenConfig, rootKey *rsa.P
 	return
 }
 
-func generateAgentCert(captenConfig config.CaptenConfig, interKey *rsa.PrivateKey,
+func generateAgentCert(captenConfig config.CaptenConfig, interKey crypto.Signer,
 	interCACertTemplate *x509.Certificate) (err error) {
-	agentKey, err := rsa.GenerateKey(rand.Reader, certBitSize)
+	agentKey, err := generateKey(captenConfig.CertKeyAlgorithm, captenConfig.CertKeySize, false)
+	if err != nil {
+		err = errors.WithMessage(err, "failed to generate key for agent certificate")
+		return
+	}
+
+	serialNumber, err := randomSerialNumber()
 	if err != nil {
-		err = errors.WithMessage(err, "failed to generate RSA key for agent certificate")
 		return
 	}
 
@@ -194,17 +196,17 @@ func generateAgentCert(captenConfig config.CaptenConfig, interKey *rsa.PrivateKe
 			Organization: []string{captenConfig.OrgName},
 			CommonName:   captenConfig.AgentCertCommonName,
 		},
-		SerialNumber:          big.NewInt(1),
+		SerialNumber:          serialNumber,
 		NotBefore:             time.Now(),
-		NotAfter:              time.Now().AddDate(1, 0, 0),
-		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		NotAfter:              time.Now().AddDate(0, 0, captenConfig.CertValidityDays),
+		KeyUsage:              leafKeyUsage(agentKey),
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		DNSNames:              captenConfig.AgentDNSNames,
 		BasicConstraintsValid: true,
 		IsCA:                  false,
 	}
 
-	agentCert, err := x509.CreateCertificate(rand.Reader, &agentCertTemplate, interCACertTemplate, &agentKey.PublicKey, interKey)
+	agentCert, err := x509.CreateCertificate(rand.Reader, &agentCertTemplate, interCACertTemplate, agentKey.Public(), interKey)
 	if err != nil {
 		err = errors.WithMessage(err, "failed to create server certificate")
 		return
@@ -217,8 +219,7 @@ func generateAgentCert(captenConfig config.CaptenConfig, interKey *rsa.PrivateKe
 		return
 	}
 
-	agentKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(agentKey)})
-	err = os.WriteFile(captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.AgentKeyFileName), agentKeyPEM, filePrmission)
+	err = writePrivateKey(captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.AgentKeyFileName), agentKey)
 	if err != nil {
 		err = errors.WithMessage(err, "error while writing from agent key to certs/server.key")
 		return
@@ -226,11 +227,16 @@ func generateAgentCert(captenConfig config.CaptenConfig, interKey *rsa.PrivateKe
 	return
 }
 
-func generateClientCert(captenConfig config.CaptenConfig, interKey *rsa.PrivateKey,
+func generateClientCert(captenConfig config.CaptenConfig, interKey crypto.Signer,
 	interCACertTemplate *x509.Certificate) (err error) {
-	clientKey, err := rsa.GenerateKey(rand.Reader, certBitSize)
+	clientKey, err := generateKey(captenConfig.CertKeyAlgorithm, captenConfig.CertKeySize, false)
+	if err != nil {
+		err = errors.WithMessage(err, "failed to generate key for capten client certificate")
+		return
+	}
+
+	serialNumber, err := randomSerialNumber()
 	if err != nil {
-		err = errors.WithMessage(err, "failed to generate RSA key for capten client certificate")
 		return
 	}
 
@@ -239,16 +245,16 @@ func generateClientCert(captenConfig config.CaptenConfig, interKey *rsa.PrivateK
 			Organization: []string{captenConfig.OrgName},
 			CommonName:   captenConfig.CaptenClientCertCommonName,
 		},
-		SerialNumber:          big.NewInt(1),
+		SerialNumber:          serialNumber,
 		NotBefore:             time.Now(),
-		NotAfter:              time.Now().AddDate(1, 0, 0),
-		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		NotAfter:              time.Now().AddDate(0, 0, captenConfig.CertValidityDays),
+		KeyUsage:              leafKeyUsage(clientKey),
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
 		BasicConstraintsValid: true,
 		IsCA:                  false,
 	}
 
-	clientCert, err := x509.CreateCertificate(rand.Reader, &clientCertTemplate, interCACertTemplate, &clientKey.PublicKey, interKey)
+	clientCert, err := x509.CreateCertificate(rand.Reader, &clientCertTemplate, interCACertTemplate, clientKey.Public(), interKey)
 	if err != nil {
 		return errors.WithMessage(err, "failed to create client certificate")
 	}
@@ -259,8 +265,7 @@ func generateClientCert(captenConfig config.CaptenConfig, interKey *rsa.PrivateK
 		return errors.WithMessage(err, "error while writing from client cert to certs/client.crt")
 	}
 
-	clientKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(clientKey)})
-	err = os.WriteFile(captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.ClientKeyFileName), clientKeyPEM, filePrmission)
+	err = writePrivateKey(captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.ClientKeyFileName), clientKey)
 	if err != nil {
 		return errors.WithMessage(err, "error while writing from client key to certs/client.key")
 	}
@@ -286,33 +291,10 @@ func generateCACertChain(captenConfig config.CaptenConfig) error {
 }
 
 func generateCaptenClientCertZip(captenConfig config.CaptenConfig) error {
-	zipFile, err := os.Create(captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.ClientCertExportFileName))
-	if err != nil {
-		return errors.WithMessage(err, "error while creating client cert export file")
-	}
-	defer zipFile.Close()
-
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
-
-	err = addFileToZip(zipWriter, captenConfig.ClientCertFileName,
-		captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.ClientCertFileName))
-	if err != nil {
-		return errors.WithMessage(err, "error while adding client cert file to zip")
-	}
-
-	err = addFileToZip(zipWriter, captenConfig.ClientKeyFileName,
+	return writeClientCertZip(captenConfig,
+		captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.ClientCertExportFileName),
+		captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.ClientCertFileName),
 		captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.ClientKeyFileName))
-	if err != nil {
-		return errors.WithMessage(err, "error while adding client key file to zip")
-	}
-
-	err = addFileToZip(zipWriter, captenConfig.CAFileName,
-		captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.CAFileName))
-	if err != nil {
-		return errors.WithMessage(err, "error while adding ca cert file to zip")
-	}
-	return nil
 }
 
 func addFileToZip(zipWriter *zip.Writer, fileName, filePath string) error {
@@ -343,4 +325,3 @@ func addFileToZip(zipWriter *zip.Writer, fileName, filePath string) error {
 	}
 	return nil
 }
-
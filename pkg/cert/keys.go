@@ -0,0 +1,154 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	KeyAlgorithmRSA     = "rsa"
+	KeyAlgorithmECDSA   = "ecdsa"
+	KeyAlgorithmEd25519 = "ed25519"
+
+	defaultRSACAKeySize   = 4096
+	defaultRSAKeySize     = 2048
+	defaultECDSACAKeySize = 384
+	defaultECDSAKeySize   = 256
+	minRSAKeySize         = 2048
+	serialNumberBits      = 128
+)
+
+// generateKey generates a private key of the key algorithm, a key size of 0 takes
+// the default size of the algorithm for CA or leaf certs. The key size of ECDSA is the curve size
+func generateKey(algorithm string, keySize int, ca bool) (crypto.Signer, error) {
+	switch algorithm {
+	case KeyAlgorithmRSA, "":
+		if keySize == 0 {
+			keySize = defaultRSAKeySize
+			if ca {
+				keySize = defaultRSACAKeySize
+			}
+		}
+		if keySize < minRSAKeySize {
+			return nil, fmt.Errorf("RSA key size %d is less than %d", keySize, minRSAKeySize)
+		}
+		return rsa.GenerateKey(rand.Reader, keySize)
+	case KeyAlgorithmECDSA:
+		if keySize == 0 {
+			keySize = defaultECDSAKeySize
+			if ca {
+				keySize = defaultECDSACAKeySize
+			}
+		}
+		var curve elliptic.Curve
+		switch keySize {
+		case 256:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("ECDSA key size %d is not supported, supported sizes: 256, 384, 521", keySize)
+		}
+		return ecdsa.GenerateKey(curve, rand.Reader)
+	case KeyAlgorithmEd25519:
+		if keySize != 0 {
+			return nil, fmt.Errorf("key size is not supported for Ed25519 keys")
+		}
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	default:
+		return nil, fmt.Errorf("key algorithm '%s' is not supported", algorithm)
+	}
+}
+
+func encodePrivateKey(key crypto.Signer) ([]byte, error) {
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), nil
+	case *ecdsa.PrivateKey:
+		data, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to marshal ECDSA key")
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: data}), nil
+	default:
+		data, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to marshal private key")
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: data}), nil
+	}
+}
+
+func writePrivateKey(keyFilePath string, key crypto.Signer) error {
+	keyPEM, err := encodePrivateKey(key)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(keyFilePath, keyPEM, filePrmission)
+}
+
+func loadPrivateKey(keyFilePath string) (crypto.Signer, error) {
+	data, err := os.ReadFile(keyFilePath)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to read key file %s", keyFilePath)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key file %s does not contain a PEM encoded private key", keyFilePath)
+	}
+
+	var key any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key file %s contains an unsupported %s block", keyFilePath, block.Type)
+	}
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to parse key file %s", keyFilePath)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("key file %s does not contain a signing key", keyFilePath)
+	}
+	return signer, nil
+}
+
+// leafKeyUsage leaves out key encipherment for the keys other than RSA, they can only sign
+func leafKeyUsage(key crypto.Signer) x509.KeyUsage {
+	if _, ok := key.(*rsa.PrivateKey); ok {
+		return x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	}
+	return x509.KeyUsageDigitalSignature
+}
+
+func randomSerialNumber() (*big.Int, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), serialNumberBits))
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to generate serial number")
+	}
+	return serialNumber, nil
+}
+
+func publicKeyEqual(key crypto.Signer, publicKey crypto.PublicKey) bool {
+	equaler, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && equaler.Equal(publicKey)
+}
@@ -0,0 +1,84 @@
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestGenerateCertsKeyAlgorithms(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		caKeySize int
+		keySize   int
+		wantKey   func(key any) bool
+		wantErr   bool
+	}{
+		{name: "RSA", algorithm: KeyAlgorithmRSA, caKeySize: 2048, keySize: 2048,
+			wantKey: func(key any) bool { k, ok := key.(*rsa.PublicKey); return ok && k.N.BitLen() == 2048 }},
+		{name: "ECDSA default sizes", algorithm: KeyAlgorithmECDSA,
+			wantKey: func(key any) bool { k, ok := key.(*ecdsa.PublicKey); return ok && k.Curve.Params().BitSize == 256 }},
+		{name: "ECDSA P-521", algorithm: KeyAlgorithmECDSA, caKeySize: 521, keySize: 384,
+			wantKey: func(key any) bool { k, ok := key.(*ecdsa.PublicKey); return ok && k.Curve.Params().BitSize == 384 }},
+		{name: "Ed25519", algorithm: KeyAlgorithmEd25519,
+			wantKey: func(key any) bool { _, ok := key.(ed25519.PublicKey); return ok }},
+		{name: "RSA key too small", algorithm: KeyAlgorithmRSA, keySize: 1024, wantErr: true},
+		{name: "ECDSA unsupported curve", algorithm: KeyAlgorithmECDSA, keySize: 224, wantErr: true},
+		{name: "Ed25519 with key size", algorithm: KeyAlgorithmEd25519, keySize: 256, wantErr: true},
+		{name: "Unsupported algorithm", algorithm: "dsa", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			captenConfig := testCertConfig(t)
+			captenConfig.CertKeyAlgorithm = tt.algorithm
+			captenConfig.CertCAKeySize = tt.caKeySize
+			captenConfig.CertKeySize = tt.keySize
+			captenConfig.CertValidityDays = 90
+
+			err := generateCerts(captenConfig)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("generateCerts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			verifyCert(t, captenConfig, captenConfig.AgentCertFileName, x509.ExtKeyUsageServerAuth)
+			verifyCert(t, captenConfig, captenConfig.ClientCertFileName, x509.ExtKeyUsageClientAuth)
+
+			agentCert, err := LoadCertificate(captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.AgentCertFileName))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.wantKey(agentCert.PublicKey) {
+				t.Errorf("agent cert public key = %T, want %s key", agentCert.PublicKey, tt.name)
+			}
+			if validity := agentCert.NotAfter.Sub(agentCert.NotBefore); validity != 90*24*time.Hour {
+				t.Errorf("agent cert validity = %v, want 90 days", validity)
+			}
+
+			rootCert, err := LoadCertificate(captenConfig.PrepareFilePath(captenConfig.CertDirPath, rootCACertFileName))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rootCert.SerialNumber.Cmp(agentCert.SerialNumber) == 0 {
+				t.Error("root and agent certs have the same serial number")
+			}
+
+			_, err = tls.LoadX509KeyPair(captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.AgentCertFileName),
+				captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.AgentKeyFileName))
+			if err != nil {
+				t.Errorf("agent cert and key do not load as a key pair, %v", err)
+			}
+
+			if err := RotateCerts(captenConfig, RotateOptions{Intermediate: true}); err != nil {
+				t.Errorf("RotateCerts() error = %v", err)
+			}
+			verifyCert(t, captenConfig, captenConfig.AgentCertFileName, x509.ExtKeyUsageServerAuth)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package cert
+
+import (
+	"capten/pkg/config"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	profileAPIVersion     = "capten/v1"
+	profileKind           = "CaptenProfile"
+	profileFileNameSuffix = "-capten-profile.yaml"
+)
+
+// Profile holds everything a capten client needs to reach the agent of a cluster,
+// the certs are PEM encoded so that the profile is a single self-contained file
+type Profile struct {
+	APIVersion         string `yaml:"apiVersion"`
+	Kind               string `yaml:"kind"`
+	Name               string `yaml:"name"`
+	DomainName         string `yaml:"domainName"`
+	LoadBalancerHost   string `yaml:"loadBalancerHost"`
+	AgentHostPort      string `yaml:"agentHostPort"`
+	AgentSecure        bool   `yaml:"agentSecure"`
+	AgentAuthority     string `yaml:"agentAuthority"`
+	VaultCredAuthority string `yaml:"vaultCredAuthority"`
+	CACert             string `yaml:"caCert"`
+	ClientCert         string `yaml:"clientCert"`
+	ClientKey          string `yaml:"clientKey"`
+}
+
+type ProfileExportOptions struct {
+	Name       string
+	ClientName string
+	FilePath   string
+}
+
+// ExportProfile writes the agent endpoint, the authority host names, the CA cert chain and a client cert
+// of the cluster to a profile file, the issued client cert of the given client name is exported instead
+// of the default client cert when set. The profile file path is returned
+func ExportProfile(captenConfig config.CaptenConfig, opts ProfileExportOptions) (string, error) {
+	if len(captenConfig.LoadBalancerHost) == 0 {
+		return "", fmt.Errorf("cluster load balancer host is not set in %s",
+			captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.CaptenHostValuesFileName))
+	}
+
+	certFilePath := captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.ClientCertFileName)
+	keyFilePath := captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.ClientKeyFileName)
+	if len(opts.ClientName) != 0 {
+		inventory, err := LoadClientCertInventory(captenConfig)
+		if err != nil {
+			return "", err
+		}
+		if _, found := inventory.active(opts.ClientName); !found {
+			return "", fmt.Errorf("client cert %s is not issued or is revoked", opts.ClientName)
+		}
+		certFilePath = clientCertFilePath(captenConfig, opts.ClientName, ".crt")
+		keyFilePath = clientCertFilePath(captenConfig, opts.ClientName, ".key")
+	}
+
+	name := opts.Name
+	if len(name) == 0 {
+		name = captenConfig.ContextName
+	}
+	if len(name) == 0 {
+		name = captenConfig.DomainName
+	}
+
+	profile := Profile{
+		APIVersion:         profileAPIVersion,
+		Kind:               profileKind,
+		Name:               name,
+		DomainName:         captenConfig.DomainName,
+		LoadBalancerHost:   captenConfig.LoadBalancerHost,
+		AgentHostPort:      captenConfig.AgentHostPort,
+		AgentSecure:        captenConfig.AgentSecure,
+		AgentAuthority:     captenConfig.AgentHostName + "." + captenConfig.DomainName,
+		VaultCredAuthority: captenConfig.VaultCredHostName + "." + captenConfig.DomainName,
+	}
+	for _, profileFile := range []struct {
+		value    *string
+		filePath string
+	}{
+		{&profile.CACert, captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.CAFileName)},
+		{&profile.ClientCert, certFilePath},
+		{&profile.ClientKey, keyFilePath},
+	} {
+		data, err := os.ReadFile(profileFile.filePath)
+		if err != nil {
+			return "", errors.WithMessagef(err, "failed to read %s", profileFile.filePath)
+		}
+		*profileFile.value = string(data)
+	}
+	if err := profile.validate(); err != nil {
+		return "", err
+	}
+
+	data, err := yaml.Marshal(profile)
+	if err != nil {
+		return "", errors.WithMessage(err, "failed to marshal profile")
+	}
+
+	filePath := opts.FilePath
+	if len(filePath) == 0 {
+		filePath = captenConfig.PrepareFilePath(captenConfig.CertDirPath, name+profileFileNameSuffix)
+		if len(opts.ClientName) != 0 {
+			filePath = clientCertFilePath(captenConfig, opts.ClientName, profileFileNameSuffix)
+		}
+	}
+	if err := os.WriteFile(filePath, data, 0600); err != nil {
+		return "", errors.WithMessagef(err, "failed to write profile to %s", filePath)
+	}
+	return filePath, nil
+}
+
+func LoadProfile(profileFilePath string) (Profile, error) {
+	profile := Profile{}
+	data, err := os.ReadFile(profileFilePath)
+	if err != nil {
+		return profile, errors.WithMessagef(err, "failed to read profile file %s", profileFilePath)
+	}
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return profile, errors.WithMessagef(err, "failed to unmarshal profile file %s", profileFilePath)
+	}
+	if profile.APIVersion != profileAPIVersion || profile.Kind != profileKind {
+		return profile, fmt.Errorf("%s is not a capten profile, expected apiVersion %s and kind %s",
+			profileFilePath, profileAPIVersion, profileKind)
+	}
+	return profile, profile.validate()
+}
+
+// ImportProfile adds a cluster context of the given name, or of the profile name when empty, with the
+// domain name, load balancer host and agent settings values files and the client cert files of the profile
+func ImportProfile(captenConfig config.CaptenConfig, profile Profile, contextName string) (config.ClusterContext, error) {
+	if len(contextName) == 0 {
+		contextName = profile.Name
+	}
+
+	contexts, err := config.LoadClusterContexts()
+	if err != nil {
+		return config.ClusterContext{}, err
+	}
+	clusterContext, err := contexts.Add(contextName, "")
+	if err != nil {
+		return config.ClusterContext{}, err
+	}
+
+	captenConfig.ContextDirPath = clusterContext.DirPath
+	if err := writeProfileFiles(captenConfig, profile); err != nil {
+		os.RemoveAll(clusterContext.DirPath)
+		return config.ClusterContext{}, err
+	}
+	if err := contexts.Save(); err != nil {
+		os.RemoveAll(clusterContext.DirPath)
+		return config.ClusterContext{}, err
+	}
+	return clusterContext, nil
+}
+
+func (p Profile) validate() error {
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"name", p.Name},
+		{"domainName", p.DomainName},
+		{"loadBalancerHost", p.LoadBalancerHost},
+		{"agentAuthority", p.AgentAuthority},
+		{"vaultCredAuthority", p.VaultCredAuthority},
+	} {
+		if len(field.value) == 0 {
+			return fmt.Errorf("profile %s is not set", field.name)
+		}
+	}
+	if !strings.HasSuffix(p.AgentAuthority, "."+p.DomainName) || !strings.HasSuffix(p.VaultCredAuthority, "."+p.DomainName) {
+		return fmt.Errorf("profile authority host names are not in the domain %s", p.DomainName)
+	}
+
+	keyPair, err := tls.X509KeyPair([]byte(p.ClientCert), []byte(p.ClientKey))
+	if err != nil {
+		return errors.WithMessage(err, "profile client cert and key do not match")
+	}
+	clientCert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return errors.WithMessage(err, "failed to parse profile client cert")
+	}
+	chain, err := parseCertificates([]byte(p.CACert), "profile CA cert")
+	if err != nil {
+		return err
+	}
+	if err := verifyChain(clientCert, chain, x509.ExtKeyUsageClientAuth); err != nil {
+		return errors.WithMessage(err, "profile client cert does not verify against the profile CA cert")
+	}
+	return nil
+}
+
+func writeProfileFiles(captenConfig config.CaptenConfig, profile Profile) error {
+	clusterValues, err := yaml.Marshal(config.CaptenClusterValues{DomainName: profile.DomainName})
+	if err != nil {
+		return errors.WithMessage(err, "failed to marshal cluster values")
+	}
+	hostValues, err := yaml.Marshal(config.CaptenClusterHost{LoadBalancerHost: profile.LoadBalancerHost})
+	if err != nil {
+		return errors.WithMessage(err, "failed to marshal host values")
+	}
+	agentValues, err := yaml.Marshal(config.CaptenAgentValues{
+		AgentHostName:     strings.TrimSuffix(profile.AgentAuthority, "."+profile.DomainName),
+		VaultCredHostName: strings.TrimSuffix(profile.VaultCredAuthority, "."+profile.DomainName),
+		AgentHostPort:     profile.AgentHostPort,
+		AgentSecure:       &profile.AgentSecure,
+	})
+	if err != nil {
+		return errors.WithMessage(err, "failed to marshal agent values")
+	}
+
+	for _, profileFile := range []struct {
+		filePath string
+		data     []byte
+		perm     os.FileMode
+	}{
+		{captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.CaptenGlobalValuesFileName), clusterValues, filePrmission},
+		{captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.CaptenHostValuesFileName), hostValues, filePrmission},
+		{captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.CaptenAgentValuesFileName), agentValues, filePrmission},
+		{captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.CAFileName), []byte(profile.CACert), filePrmission},
+		{captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.ClientCertFileName), []byte(profile.ClientCert), filePrmission},
+		{captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.ClientKeyFileName), []byte(profile.ClientKey), 0600},
+	} {
+		if err := os.WriteFile(profileFile.filePath, profileFile.data, profileFile.perm); err != nil {
+			return errors.WithMessagef(err, "failed to write %s", profileFile.filePath)
+		}
+	}
+	return nil
+}
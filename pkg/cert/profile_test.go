@@ -0,0 +1,150 @@
+package cert
+
+import (
+	"bytes"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"capten/pkg/config"
+)
+
+func testProfileConfig(t *testing.T) config.CaptenConfig {
+	captenConfig := testCertConfig(t)
+	captenConfig.ConfigDirPath = "/config/"
+	captenConfig.CaptenGlobalValuesFileName = "capten.yaml"
+	captenConfig.CaptenHostValuesFileName = "capten-lb-endpoint.yaml"
+	captenConfig.CaptenAgentValuesFileName = "capten-agent.yaml"
+	captenConfig.DomainName = "example.com"
+	captenConfig.LoadBalancerHost = "lb.example.com"
+	captenConfig.AgentHostName = "captenagent"
+	captenConfig.VaultCredHostName = "vault-cred"
+	captenConfig.AgentHostPort = ":443"
+	captenConfig.AgentSecure = true
+	return captenConfig
+}
+
+func TestExportImportProfile(t *testing.T) {
+	t.Setenv("CAPTEN_HOME", t.TempDir())
+	t.Setenv("CAPTEN_CONTEXT", "")
+
+	captenConfig := testProfileConfig(t)
+	if err := generateCerts(captenConfig); err != nil {
+		t.Fatalf("generateCerts() error = %v", err)
+	}
+	if _, err := IssueClientCert(captenConfig, "alice", 24*time.Hour); err != nil {
+		t.Fatalf("IssueClientCert() error = %v", err)
+	}
+
+	if _, err := ExportProfile(captenConfig, ProfileExportOptions{ClientName: "bob"}); err == nil {
+		t.Error("ExportProfile() of a not issued client cert, want error")
+	}
+	profileFilePath, err := ExportProfile(captenConfig, ProfileExportOptions{Name: "dev", ClientName: "alice"})
+	if err != nil {
+		t.Fatalf("ExportProfile() error = %v", err)
+	}
+	if want := clientCertFilePath(captenConfig, "alice", profileFileNameSuffix); profileFilePath != want {
+		t.Errorf("ExportProfile() path = %s, want %s", profileFilePath, want)
+	}
+
+	profile, err := LoadProfile(profileFilePath)
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if profile.Name != "dev" || profile.AgentHostPort != ":443" || !profile.AgentSecure ||
+		profile.AgentAuthority != "captenagent.example.com" || profile.VaultCredAuthority != "vault-cred.example.com" {
+		t.Errorf("LoadProfile() = %+v", profile)
+	}
+
+	importConfig := testProfileConfig(t)
+	clusterContext, err := ImportProfile(importConfig, profile, "")
+	if err != nil {
+		t.Fatalf("ImportProfile() error = %v", err)
+	}
+	if clusterContext.Name != "dev" {
+		t.Errorf("ImportProfile() context = %s, want dev", clusterContext.Name)
+	}
+	if _, err := ImportProfile(importConfig, profile, ""); err == nil {
+		t.Error("ImportProfile() of an existing context, want error")
+	}
+
+	importConfig.ContextDirPath = clusterContext.DirPath
+	if got := readCertFile(t, importConfig, importConfig.ClientCertFileName); !bytes.Equal(got,
+		readCertFile(t, captenConfig, "clients/alice.crt")) {
+		t.Error("ImportProfile() client cert differs from the issued client cert")
+	}
+	verifyCert(t, importConfig, importConfig.ClientCertFileName, x509.ExtKeyUsageClientAuth)
+
+	hostValues := config.CaptenClusterHost{}
+	if _, err := config.GetCaptenClusterValues(importConfig.PrepareFilePath(importConfig.ConfigDirPath,
+		importConfig.CaptenHostValuesFileName), &hostValues); err != nil || hostValues.LoadBalancerHost != "lb.example.com" {
+		t.Errorf("ImportProfile() host values = %+v, %v", hostValues, err)
+	}
+	clusterValues := config.CaptenClusterValues{}
+	if _, err := config.GetCaptenClusterValues(importConfig.PrepareFilePath(importConfig.ConfigDirPath,
+		importConfig.CaptenGlobalValuesFileName), &clusterValues); err != nil || clusterValues.DomainName != "example.com" {
+		t.Errorf("ImportProfile() cluster values = %+v, %v", clusterValues, err)
+	}
+	agentValues := config.CaptenAgentValues{}
+	if _, err := config.GetCaptenClusterValues(importConfig.PrepareFilePath(importConfig.ConfigDirPath,
+		importConfig.CaptenAgentValuesFileName), &agentValues); err != nil || agentValues.AgentHostName != "captenagent" ||
+		agentValues.VaultCredHostName != "vault-cred" || agentValues.AgentHostPort != ":443" ||
+		agentValues.AgentSecure == nil || !*agentValues.AgentSecure {
+		t.Errorf("ImportProfile() agent values = %+v, %v", agentValues, err)
+	}
+}
+
+func TestLoadProfileInvalid(t *testing.T) {
+	captenConfig := testProfileConfig(t)
+	if err := generateCerts(captenConfig); err != nil {
+		t.Fatalf("generateCerts() error = %v", err)
+	}
+	profileFilePath, err := ExportProfile(captenConfig, ProfileExportOptions{})
+	if err != nil {
+		t.Fatalf("ExportProfile() error = %v", err)
+	}
+	data, err := os.ReadFile(profileFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agentKey := string(readCertFile(t, captenConfig, captenConfig.AgentKeyFileName))
+	profile, err := LoadProfile(profileFilePath)
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		replace func(string) string
+	}{
+		{name: "Not a profile", replace: func(s string) string { return strings.Replace(s, profileKind, "Config", 1) }},
+		{name: "No load balancer host", replace: func(s string) string { return strings.Replace(s, "lb.example.com", `""`, 1) }},
+		{name: "Authority outside domain", replace: func(s string) string {
+			return strings.Replace(s, "captenagent.example.com", "captenagent.other.com", 1)
+		}},
+		{name: "Key mismatch", replace: func(s string) string {
+			return strings.Replace(s, indentPEM(profile.ClientKey), indentPEM(agentKey), 1)
+		}},
+		{name: "Unknown CA", replace: func(s string) string {
+			return strings.Replace(s, indentPEM(profile.CACert), indentPEM(string(readCertFile(t, captenConfig, captenConfig.AgentCertFileName))), 1)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalidFilePath := filepath.Join(t.TempDir(), "profile.yaml")
+			if err := os.WriteFile(invalidFilePath, []byte(tt.replace(string(data))), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadProfile(invalidFilePath); err == nil {
+				t.Error("LoadProfile() error = nil, want error")
+			}
+		})
+	}
+}
+
+func indentPEM(data string) string {
+	return strings.ReplaceAll(strings.TrimSpace(data), "\n", "\n  ")
+}
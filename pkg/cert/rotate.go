@@ -0,0 +1,95 @@
+package cert
+
+import (
+	"capten/pkg/config"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+)
+
+type RotateOptions struct {
+	Agent        bool
+	Client       bool
+	Intermediate bool
+}
+
+// RotateCerts reissues the selected certs with the existing CA certs, rotating the intermediate CA
+// reissues the agent and client certs as well since they are signed by it. The root CA is never rotated,
+// the cert source must hold the intermediate CA key
+func RotateCerts(captenConfig config.CaptenConfig, opts RotateOptions) error {
+	if !opts.Agent && !opts.Client && !opts.Intermediate {
+		return fmt.Errorf("no certs selected to rotate")
+	}
+	if err := checkSigningCA(captenConfig); err != nil {
+		return err
+	}
+
+	certFileNames := []string{interCACertFileName, interCAKeyFileName}
+	if opts.Intermediate {
+		if captenConfig.CertSource == CertSourceImport {
+			return fmt.Errorf("intermediate CA of the import cert source is rotated by importing a new CA cert and key")
+		}
+		certFileNames = append(certFileNames, rootCACertFileName, rootCAKeyFileName)
+	}
+	if !certFilesExist(captenConfig, certFileNames) {
+		return fmt.Errorf("cert files do not exist in %s, generate the certs before rotating them",
+			captenConfig.PrepareDirPath(captenConfig.CertDirPath))
+	}
+
+	var interKey crypto.Signer
+	var interCACert *x509.Certificate
+	var err error
+	if opts.Intermediate {
+		rootKey, rootCert, err := loadCAKeyPair(captenConfig, rootCACertFileName, rootCAKeyFileName)
+		if err != nil {
+			return err
+		}
+		interKey, interCACert, err = generateIntermediateCACert(captenConfig, rootKey, rootCert)
+		if err != nil {
+			return err
+		}
+		opts.Agent, opts.Client = true, true
+	} else {
+		interKey, interCACert, err = loadCAKeyPair(captenConfig, interCACertFileName, interCAKeyFileName)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.Agent {
+		if err := generateAgentCert(captenConfig, interKey, interCACert); err != nil {
+			return err
+		}
+	}
+	if opts.Client {
+		if err := generateClientCert(captenConfig, interKey, interCACert); err != nil {
+			return err
+		}
+	}
+	if opts.Intermediate {
+		if err := generateCACertChain(captenConfig); err != nil {
+			return err
+		}
+		if err := refreshCRL(captenConfig); err != nil {
+			return err
+		}
+	}
+	if opts.Client {
+		if err := generateCaptenClientCertZip(captenConfig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadCAKeyPair(captenConfig config.CaptenConfig, certFileName, keyFileName string) (crypto.Signer, *x509.Certificate, error) {
+	caCert, err := LoadCertificate(captenConfig.PrepareFilePath(captenConfig.CertDirPath, certFileName))
+	if err != nil {
+		return nil, nil, err
+	}
+	caKey, err := loadPrivateKey(captenConfig.PrepareFilePath(captenConfig.CertDirPath, keyFileName))
+	if err != nil {
+		return nil, nil, err
+	}
+	return caKey, caCert, nil
+}
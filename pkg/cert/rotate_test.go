@@ -0,0 +1,163 @@
+package cert
+
+import (
+	"bytes"
+	"capten/pkg/config"
+	"crypto/x509"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testCertConfig(t *testing.T) config.CaptenConfig {
+	return config.CaptenConfig{
+		CurrentDirPath:             t.TempDir(),
+		CertDirPath:                "/cert/",
+		AgentCertFileName:          "agent.crt",
+		AgentKeyFileName:           "agent.key",
+		ClientCertFileName:         "client.crt",
+		ClientKeyFileName:          "client.key",
+		CAFileName:                 "ca.crt",
+		CRLFileName:                "ca.crl",
+		ClientCertExportFileName:   "capten-client-auth-certs.zip",
+		OrgName:                    "Intelops",
+		RootCACommonName:           "Capten Root CA",
+		IntermediateCACommonName:   "Capten Cluster CA",
+		AgentCertCommonName:        "Capten Agent",
+		CaptenClientCertCommonName: "Capten Client",
+		AgentDNSNames:              []string{"captenagent.example.com"},
+		CertKeyAlgorithm:           KeyAlgorithmRSA,
+		CertCAKeySize:              2048,
+		RootCAValidityDays:         1825,
+		IntermediateCAValidityDays: 730,
+		CertValidityDays:           365,
+	}
+}
+
+func readCertFile(t *testing.T, captenConfig config.CaptenConfig, fileName string) []byte {
+	data, err := os.ReadFile(captenConfig.PrepareFilePath(captenConfig.CertDirPath, fileName))
+	if err != nil {
+		t.Fatalf("failed to read %s, %v", fileName, err)
+	}
+	return data
+}
+
+func verifyCert(t *testing.T, captenConfig config.CaptenConfig, fileName string, usage x509.ExtKeyUsage) {
+	_, chain, err := loadCertificates(captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.CAFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := LoadCertificate(captenConfig.PrepareFilePath(captenConfig.CertDirPath, fileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyChain(certificate, chain, usage); err != nil {
+		t.Errorf("%s does not verify against the CA cert chain, %v", fileName, err)
+	}
+}
+
+func TestRotateCerts(t *testing.T) {
+	captenConfig := testCertConfig(t)
+	if err := RotateCerts(captenConfig, RotateOptions{Agent: true}); err == nil {
+		t.Fatal("RotateCerts() without generated certs, want error")
+	}
+	if err := generateCerts(captenConfig); err != nil {
+		t.Fatalf("generateCerts() error = %v", err)
+	}
+	if err := RotateCerts(captenConfig, RotateOptions{}); err == nil {
+		t.Error("RotateCerts() without selected certs, want error")
+	}
+
+	rootCert := readCertFile(t, captenConfig, rootCACertFileName)
+	interCert := readCertFile(t, captenConfig, interCACertFileName)
+	agentCert := readCertFile(t, captenConfig, captenConfig.AgentCertFileName)
+	clientCert := readCertFile(t, captenConfig, captenConfig.ClientCertFileName)
+
+	if err := RotateCerts(captenConfig, RotateOptions{Agent: true}); err != nil {
+		t.Fatalf("RotateCerts() agent error = %v", err)
+	}
+	if !bytes.Equal(interCert, readCertFile(t, captenConfig, interCACertFileName)) {
+		t.Error("RotateCerts() agent changed the intermediate CA cert")
+	}
+	if !bytes.Equal(clientCert, readCertFile(t, captenConfig, captenConfig.ClientCertFileName)) {
+		t.Error("RotateCerts() agent changed the client cert")
+	}
+	if bytes.Equal(agentCert, readCertFile(t, captenConfig, captenConfig.AgentCertFileName)) {
+		t.Error("RotateCerts() agent did not reissue the agent cert")
+	}
+	verifyCert(t, captenConfig, captenConfig.AgentCertFileName, x509.ExtKeyUsageServerAuth)
+
+	if err := RotateCerts(captenConfig, RotateOptions{Intermediate: true}); err != nil {
+		t.Fatalf("RotateCerts() intermediate error = %v", err)
+	}
+	if !bytes.Equal(rootCert, readCertFile(t, captenConfig, rootCACertFileName)) {
+		t.Error("RotateCerts() intermediate changed the root CA cert")
+	}
+	newInterCert := readCertFile(t, captenConfig, interCACertFileName)
+	if bytes.Equal(interCert, newInterCert) {
+		t.Error("RotateCerts() intermediate did not reissue the intermediate CA cert")
+	}
+	if !bytes.Equal(append(rootCert, newInterCert...), readCertFile(t, captenConfig, captenConfig.CAFileName)) {
+		t.Error("RotateCerts() intermediate did not rebuild the CA cert chain")
+	}
+	verifyCert(t, captenConfig, captenConfig.AgentCertFileName, x509.ExtKeyUsageServerAuth)
+	verifyCert(t, captenConfig, captenConfig.ClientCertFileName, x509.ExtKeyUsageClientAuth)
+}
+
+func TestCertStatus(t *testing.T) {
+	captenConfig := testCertConfig(t)
+	if err := generateCerts(captenConfig); err != nil {
+		t.Fatalf("generateCerts() error = %v", err)
+	}
+	agentCertPath := captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.AgentCertFileName)
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		certFilePath  string
+		warningWindow time.Duration
+		now           time.Time
+		wantStatus    string
+	}{
+		{name: "Valid", certFilePath: agentCertPath, warningWindow: 30 * 24 * time.Hour, now: now, wantStatus: CertValid},
+		{name: "Expiring", certFilePath: agentCertPath, warningWindow: 400 * 24 * time.Hour, now: now, wantStatus: CertExpiring},
+		{name: "Expired", certFilePath: agentCertPath, now: now.AddDate(2, 0, 0), wantStatus: CertExpired},
+		{name: "Missing", certFilePath: agentCertPath + ".missing", now: now, wantStatus: CertMissing},
+		{name: "Invalid", certFilePath: captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.AgentKeyFileName), now: now, wantStatus: CertInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := certStatus("agent", tt.certFilePath, tt.warningWindow, tt.now); got.Status != tt.wantStatus {
+				t.Errorf("certStatus() = %+v, want status %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCertsStatus(t *testing.T) {
+	captenConfig := testCertConfig(t)
+	if err := generateCerts(captenConfig); err != nil {
+		t.Fatalf("generateCerts() error = %v", err)
+	}
+
+	tests := []struct {
+		certSource string
+		wantNames  []string
+	}{
+		{CertSourceSelfSigned, []string{"root-ca", "intermediate-ca", "agent", "client"}},
+		{CertSourceCertManager, []string{"root-ca", "intermediate-ca", "client"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.certSource, func(t *testing.T) {
+			captenConfig.CertSource = tt.certSource
+			names := []string{}
+			for _, status := range CertsStatus(captenConfig, 30*24*time.Hour) {
+				names = append(names, status.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("CertsStatus() certs = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
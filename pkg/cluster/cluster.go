@@ -3,12 +3,73 @@ package cluster
 import (
 	"capten/pkg/cluster/k3s"
 	"capten/pkg/config"
+	"capten/pkg/types"
+	"fmt"
 )
 
+func init() {
+	Register("aws", "talos", k3s.NewAWSProvisioner())
+	Register("azure", "talos", k3s.NewAzureProvisioner())
+}
+
 func Create(captenConfig config.CaptenConfig) error {
-	return k3s.Create(captenConfig)
+	provisioner, err := GetProvisioner(captenConfig.CloudService, captenConfig.ClusterType)
+	if err != nil {
+		return err
+	}
+	return provisioner.Create(captenConfig)
 }
 
 func Destroy(captenConfig config.CaptenConfig) error {
-	return k3s.Destroy(captenConfig)
+	provisioner, err := GetProvisioner(captenConfig.CloudService, captenConfig.ClusterType)
+	if err != nil {
+		return err
+	}
+	return provisioner.Destroy(captenConfig)
+}
+
+func Status(captenConfig config.CaptenConfig) (string, error) {
+	provisioner, err := GetProvisioner(captenConfig.CloudService, captenConfig.ClusterType)
+	if err != nil {
+		return "", err
+	}
+	return provisioner.Status(captenConfig)
+}
+
+func Outputs(captenConfig config.CaptenConfig) (map[string]string, error) {
+	provisioner, err := GetProvisioner(captenConfig.CloudService, captenConfig.ClusterType)
+	if err != nil {
+		return nil, err
+	}
+	return provisioner.Outputs(captenConfig)
+}
+
+func Plan(captenConfig config.CaptenConfig, planFile string) (*types.ClusterPlanSummary, error) {
+	planner, err := getPlanner(captenConfig)
+	if err != nil {
+		return nil, err
+	}
+	return planner.Plan(captenConfig, planFile)
+}
+
+func ApplyPlan(captenConfig config.CaptenConfig, planFile string) error {
+	planner, err := getPlanner(captenConfig)
+	if err != nil {
+		return err
+	}
+	return planner.ApplyPlan(captenConfig, planFile)
+}
+
+func getPlanner(captenConfig config.CaptenConfig) (Planner, error) {
+	provisioner, err := GetProvisioner(captenConfig.CloudService, captenConfig.ClusterType)
+	if err != nil {
+		return nil, err
+	}
+
+	planner, ok := provisioner.(Planner)
+	if !ok {
+		return nil, fmt.Errorf("cluster type '%s' on cloud service '%s' does not support plans",
+			captenConfig.ClusterType, captenConfig.CloudService)
+	}
+	return planner, nil
 }
@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"capten/pkg/config"
+	"reflect"
 	"testing"
 )
 
@@ -77,3 +78,60 @@ func TestDestroy(t *testing.T) {
 		})
 	}
 }
+
+func TestGetProvisioner(t *testing.T) {
+	type args struct {
+		cloudService string
+		clusterType  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name:    "Registered aws talos provisioner",
+			args:    args{cloudService: "aws", clusterType: "talos"},
+			wantErr: false,
+		},
+		{
+			name:    "Registered azure talos provisioner",
+			args:    args{cloudService: "azure", clusterType: "talos"},
+			wantErr: false,
+		},
+		{
+			name:    "Unsupported cloud service",
+			args:    args{cloudService: "gcp", clusterType: "talos"},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported cluster type",
+			args:    args{cloudService: "aws", clusterType: "kind"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetProvisioner(tt.args.cloudService, tt.args.clusterType)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetProvisioner() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got == nil {
+				t.Errorf("GetProvisioner() returned nil provisioner")
+			}
+		})
+	}
+}
+
+func TestSupportedClusterTypes(t *testing.T) {
+	if got := SupportedCloudServices(); !reflect.DeepEqual(got, []string{"aws", "azure"}) {
+		t.Errorf("SupportedCloudServices() = %v", got)
+	}
+	if got := SupportedClusterTypes("aws"); !reflect.DeepEqual(got, []string{"talos"}) {
+		t.Errorf("SupportedClusterTypes() = %v", got)
+	}
+	if got := SupportedClusterTypes("gcp"); len(got) != 0 {
+		t.Errorf("SupportedClusterTypes() = %v, want empty", got)
+	}
+}
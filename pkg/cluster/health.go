@@ -0,0 +1,172 @@
+package cluster
+
+import (
+	"capten/pkg/agent"
+	"capten/pkg/app"
+	"capten/pkg/cert"
+	"capten/pkg/config"
+	"capten/pkg/k8s"
+	"capten/pkg/types"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const (
+	CheckOK      = "OK"
+	CheckWarning = "Warning"
+	CheckFailed  = "Failed"
+
+	HealthHealthy  = "Healthy"
+	HealthDegraded = "Degraded"
+
+	nodeCheckTimeout = 30 * time.Second
+)
+
+type HealthCheck struct {
+	Name    string `json:"name" yaml:"name"`
+	Status  string `json:"status" yaml:"status"`
+	Message string `json:"message" yaml:"message"`
+}
+
+type HealthReport struct {
+	Status string        `json:"status" yaml:"status"`
+	Checks []HealthCheck `json:"checks" yaml:"checks"`
+}
+
+func (r HealthReport) Table(wide bool) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, check := range r.Checks {
+		rows = append(rows, []string{check.Name, check.Status, check.Message})
+	}
+	return []string{"Check", "Status", "Message"}, rows
+}
+
+func (r HealthReport) Count(status string) int {
+	count := 0
+	for _, check := range r.Checks {
+		if check.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+func (r HealthReport) Failed() bool {
+	return r.Count(CheckFailed) != 0
+}
+
+func (r HealthReport) Summary() string {
+	if r.Status == HealthHealthy {
+		return fmt.Sprintf("Cluster is %s, %d checks passed", strings.ToLower(r.Status), len(r.Checks))
+	}
+	return fmt.Sprintf("Cluster is %s, %d failed, %d warnings", strings.ToLower(r.Status), r.Count(CheckFailed), r.Count(CheckWarning))
+}
+
+// Health checks the agent, vault-cred, the cluster nodes, the core apps and the certs reported by cert status,
+// every check is run even when a previous one failed so that the report covers the whole cluster
+func Health(captenConfig config.CaptenConfig, certWarningWindow time.Duration) HealthReport {
+	checks := []HealthCheck{
+		newCheck("agent", agent.Ping(captenConfig), "agent is reachable"),
+		vaultCredCheck(agent.CheckVaultCred(captenConfig)),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), nodeCheckTimeout)
+	defer cancel()
+	checks = append(checks, nodesCheck(k8s.GetNotReadyNodes(ctx,
+		captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.KubeConfigFileName))))
+
+	appStatuses, err := app.GetAppsReleaseStatus(captenConfig, captenConfig.CoreAppGroupsFileName)
+	if err != nil {
+		checks = append(checks, newCheck("core-apps", err, ""))
+	}
+	for _, appStatus := range appStatuses {
+		checks = append(checks, appCheck(appStatus))
+	}
+
+	for _, certStatus := range cert.CertsStatus(captenConfig, certWarningWindow) {
+		checks = append(checks, certCheck(certStatus))
+	}
+	return newHealthReport(checks)
+}
+
+func newHealthReport(checks []HealthCheck) HealthReport {
+	report := HealthReport{Status: HealthHealthy, Checks: checks}
+	for _, check := range checks {
+		if check.Status != CheckOK {
+			report.Status = HealthDegraded
+		}
+	}
+	return report
+}
+
+func newCheck(name string, err error, okMessage string) HealthCheck {
+	if err != nil {
+		return HealthCheck{Name: name, Status: CheckFailed, Message: err.Error()}
+	}
+	return HealthCheck{Name: name, Status: CheckOK, Message: okMessage}
+}
+
+func vaultCredCheck(reachable bool, err error) HealthCheck {
+	switch {
+	case !reachable:
+		return newCheck("vault-cred", err, "")
+	case err != nil:
+		return HealthCheck{Name: "vault-cred", Status: CheckWarning, Message: fmt.Sprintf("vault-cred is reachable, credential lookup failed, %v", err)}
+	default:
+		return newCheck("vault-cred", nil, "vault-cred is reachable")
+	}
+}
+
+func nodesCheck(nodeCount int, notReadyNodes []string, err error) HealthCheck {
+	switch {
+	case err != nil:
+		return newCheck("nodes", err, "")
+	case nodeCount == 0:
+		return HealthCheck{Name: "nodes", Status: CheckFailed, Message: "no nodes found"}
+	case len(notReadyNodes) == nodeCount:
+		return HealthCheck{Name: "nodes", Status: CheckFailed, Message: fmt.Sprintf("0/%d nodes ready", nodeCount)}
+	case len(notReadyNodes) != 0:
+		return HealthCheck{Name: "nodes", Status: CheckWarning, Message: fmt.Sprintf("%d/%d nodes ready, not ready: %s",
+			nodeCount-len(notReadyNodes), nodeCount, strings.Join(notReadyNodes, ", "))}
+	default:
+		return HealthCheck{Name: "nodes", Status: CheckOK, Message: fmt.Sprintf("%d/%d nodes ready", nodeCount, nodeCount)}
+	}
+}
+
+func appCheck(appStatus types.AppReleaseStatus) HealthCheck {
+	check := HealthCheck{Name: "app/" + appStatus.Name, Message: appStatus.Status}
+	if len(appStatus.ChartVersion) != 0 {
+		check.Message = fmt.Sprintf("%s, version %s", appStatus.Status, appStatus.ChartVersion)
+	}
+
+	switch release.Status(appStatus.Status) {
+	case release.StatusDeployed:
+		check.Status = CheckOK
+	case release.StatusPendingInstall, release.StatusPendingUpgrade, release.StatusPendingRollback:
+		check.Status = CheckWarning
+	default:
+		check.Status = CheckFailed
+	}
+	if len(appStatus.Error) != 0 {
+		check.Message = appStatus.Error
+	}
+	return check
+}
+
+func certCheck(certStatus cert.CertStatus) HealthCheck {
+	name := "cert/" + certStatus.Name
+	switch certStatus.Status {
+	case cert.CertValid:
+		return HealthCheck{Name: name, Status: CheckOK, Message: "expires on " + certStatus.NotAfter}
+	case cert.CertExpiring:
+		return HealthCheck{Name: name, Status: CheckWarning, Message: "expires on " + certStatus.NotAfter}
+	case cert.CertExpired:
+		return HealthCheck{Name: name, Status: CheckFailed, Message: "expired on " + certStatus.NotAfter}
+	default:
+		return HealthCheck{Name: name, Status: CheckFailed, Message: certStatus.Error}
+	}
+}
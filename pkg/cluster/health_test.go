@@ -0,0 +1,94 @@
+package cluster
+
+import (
+	"errors"
+	"testing"
+
+	"capten/pkg/cert"
+	"capten/pkg/types"
+)
+
+func TestNodesCheck(t *testing.T) {
+	tests := []struct {
+		name          string
+		nodeCount     int
+		notReadyNodes []string
+		err           error
+		wantStatus    string
+	}{
+		{name: "All ready", nodeCount: 3, notReadyNodes: []string{}, wantStatus: CheckOK},
+		{name: "Some not ready", nodeCount: 3, notReadyNodes: []string{"worker-1"}, wantStatus: CheckWarning},
+		{name: "None ready", nodeCount: 2, notReadyNodes: []string{"master-1", "worker-1"}, wantStatus: CheckFailed},
+		{name: "No nodes", nodeCount: 0, wantStatus: CheckFailed},
+		{name: "Kubeconfig error", err: errors.New("no kubeconfig"), wantStatus: CheckFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nodesCheck(tt.nodeCount, tt.notReadyNodes, tt.err); got.Status != tt.wantStatus {
+				t.Errorf("nodesCheck() = %+v, want status %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAppCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		appStatus  types.AppReleaseStatus
+		wantStatus string
+	}{
+		{name: "Deployed", appStatus: types.AppReleaseStatus{Name: "vault", Status: "deployed", ChartVersion: "0.1.0"}, wantStatus: CheckOK},
+		{name: "Pending upgrade", appStatus: types.AppReleaseStatus{Name: "vault", Status: "pending-upgrade"}, wantStatus: CheckWarning},
+		{name: "Failed", appStatus: types.AppReleaseStatus{Name: "vault", Status: "failed"}, wantStatus: CheckFailed},
+		{name: "Not installed", appStatus: types.AppReleaseStatus{Name: "vault", Status: "not-installed"}, wantStatus: CheckFailed},
+		{name: "Unknown", appStatus: types.AppReleaseStatus{Name: "vault", Status: "unknown", Error: "no kubeconfig"}, wantStatus: CheckFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := appCheck(tt.appStatus)
+			if got.Status != tt.wantStatus || got.Name != "app/vault" {
+				t.Errorf("appCheck() = %+v, want status %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCertCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		certStatus cert.CertStatus
+		wantStatus string
+	}{
+		{name: "Valid", certStatus: cert.CertStatus{Name: "agent", Status: cert.CertValid}, wantStatus: CheckOK},
+		{name: "Expiring", certStatus: cert.CertStatus{Name: "agent", Status: cert.CertExpiring}, wantStatus: CheckWarning},
+		{name: "Expired", certStatus: cert.CertStatus{Name: "agent", Status: cert.CertExpired}, wantStatus: CheckFailed},
+		{name: "Missing file", certStatus: cert.CertStatus{Name: "agent", Status: cert.CertMissing, Error: "no such file"}, wantStatus: CheckFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := certCheck(tt.certStatus); got.Status != tt.wantStatus || got.Name != "cert/agent" {
+				t.Errorf("certCheck() = %+v, want status %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNewHealthReport(t *testing.T) {
+	healthy := newHealthReport([]HealthCheck{{Name: "agent", Status: CheckOK}, {Name: "nodes", Status: CheckOK}})
+	if healthy.Status != HealthHealthy || healthy.Failed() {
+		t.Errorf("newHealthReport() = %+v, want healthy", healthy)
+	}
+
+	warning := newHealthReport([]HealthCheck{{Name: "agent", Status: CheckOK}, {Name: "nodes", Status: CheckWarning}})
+	if warning.Status != HealthDegraded || warning.Failed() {
+		t.Errorf("newHealthReport() = %+v, want degraded without failures", warning)
+	}
+
+	failed := newHealthReport([]HealthCheck{{Name: "agent", Status: CheckFailed}, {Name: "nodes", Status: CheckWarning}})
+	if failed.Status != HealthDegraded || !failed.Failed() {
+		t.Errorf("newHealthReport() = %+v, want degraded with failures", failed)
+	}
+	if got, want := failed.Summary(), "Cluster is degraded, 1 failed, 1 warnings"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
@@ -12,87 +12,126 @@ import (
 	"github.com/pkg/errors"
 )
 
-func getClusterInfo(captenConfig config.CaptenConfig) (interface{}, error) {
-	var clusterInfo interface{}
+type terraformExecutor interface {
+	Apply() error
+	Destroy() error
+	Status() (string, error)
+	Outputs() (map[string]string, error)
+	Plan(planFile string) (*types.ClusterPlanSummary, error)
+	ApplyPlan(planFile string) error
+}
 
-	if captenConfig.CloudService == "aws" {
-		awsclusterInfo, err := config.GetClusterInfo(captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.CloudService+"_config.yaml"))
-		if err != nil {
-			return nil, err
-		}
-		clusterInfo = awsclusterInfo
+type prepareTerraformFunc func(captenConfig config.CaptenConfig, generateVarFile bool) (terraformExecutor, error)
 
-	} else if captenConfig.CloudService == "azure" {
-		azureClusterInfo, err := config.GetClusterInfoAzure(captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.CloudService+"_config.yaml"))
-		if err != nil {
-			return nil, err
-		}
-		clusterInfo = azureClusterInfo
-	} else {
-		return nil, errors.Errorf("Unsupported Cloud Service")
+type provisioner struct {
+	prepareTerraform prepareTerraformFunc
+}
+
+func NewAWSProvisioner() *provisioner {
+	return &provisioner{prepareTerraform: prepareAWSTerraform}
+}
+
+func NewAzureProvisioner() *provisioner {
+	return &provisioner{prepareTerraform: prepareAzureTerraform}
+}
+
+func (p *provisioner) Create(captenConfig config.CaptenConfig) error {
+	clog.Logger.Debugf("create cluster on %s cloud with %s cluster type", captenConfig.CloudService, captenConfig.ClusterType)
+	tf, err := p.prepareTerraform(captenConfig, true)
+	defer removeTemplateVarFile(captenConfig)
+	if err != nil {
+		return err
 	}
+	return tf.Apply()
+}
 
-	return clusterInfo, nil
+func (p *provisioner) Destroy(captenConfig config.CaptenConfig) error {
+	clog.Logger.Debugf("destroy cluster on %s cloud with %s cluster type", captenConfig.CloudService, captenConfig.ClusterType)
+	tf, err := p.prepareTerraform(captenConfig, true)
+	defer removeTemplateVarFile(captenConfig)
+	if err != nil {
+		return err
+	}
+	return tf.Destroy()
 }
 
-func createOrDestroyCluster(captenConfig config.CaptenConfig, action string) error {
-	clog.Logger.Debugf("%s cluster on %s cloud with %s cluster type", action, captenConfig.CloudService, captenConfig.ClusterType)
+func (p *provisioner) Status(captenConfig config.CaptenConfig) (string, error) {
+	tf, err := p.prepareTerraform(captenConfig, false)
+	if err != nil {
+		return "", err
+	}
+	return tf.Status()
+}
 
-	clusterInfo, err := getClusterInfo(captenConfig)
+func (p *provisioner) Outputs(captenConfig config.CaptenConfig) (map[string]string, error) {
+	tf, err := p.prepareTerraform(captenConfig, false)
+	if err != nil {
+		return nil, err
+	}
+	return tf.Outputs()
+}
+
+func (p *provisioner) Plan(captenConfig config.CaptenConfig, planFile string) (*types.ClusterPlanSummary, error) {
+	clog.Logger.Debugf("plan cluster on %s cloud with %s cluster type", captenConfig.CloudService, captenConfig.ClusterType)
+	tf, err := p.prepareTerraform(captenConfig, true)
+	defer removeTemplateVarFile(captenConfig)
+	if err != nil {
+		return nil, err
+	}
+	return tf.Plan(planFile)
+}
+
+func (p *provisioner) ApplyPlan(captenConfig config.CaptenConfig, planFile string) error {
+	clog.Logger.Debugf("apply cluster plan %s on %s cloud with %s cluster type", planFile, captenConfig.CloudService, captenConfig.ClusterType)
+	tf, err := p.prepareTerraform(captenConfig, false)
 	if err != nil {
 		return err
 	}
+	return tf.ApplyPlan(planFile)
+}
+
+func prepareAWSTerraform(captenConfig config.CaptenConfig, generateVarFile bool) (terraformExecutor, error) {
+	info, err := config.GetClusterInfo(captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.CloudService+"_config.yaml"))
+	if err != nil {
+		return nil, err
+	}
 
-	switch info := clusterInfo.(type) {
-	case types.AWSClusterInfo:
-		info.ConfigFolderPath = captenConfig.PrepareDirPath(captenConfig.ConfigDirPath)
-		info.TerraformModulesDirPath = captenConfig.PrepareDirPath(captenConfig.TerraformModulesDirPath)
+	info.ConfigFolderPath = captenConfig.PrepareDirPath(captenConfig.ConfigDirPath)
+	info.TerraformModulesDirPath = captenConfig.PrepareDirPath(captenConfig.TerraformModulesDirPath)
+	if generateVarFile {
 		err = generateTemplateVarFile(captenConfig, info, captenConfig.AWSTerraformTemplateFileName)
 		if err != nil {
-			return err
+			return nil, err
 		}
+	}
 
-		tf, err := terraform.NewAws(captenConfig, info)
-		if err != nil {
-			return errors.WithMessage(err, "failed to initialize the terraform")
-		}
+	tf, err := terraform.NewAws(captenConfig, info)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to initialize the terraform")
+	}
+	return tf, nil
+}
 
-		if action == "create" {
-			return tf.Apply()
-		} else if action == "destroy" {
-			return tf.Destroy()
-		}
-	case types.AzureClusterInfo:
-		info.ConfigFolderPath = captenConfig.PrepareDirPath(captenConfig.ConfigDirPath)
-		info.TerraformModulesDirPath = captenConfig.PrepareDirPath(captenConfig.TerraformModulesDirPath)
-		err = generateTemplateVarFile(captenConfig, info, captenConfig.AzureTerraformTemplateFileName)
-		if err != nil {
-			return err
-		}
+func prepareAzureTerraform(captenConfig config.CaptenConfig, generateVarFile bool) (terraformExecutor, error) {
+	info, err := config.GetClusterInfoAzure(captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.CloudService+"_config.yaml"))
+	if err != nil {
+		return nil, err
+	}
 
-		tf, err := terraform.NewAzure(captenConfig, info)
+	info.ConfigFolderPath = captenConfig.PrepareDirPath(captenConfig.ConfigDirPath)
+	info.TerraformModulesDirPath = captenConfig.PrepareDirPath(captenConfig.TerraformModulesDirPath)
+	if generateVarFile {
+		err = generateTemplateVarFile(captenConfig, info, captenConfig.AzureTerraformTemplateFileName)
 		if err != nil {
-			return errors.WithMessage(err, "failed to initialize the terraform")
-		}
-
-		if action == "create" {
-			return tf.Apply()
-		} else if action == "destroy" {
-			return tf.Destroy()
+			return nil, err
 		}
-	default:
-		return errors.New("unsupported cloud service")
 	}
 
-	return nil
-}
-
-func Create(captenConfig config.CaptenConfig) error {
-	return createOrDestroyCluster(captenConfig, "create")
-}
-
-func Destroy(captenConfig config.CaptenConfig) error {
-	return createOrDestroyCluster(captenConfig, "destroy")
+	tf, err := terraform.NewAzure(captenConfig, info)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to initialize the terraform")
+	}
+	return tf, nil
 }
 
 func generateTemplateVarFile(captenConfig config.CaptenConfig, clusterInfo interface{}, templateFileName string) error {
@@ -109,12 +148,14 @@ func generateTemplateVarFile(captenConfig config.CaptenConfig, clusterInfo inter
 		return err
 	}
 
-	templateFile, err := os.Create(captenConfig.PrepareFilePath(captenConfig.TerraformTemplateDirPath, captenConfig.TerraformVarFileName))
-
+	// the var file holds the resolved cloud credentials, keep it readable only by the user
+	templateFile, err := os.OpenFile(captenConfig.PrepareFilePath(captenConfig.TerraformTemplateDirPath, captenConfig.TerraformVarFileName),
+		os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
 	if err != nil {
 		clog.Logger.Error("Error while creating templateFile", err)
 		return err
 	}
+	defer templateFile.Close()
 
 	if err := templateObj.Execute(templateFile, clusterInfo); err != nil {
 		clog.Logger.Error("Error while executing templateObj", err)
@@ -122,3 +163,10 @@ func generateTemplateVarFile(captenConfig config.CaptenConfig, clusterInfo inter
 	}
 	return nil
 }
+
+func removeTemplateVarFile(captenConfig config.CaptenConfig) {
+	varFilePath := captenConfig.PrepareFilePath(captenConfig.TerraformTemplateDirPath, captenConfig.TerraformVarFileName)
+	if err := os.Remove(varFilePath); err != nil && !os.IsNotExist(err) {
+		clog.Logger.Errorf("failed to remove terraform var file %s, %v", varFilePath, err)
+	}
+}
@@ -2,65 +2,19 @@ package k3s
 
 import (
 	"capten/pkg/config"
+	"capten/pkg/types"
+	"os"
+	"path/filepath"
 	//	"reflect"
 	"strings"
 	"testing"
 )
 
-func Test_getClusterInfo(t *testing.T) {
-	validConfig := config.CaptenConfig{
-		// Add valid configuration fields here
-	}
+func Test_prepareTerraform(t *testing.T) {
 	type args struct {
-		captenConfig config.CaptenConfig
-	}
-	invalidConfig := config.CaptenConfig{
-		// Add invalid configuration fields here
-	}
-
-	tests := []struct {
-		name string
-		args args
-		//	want    interface{}
-		wantErr bool
-	}{
-		{
-			name: "Valid cluster configuration",
-			args: args{
-				captenConfig: validConfig,
-			},
-			//		want:    // Add expected result for valid config,
-			wantErr: false,
-		},
-		{
-			name: "Invalid cluster configuration",
-			args: args{
-				captenConfig: invalidConfig,
-			},
-			//		want:    // Add expected result for invalid config,
-			wantErr: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			_, err := getClusterInfo(tt.args.captenConfig)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("getClusterInfo() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			// if !reflect.DeepEqual(got, tt.want) {
-			// 	t.Errorf("getClusterInfo() = %v, want %v", got, tt.want)
-			// }
-		})
-	}
-}
-func Test_createOrDestroyCluster(t *testing.T) {
-	// Test create or destroy cluster using valid and invalid config
-	// and both create and destroy actions
-	type args struct {
-		captenConfig config.CaptenConfig
-		action       string
+		captenConfig    config.CaptenConfig
+		prepare         prepareTerraformFunc
+		generateVarFile bool
 	}
 
 	tests := []struct {
@@ -69,34 +23,18 @@ func Test_createOrDestroyCluster(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			name: "Successful creation of a cluster",
-			args: args{
-				captenConfig: config.CaptenConfig{},
-				action:       "create",
-			},
-			wantErr: false,
-		},
-		{
-			name: "Successful destruction of a cluster",
-			args: args{
-				captenConfig: config.CaptenConfig{},
-				action:       "destroy",
-			},
-			wantErr: false,
-		},
-		{
-			name: "Error handling when creating a cluster with invalid config",
+			name: "Missing aws cluster info file",
 			args: args{
-				captenConfig: config.CaptenConfig{},
-				action:       "create",
+				captenConfig: config.CaptenConfig{CaptenClusterValues: config.CaptenClusterValues{CloudService: "aws"}, ConfigDirPath: "/invalid/"},
+				prepare:      prepareAWSTerraform,
 			},
 			wantErr: true,
 		},
 		{
-			name: "Error handling when destroying a cluster with invalid config",
+			name: "Missing azure cluster info file",
 			args: args{
-				captenConfig: config.CaptenConfig{},
-				action:       "destroy",
+				captenConfig: config.CaptenConfig{CaptenClusterValues: config.CaptenClusterValues{CloudService: "azure"}, ConfigDirPath: "/invalid/"},
+				prepare:      prepareAzureTerraform,
 			},
 			wantErr: true,
 		},
@@ -104,23 +42,17 @@ func Test_createOrDestroyCluster(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := createOrDestroyCluster(tt.args.captenConfig, tt.args.action); (err != nil) != tt.wantErr {
-				t.Errorf("createOrDestroyCluster() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if err := createOrDestroyCluster(tt.args.captenConfig, tt.args.action); (err != nil) != tt.wantErr {
-				t.Errorf("createOrDestroyCluster() error = %v, wantErr %v", err, tt.wantErr)
+			if _, err := tt.args.prepare(tt.args.captenConfig, tt.args.generateVarFile); (err != nil) != tt.wantErr {
+				t.Errorf("prepareTerraform() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
-func TestCreate(t *testing.T) {
+
+func TestProvisioner(t *testing.T) {
 	type args struct {
 		captenConfig config.CaptenConfig
+		provisioner  *provisioner
 	}
 	tests := []struct {
 		name    string
@@ -128,58 +60,36 @@ func TestCreate(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			name: "Successful creation with valid config",
+			name: "Error handling with invalid aws config",
 			args: args{
-				captenConfig: config.CaptenConfig{},
+				captenConfig: config.CaptenConfig{CaptenClusterValues: config.CaptenClusterValues{CloudService: "aws"}, ConfigDirPath: "/invalid/"},
+				provisioner:  NewAWSProvisioner(),
 			},
-			wantErr: false,
+			wantErr: true,
 		},
 		{
-			name: "Error handling with invalid config",
+			name: "Error handling with invalid azure config",
 			args: args{
-				captenConfig: config.CaptenConfig{},
+				captenConfig: config.CaptenConfig{CaptenClusterValues: config.CaptenClusterValues{CloudService: "azure"}, ConfigDirPath: "/invalid/"},
+				provisioner:  NewAzureProvisioner(),
 			},
 			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := Create(tt.args.captenConfig); (err != nil) != tt.wantErr {
+			if err := tt.args.provisioner.Create(tt.args.captenConfig); (err != nil) != tt.wantErr {
 				t.Errorf("Create() error = %v, wantErr %v", err, tt.wantErr)
 			}
-		})
-	}
-}
-
-func TestDestroy(t *testing.T) {
-	type args struct {
-		captenConfig config.CaptenConfig
-	}
-	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
-	}{
-		{
-			name: "Successful destruction of a cluster",
-			args: args{
-				captenConfig: config.CaptenConfig{},
-			},
-			wantErr: false,
-		},
-		{
-			name: "Error handling when trying to destroy a non-existing cluster",
-			args: args{
-				captenConfig: config.CaptenConfig{},
-			},
-			wantErr: true,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if err := Destroy(tt.args.captenConfig); (err != nil) != tt.wantErr {
+			if err := tt.args.provisioner.Destroy(tt.args.captenConfig); (err != nil) != tt.wantErr {
 				t.Errorf("Destroy() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if _, err := tt.args.provisioner.Status(tt.args.captenConfig); (err != nil) != tt.wantErr {
+				t.Errorf("Status() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, err := tt.args.provisioner.Outputs(tt.args.captenConfig); (err != nil) != tt.wantErr {
+				t.Errorf("Outputs() error = %v, wantErr %v", err, tt.wantErr)
+			}
 		})
 	}
 }
@@ -187,45 +97,96 @@ func TestDestroy(t *testing.T) {
 func Test_generateTemplateVarFile(t *testing.T) {
 	tests := []struct {
 		name           string
-		captenConfig   config.CaptenConfig
 		clusterInfo    interface{}
 		templateFile   string
+		want           string
 		expectedErrMsg string
 	}{
 		{
 			name:         "Successful Generation",
-			captenConfig: config.CaptenConfig{}, // Fill in with appropriate config
-			clusterInfo:  "testClusterInfo",     // Fill in with appropriate cluster info
-			templateFile: "testTemplateFile",    // Fill in with appropriate template file
+			clusterInfo:  types.AWSClusterInfo{AwsAccessKey: "access", Region: "us-west-2"},
+			templateFile: "values.aws.tmpl",
+			want:         "key = \"access\"\nregion = \"us-west-2\"\n",
 		},
 		{
 			name:           "Error Reading Template File",
-			captenConfig:   config.CaptenConfig{}, // Fill in with appropriate config
-			clusterInfo:    "testClusterInfo",     // Fill in with appropriate cluster info
-			templateFile:   "invalidTemplateFile", // An invalid template file that should cause an error
+			clusterInfo:    types.AWSClusterInfo{},
+			templateFile:   "invalidTemplateFile",
 			expectedErrMsg: "failed to read template file",
 		},
-		{
-			name:           "Error Creating Template File",
-			captenConfig:   config.CaptenConfig{}, // Fill in with appropriate config
-			clusterInfo:    "testClusterInfo",     // Fill in with appropriate cluster info
-			templateFile:   "testTemplateFile",    // Fill in with appropriate template file
-			expectedErrMsg: "failed to create template file",
-		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := generateTemplateVarFile(tt.captenConfig, tt.clusterInfo, tt.templateFile)
+			captenConfig := config.CaptenConfig{
+				CurrentDirPath:           t.TempDir(),
+				TerraformTemplateDirPath: "/templates/",
+				TerraformVarFileName:     "values.tfvars",
+			}
+			templateDir := filepath.Join(captenConfig.CurrentDirPath, "templates")
+			if err := os.MkdirAll(templateDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			templateContent := "key = \"{{.AwsAccessKey}}\"\nregion = \"{{.Region}}\"\n"
+			if err := os.WriteFile(filepath.Join(templateDir, "values.aws.tmpl"), []byte(templateContent), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := generateTemplateVarFile(captenConfig, tt.clusterInfo, tt.templateFile)
 			if tt.expectedErrMsg != "" {
 				if err == nil || !strings.Contains(err.Error(), tt.expectedErrMsg) {
 					t.Errorf("Expected error containing '%s', but got: %v", tt.expectedErrMsg, err)
 				}
-			} else {
-				if err != nil {
-					t.Errorf("Unexpected error: %v", err)
-				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			data, err := os.ReadFile(filepath.Join(templateDir, "values.tfvars"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("var file = %q, want %q", data, tt.want)
 			}
 		})
 	}
 }
+
+func Test_generateTemplateVarFilePermissions(t *testing.T) {
+	captenConfig := config.CaptenConfig{
+		CurrentDirPath:           t.TempDir(),
+		TerraformTemplateDirPath: "/templates/",
+		TerraformVarFileName:     "values.tfvars",
+	}
+	templateDir := filepath.Join(captenConfig.CurrentDirPath, "templates")
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "values.aws.tmpl"), []byte(`key = "{{.AwsAccessKey}}"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := generateTemplateVarFile(captenConfig, types.AWSClusterInfo{AwsAccessKey: "access"}, "values.aws.tmpl")
+	if err != nil {
+		t.Fatalf("generateTemplateVarFile() error = %v", err)
+	}
+
+	varFile := filepath.Join(templateDir, "values.tfvars")
+	info, err := os.Stat(varFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("var file permissions = %v, want 0600", info.Mode().Perm())
+	}
+	data, err := os.ReadFile(varFile)
+	if err != nil || string(data) != `key = "access"` {
+		t.Errorf("var file = %s, %v", data, err)
+	}
+
+	removeTemplateVarFile(captenConfig)
+	if _, err := os.Stat(varFile); !os.IsNotExist(err) {
+		t.Errorf("var file not removed, %v", err)
+	}
+}
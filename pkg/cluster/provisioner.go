@@ -0,0 +1,76 @@
+package cluster
+
+import (
+	"capten/pkg/config"
+	"capten/pkg/types"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+type Provisioner interface {
+	Create(captenConfig config.CaptenConfig) error
+	Destroy(captenConfig config.CaptenConfig) error
+	Status(captenConfig config.CaptenConfig) (string, error)
+	Outputs(captenConfig config.CaptenConfig) (map[string]string, error)
+}
+
+type Planner interface {
+	Plan(captenConfig config.CaptenConfig, planFile string) (*types.ClusterPlanSummary, error)
+	ApplyPlan(captenConfig config.CaptenConfig, planFile string) error
+}
+
+var (
+	provisionersMutex sync.RWMutex
+	provisioners      = map[string]map[string]Provisioner{}
+)
+
+func Register(cloudService, clusterType string, provisioner Provisioner) {
+	provisionersMutex.Lock()
+	defer provisionersMutex.Unlock()
+
+	if _, ok := provisioners[cloudService]; !ok {
+		provisioners[cloudService] = map[string]Provisioner{}
+	}
+	provisioners[cloudService][clusterType] = provisioner
+}
+
+func GetProvisioner(cloudService, clusterType string) (Provisioner, error) {
+	provisionersMutex.RLock()
+	defer provisionersMutex.RUnlock()
+
+	clusterTypes, ok := provisioners[cloudService]
+	if !ok {
+		return nil, fmt.Errorf("cloud service '%s' is not supported", cloudService)
+	}
+
+	provisioner, ok := clusterTypes[clusterType]
+	if !ok {
+		return nil, fmt.Errorf("cluster type '%s' is not supported on cloud service '%s'", clusterType, cloudService)
+	}
+	return provisioner, nil
+}
+
+func SupportedCloudServices() []string {
+	provisionersMutex.RLock()
+	defer provisionersMutex.RUnlock()
+
+	cloudServices := []string{}
+	for cloudService := range provisioners {
+		cloudServices = append(cloudServices, cloudService)
+	}
+	sort.Strings(cloudServices)
+	return cloudServices
+}
+
+func SupportedClusterTypes(cloudService string) []string {
+	provisionersMutex.RLock()
+	defer provisionersMutex.RUnlock()
+
+	clusterTypes := []string{}
+	for clusterType := range provisioners[cloudService] {
+		clusterTypes = append(clusterTypes, clusterType)
+	}
+	sort.Strings(clusterTypes)
+	return clusterTypes
+}
@@ -1,7 +1,15 @@
 package cmd
 
 import (
+	"capten/pkg/agent"
+	"capten/pkg/cert"
+	"capten/pkg/clog"
+	"capten/pkg/cluster"
+	"capten/pkg/config"
+	"capten/pkg/output"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -10,10 +18,28 @@ var rootCmd = &cobra.Command{
 	Use:   "capten",
 	Short: "",
 	Long:  `command line tool for building cluster`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		contextName, _ := cmd.Flags().GetString("context")
+		config.SetContext(contextName)
+		configOverrides, _ := cmd.Flags().GetStringArray("config-override")
+		if err := config.SetFlagOverrides(configOverrides); err != nil {
+			clog.Logger.Error(err)
+		}
+		callTimeout, _ := cmd.Flags().GetDuration("timeout")
+		if err := agent.SetCallTimeout(callTimeout); err != nil {
+			return err
+		}
+		outputFormat, _ := cmd.Flags().GetString("output")
+		return output.SetFormat(outputFormat)
+	},
 }
 
 func Execute() {
-	cobra.CheckErr(rootCmd.Execute())
+	err := rootCmd.Execute()
+	if closeErr := agent.CloseConnections(); closeErr != nil {
+		clog.Logger.Debug(closeErr)
+	}
+	cobra.CheckErr(err)
 }
 
 var clusterCmd = &cobra.Command{
@@ -34,6 +60,18 @@ var clusterAppsCmd = &cobra.Command{
 	Long:  ``,
 }
 
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "cluster context operations",
+	Long:  ``,
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "capten config operations",
+	Long:  ``,
+}
+
 var pluginCmd = &cobra.Command{
 	Use:   "plugin",
 	Short: "plugin operations",
@@ -46,6 +84,24 @@ var pluginStoreCmd = &cobra.Command{
 	Long:  ``,
 }
 
+var certCmd = &cobra.Command{
+	Use:   "cert",
+	Short: "cert operations",
+	Long:  ``,
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "cluster access profile operations",
+	Long:  ``,
+}
+
+var credsCmd = &cobra.Command{
+	Use:   "creds",
+	Short: "vault credential operations",
+	Long:  ``,
+}
+
 var clusterResourcesCmd = &cobra.Command{
 	Use:   "resources",
 	Short: "cluster resources operations",
@@ -66,22 +122,98 @@ func readAndValidClusterFlags(cmd *cobra.Command) (cloudService string, clusterT
 }
 
 func validateClusterFlags(cloudService, clusterType string) (err error) {
-
-	if cloudService != "aws" && cloudService != "azure" {
-		err = fmt.Errorf("cloud service '%s' is not supported, supported cloud serivces: aws", cloudService)
+	clusterTypes := cluster.SupportedClusterTypes(cloudService)
+	if len(clusterTypes) == 0 {
+		err = fmt.Errorf("cloud service '%s' is not supported, supported cloud serivces: %s",
+			cloudService, strings.Join(cluster.SupportedCloudServices(), ", "))
 		return
 	}
 
-	if clusterType != "talos" {
-		err = fmt.Errorf("cluster type '%s' is not supported, supported types: talos", clusterType)
+	if !slices.Contains(clusterTypes, clusterType) {
+		err = fmt.Errorf("cluster type '%s' is not supported, supported types: %s", clusterType, strings.Join(clusterTypes, ", "))
 		return
 	}
 	return
 }
 
 func init() {
+	rootCmd.PersistentFlags().String("context", "", "cluster context to use (default: current context)")
+	rootCmd.PersistentFlags().StringArray("config-override", nil, "override a config value, by field or env name (e.g. DomainName=example.com)")
+	rootCmd.PersistentFlags().Duration("timeout", agent.DefaultCallTimeout, "timeout of each call to the cluster agent and vault (e.g. 30s, 2m)")
+	rootCmd.PersistentFlags().StringP("output", "o", output.FormatTable, "output format of list and show commands (table, wide, json, yaml)")
 	rootCmd.AddCommand(clusterCmd)
 	rootCmd.AddCommand(pluginCmd)
+	rootCmd.AddCommand(contextCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(certCmd)
+	rootCmd.AddCommand(profileCmd)
+	rootCmd.AddCommand(credsCmd)
+
+	//init options
+	initCmd.PersistentFlags().String("cloud", "", "cloud service (aws, azure)")
+	initCmd.PersistentFlags().String("type", "", "type of cluster (default: talos)")
+	initCmd.PersistentFlags().String("cluster-name", "", "name used for the cloud resources of the cluster (default: capten)")
+	initCmd.PersistentFlags().String("domain", "", "domain name of the cluster")
+	initCmd.PersistentFlags().String("region", "", "cloud region of the cluster")
+	initCmd.PersistentFlags().String("instance-type", "", "instance type of the cluster nodes")
+	initCmd.PersistentFlags().Int("master-count", 0, "number of master nodes")
+	initCmd.PersistentFlags().Int("worker-count", 0, "number of worker nodes")
+	initCmd.PersistentFlags().String("social-integration", "", "social integration (none, slack, teams)")
+	initCmd.PersistentFlags().String("slack-url", "", "slack webhook url")
+	initCmd.PersistentFlags().String("slack-channel", "", "slack channel")
+	initCmd.PersistentFlags().String("teams-url", "", "teams webhook url")
+	initCmd.PersistentFlags().Bool("non-interactive", false, "do not prompt, use the flags and the default values")
+	initCmd.PersistentFlags().Bool("force", false, "overwrite the files of an existing workspace")
+
+	//cert options
+	certRotateSubCmd.PersistentFlags().Bool("agent", false, "rotate the agent cert")
+	certRotateSubCmd.PersistentFlags().Bool("client", false, "rotate the client cert and rebuild the client certs zip")
+	certRotateSubCmd.PersistentFlags().Bool("intermediate", false, "rotate the intermediate CA cert, reissues the agent and client certs")
+	certCmd.AddCommand(certRotateSubCmd)
+	certStatusSubCmd.PersistentFlags().Int("warn-days", cert.DefaultExpiryWarningDays, "warn about certs expiring within the given number of days")
+	certCmd.AddCommand(certStatusSubCmd)
+	certIssueClientSubCmd.PersistentFlags().String("name", "", "name of the client, used as the common name of the cert")
+	certIssueClientSubCmd.PersistentFlags().String("ttl", "365d", "lifetime of the cert, in days (e.g. 90d) or as a duration (e.g. 36h)")
+	certCmd.AddCommand(certIssueClientSubCmd)
+	certRevokeSubCmd.PersistentFlags().String("name", "", "name of the client cert to revoke")
+	certCmd.AddCommand(certRevokeSubCmd)
+
+	//profile options
+	profileExportSubCmd.PersistentFlags().String("name", "", "name of the profile, used as the context name on import (default: context or domain name)")
+	profileExportSubCmd.PersistentFlags().String("client", "", "export the issued client cert of the given name instead of the default client cert")
+	profileExportSubCmd.PersistentFlags().String("file", "", "path to write the profile file (default: cert/<name>-capten-profile.yaml)")
+	profileCmd.AddCommand(profileExportSubCmd)
+	profileImportSubCmd.PersistentFlags().String("name", "", "name of the context to add (default: profile name)")
+	profileImportSubCmd.PersistentFlags().Bool("use", false, "switch to the added context")
+	profileCmd.AddCommand(profileImportSubCmd)
+
+	//creds options
+	credsCmd.AddCommand(credsListSubCmd)
+	credsGetSubCmd.PersistentFlags().Bool("reveal", false, "show the credential values instead of masking them")
+	credsCmd.AddCommand(credsGetSubCmd)
+	credsPutSubCmd.PersistentFlags().StringArray("value", nil, "credential value as key=value, can be repeated")
+	credsPutSubCmd.PersistentFlags().StringArray("from-file", nil, "credential value read from a file as key=path, can be repeated")
+	credsPutSubCmd.PersistentFlags().Bool("merge", false, "keep the existing keys of the credential, only update the given keys")
+	credsCmd.AddCommand(credsPutSubCmd)
+	credsCmd.AddCommand(credsDeleteSubCmd)
+	credsRotateSubCmd.PersistentFlags().String("name", "", "name of the app credential config to rotate (e.g. clickhouse, natscred)")
+	credsRotateSubCmd.PersistentFlags().Bool("restart", false, "wait for the secrets to sync and restart the workloads using them")
+	credsRotateSubCmd.PersistentFlags().Bool("force", false, "rotate a database password, which has to be changed in the database as well")
+	credsCmd.AddCommand(credsRotateSubCmd)
+
+	//config options
+	configCmd.AddCommand(configViewSubCmd)
+	configCmd.AddCommand(configGetSubCmd)
+	configCmd.AddCommand(configSetSubCmd)
+
+	//context options
+	contextAddSubCmd.PersistentFlags().String("from-dir", "", "directory to copy the config and cert files from (default: current directory)")
+	contextAddSubCmd.PersistentFlags().Bool("empty", false, "add the context without copying config and cert files")
+	contextCmd.AddCommand(contextAddSubCmd)
+	contextCmd.AddCommand(contextUseSubCmd)
+	contextCmd.AddCommand(contextListSubCmd)
+	contextCmd.AddCommand(contextDeleteSubCmd)
 
 	//cluster optons
 	clusterCmd.AddCommand(clusterShowCmd)
@@ -91,16 +223,49 @@ func init() {
 	//cluster create options
 	clusterCreateSubCmd.PersistentFlags().String("cloud", "", "cloud service (default: azure)")
 	clusterCreateSubCmd.PersistentFlags().String("type", "", "type of cluster (default: talos)")
+	clusterCreateSubCmd.PersistentFlags().String("plan-file", "", "saved plan file to apply, generated by cluster plan")
 	clusterCmd.AddCommand(clusterCreateSubCmd)
 
+	//cluster plan options
+	clusterPlanSubCmd.PersistentFlags().String("cloud", "", "cloud service (default: azure)")
+	clusterPlanSubCmd.PersistentFlags().String("type", "", "type of cluster (default: talos)")
+	clusterPlanSubCmd.PersistentFlags().String("out", "", "path to write the plan file (default: templates/k3s/cluster.tfplan)")
+	clusterCmd.AddCommand(clusterPlanSubCmd)
+
 	//cluster destroy options
 	clusterCmd.AddCommand(clusterDestroySubCmd)
+	clusterStatusSubCmd.PersistentFlags().Int("warn-days", cert.DefaultExpiryWarningDays, "warn about certs expiring within the given number of days")
+	clusterCmd.AddCommand(clusterStatusSubCmd)
 
 	//cluster show options
 	clusterShowCmd.AddCommand(showClusterInfoSubCmd)
 
 	//cluster apps options
+	appsInstallSubCmd.PersistentFlags().Bool("resume", false, "resume install, skipping steps completed in the previous run")
+	appsInstallSubCmd.PersistentFlags().String("from-step", "", "run install steps starting from the given step")
+	appsInstallSubCmd.PersistentFlags().StringSlice("only-step", nil, "run only the given install steps")
+	appsInstallSubCmd.PersistentFlags().Int("workers", 0, "number of apps installed concurrently (default: 4)")
+	appsInstallSubCmd.PersistentFlags().Bool("diff", false, "show the values and manifest changes of the apps and ask to confirm before installing")
+	appsInstallSubCmd.PersistentFlags().Bool("yes", false, "install without confirmation after showing the diff")
+	appsInstallSubCmd.PersistentFlags().Bool("dry-run", false, "render the values and manifests of the apps without installing them")
+	appsInstallSubCmd.PersistentFlags().String("output-dir", "", "directory to write the rendered apps to, one directory per app")
+	appsInstallSubCmd.AddCommand(appsInstallStatusSubCmd)
 	clusterAppsCmd.AddCommand(appsInstallSubCmd)
+	appsUninstallSubCmd.PersistentFlags().String("app-name", "", "name of app to uninstall")
+	appsUninstallSubCmd.PersistentFlags().String("group", "", "app group to uninstall (core, default)")
+	clusterAppsCmd.AddCommand(appsUninstallSubCmd)
+	appsDeploySubCmd.PersistentFlags().String("app-name", "", "name of app to deploy")
+	appsDeploySubCmd.PersistentFlags().String("version", "", "chart version to deploy (default: version in app config)")
+	appsDeploySubCmd.PersistentFlags().StringArray("set", nil, "override app values (e.g. key1=val1,key2.subkey=val2)")
+	clusterAppsCmd.AddCommand(appsDeploySubCmd)
+	appsHistorySubCmd.PersistentFlags().String("app-name", "", "name of app")
+	clusterAppsCmd.AddCommand(appsHistorySubCmd)
+	appsRollbackSubCmd.PersistentFlags().String("app-name", "", "name of app to rollback")
+	appsRollbackSubCmd.PersistentFlags().Int("revision", 0, "revision to rollback to (default: previous revision)")
+	clusterAppsCmd.AddCommand(appsRollbackSubCmd)
+	appsDiffSubCmd.PersistentFlags().String("app-name", "", "name of app to diff")
+	appsDiffSubCmd.PersistentFlags().String("group", "", "app group to diff (core, default)")
+	clusterAppsCmd.AddCommand(appsDiffSubCmd)
 	clusterAppsCmd.AddCommand(appsListSubCmd)
 	appsShowSubCmd.PersistentFlags().String("app-name", "", "name of app")
 	clusterAppsCmd.AddCommand(appsShowSubCmd)
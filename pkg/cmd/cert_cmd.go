@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"capten/pkg/cert"
+	"capten/pkg/clog"
+	"capten/pkg/config"
+	"capten/pkg/output"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var certRotateSubCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "reissue the agent, client or intermediate CA certs and update the cluster secrets",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := cert.RotateOptions{}
+		opts.Agent, _ = cmd.Flags().GetBool("agent")
+		opts.Client, _ = cmd.Flags().GetBool("client")
+		opts.Intermediate, _ = cmd.Flags().GetBool("intermediate")
+		if !opts.Agent && !opts.Client && !opts.Intermediate {
+			clog.Logger.Error("specify the certs to rotate with --agent, --client or --intermediate")
+			return
+		}
+
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		if err := cert.RotateCerts(captenConfig, opts); err != nil {
+			clog.Logger.Errorf("failed to rotate certs, %v", err)
+			return
+		}
+		clog.Logger.Info("Rotated certs in ", captenConfig.PrepareDirPath(captenConfig.CertDirPath))
+
+		if err := cert.PublishCerts(captenConfig); err != nil {
+			clog.Logger.Errorf("failed to update secrets for certs, %v", err)
+			return
+		}
+		clog.Logger.Info("Updated cert secrets on cluster")
+		if opts.Client {
+			clog.Logger.Infof("Client certs exported to %s",
+				captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.ClientCertExportFileName))
+		}
+	},
+}
+
+var certStatusSubCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show the expiry of the CA, agent and client certs",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		warnDays, _ := cmd.Flags().GetInt("warn-days")
+		if warnDays < 0 {
+			clog.Logger.Error("warn-days must not be negative")
+			return
+		}
+
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		statuses := cert.CertsStatus(captenConfig, time.Duration(warnDays)*24*time.Hour)
+		renderOutput(statuses)
+		if !output.IsTable() {
+			return
+		}
+		for _, status := range statuses {
+			switch status.Status {
+			case cert.CertExpiring:
+				clog.Logger.Warnf("%s cert expires in %d days, rotate it with capten cert rotate", status.Name, status.DaysLeft)
+			case cert.CertExpired:
+				clog.Logger.Warnf("%s cert expired on %s", status.Name, status.NotAfter)
+			case cert.CertMissing, cert.CertInvalid:
+				clog.Logger.Warnf("%s cert, %s", status.Name, status.Error)
+			}
+		}
+	},
+}
+
+var certIssueClientSubCmd = &cobra.Command{
+	Use:   "issue-client",
+	Short: "issue a named client cert signed by the intermediate CA",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		if len(name) == 0 {
+			clog.Logger.Error("specify the name of the client cert with --name")
+			return
+		}
+
+		ttlValue, _ := cmd.Flags().GetString("ttl")
+		ttl, err := cert.ParseTTL(ttlValue)
+		if err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		exportFilePath, err := cert.IssueClientCert(captenConfig, name, ttl)
+		if err != nil {
+			clog.Logger.Errorf("failed to issue client cert, %v", err)
+			return
+		}
+		clog.Logger.Infof("Issued client cert %s, exported to %s", name, exportFilePath)
+	},
+}
+
+var certRevokeSubCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "revoke a named client cert and publish the CRL to the cluster",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		if len(name) == 0 {
+			clog.Logger.Error("specify the name of the client cert with --name")
+			return
+		}
+
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		if err := cert.RevokeClientCert(captenConfig, name); err != nil {
+			clog.Logger.Errorf("failed to revoke client cert, %v", err)
+			return
+		}
+		clog.Logger.Infof("Revoked client cert %s, CRL written to %s", name,
+			captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.CRLFileName))
+
+		if err := cert.PublishCerts(captenConfig); err != nil {
+			clog.Logger.Errorf("failed to publish CRL to cluster, %v", err)
+			return
+		}
+		clog.Logger.Info("Published CRL to cluster")
+	},
+}
@@ -1,20 +1,20 @@
 package cmd
 
 import (
+	"bufio"
 	"capten/pkg/agent"
 	"capten/pkg/app"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
-	"capten/pkg/cert"
 	"capten/pkg/clog"
 	"capten/pkg/config"
-	"capten/pkg/k8s"
-	"os"
+	"capten/pkg/setup"
+	"capten/pkg/types"
 
-	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
 )
 
 func readAppsNameFlags(cmd *cobra.Command) (appsName string, err error) {
@@ -25,34 +25,37 @@ func readAppsNameFlags(cmd *cobra.Command) (appsName string, err error) {
 	return
 }
 
-type SetupAppsActionList struct {
-	Actions SetupAppsActions `yaml:"actions"`
-}
-
-type SetupAppsActions struct {
-	CreateNamespaces        map[string]interface{} `yaml:"create-namespaces"`
-	InstallCoreAppGroup     map[string]interface{} `yaml:"install-core-app-group"`
-	ConfigureAgentCerts     map[string]interface{} `yaml:"configure-agent-certs"`
-	ConfigureSecrets        map[string]interface{} `yaml:"configure-secrets"`
-	ConfigureCertIssuer     map[string]interface{} `yaml:"configure-cert-issuer"`
-	ConfigureCstorPool      map[string]interface{} `yaml:"configure-cstor-pool"`
-	InstallDefaultAppGroup  map[string]interface{} `yaml:"install-default-app-group"`
-	SynchApps               map[string]interface{} `yaml:"synch-apps"`
-	StoreClusterCredentials map[string]interface{} `yaml:"store-cluster-credentials"`
-	FetchLoadBalancerHost   map[string]interface{} `yaml:"fetch-loadBalancerHost"`
-}
-
 var appsInstallSubCmd = &cobra.Command{
 	Use:   "install",
 	Short: "install capten stack apps on cluster",
 	Long:  ``,
 	Run: func(cmd *cobra.Command, args []string) {
+		runOptions, err := readSetupAppsRunFlags(cmd)
+		if err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+
+		workers, _ := cmd.Flags().GetInt("workers")
+		if workers < 0 {
+			clog.Logger.Error("workers must not be negative")
+			return
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		outputDir, _ := cmd.Flags().GetString("output-dir")
+		if dryRun && len(outputDir) == 0 {
+			clog.Logger.Error("specify the output-dir for rendering the apps in dry-run")
+			return
+		}
 
 		captenConfig, err := config.GetCaptenConfig()
 		if err != nil {
 			clog.Logger.Errorf("failed to read capten config, %v", err)
 			return
 		}
+		if workers > 0 {
+			captenConfig.AppDeployWorkers = workers
+		}
 
 		globalValues, err := app.PrepareGlobalVaules(captenConfig)
 		if err != nil {
@@ -60,181 +63,300 @@ var appsInstallSubCmd = &cobra.Command{
 			return
 		}
 
-		actions, err := loadSetupAppsActions(captenConfig)
+		stepConfigs, err := setup.LoadStepsConfig(captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.SetupAppsConfigFile))
 		if err != nil {
-			clog.Logger.Errorf("loading setup apps actions failed, %v", err)
+			clog.Logger.Errorf("loading setup apps steps failed, %v", err)
 			return
 		}
 
-		err = execActionIfEnabled(actions.Actions.CreateNamespaces, func() error {
-			kubeconfigPath := captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.KubeConfigFileName)
-			return k8s.CreateNamespaceIfNotExists(kubeconfigPath, captenConfig.CaptenNamespace)
-		})
-		if err != nil {
-			clog.Logger.Errorf("capten namespace creation failed, %v", err)
+		if diff, _ := cmd.Flags().GetBool("diff"); diff {
+			if err := diffSetupAppGroups(captenConfig, globalValues, stepConfigs); err != nil {
+				clog.Logger.Errorf("%v", err)
+				return
+			}
+			if yes, _ := cmd.Flags().GetBool("yes"); !yes && !confirm("Install the apps with the changes above?") {
+				clog.Logger.Info("Apps install cancelled")
+				return
+			}
+		}
+
+		if dryRun {
+			if err := renderSetupAppGroups(captenConfig, globalValues, stepConfigs, outputDir); err != nil {
+				clog.Logger.Errorf("%v", err)
+				return
+			}
+			clog.Logger.Infof("Rendered applications to %s", outputDir)
+			return
 		}
 
-		err = execActionIfEnabled(actions.Actions.InstallCoreAppGroup, func() error {
-			return app.DeployApps(captenConfig, globalValues, captenConfig.CoreAppGroupsFileName)
-		})
+		steps, err := setup.BuildSteps(stepConfigs, setupAppsActions(&captenConfig, globalValues))
 		if err != nil {
-			clog.Logger.Errorf(" Error while deploying apps %v", err)
+			clog.Logger.Errorf("invalid setup apps steps, %v", err)
 			return
 		}
 
-		err = execActionIfEnabled(actions.Actions.FetchLoadBalancerHost, func() error {
-			lbhostName, err := k8s.FetchClusterLoadBalancerHost(captenConfig.PrepareFilePath(captenConfig.ConfigDirPath,
-				captenConfig.KubeConfigFileName), "traefik", captenConfig.LBServiceName)
-			if err != nil {
-				clog.Logger.Error("failed to get LoadBalancerService ", err)
-			}
+		state, err := setup.LoadState(captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.SetupAppsStateFile))
+		if err != nil {
+			clog.Logger.Errorf("loading setup apps state failed, %v", err)
+			return
+		}
 
-			err = retry(10, 30*time.Second, func() error {
-				if err := config.UpdateLBEndpointFile(&captenConfig, lbhostName, ""); err != nil {
-					clog.Logger.Infof("LB is not updated in the capten_lb_endpoint.yaml ")
-					return errors.WithMessage(err, "failed to update LB ")
-				}
-				return nil
-			})
-			if err != nil {
-				return err
-			}
-			clog.Logger.Info("Fetched cluster agent address")
-			return nil
-		})
+		err = setup.NewRunner(state, globalValues).Run(steps, runOptions)
 		if err != nil {
 			clog.Logger.Errorf("%v", err)
 			return
 		}
+	},
+}
 
-		err = execActionIfEnabled(actions.Actions.ConfigureAgentCerts, func() error {
-			if err := cert.PrepareCerts(captenConfig); err != nil {
-				return errors.WithMessage(err, "failed to generate certificate")
-			}
-			if err := k8s.CreateOrUpdateCertSecrets(captenConfig); err != nil {
-				return errors.WithMessage(err, "failed to create secret for certs")
-			}
-			clog.Logger.Info("Configured Certificates for Cluster Agent")
-			return nil
-		})
+var appsInstallStatusSubCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show status of capten stack apps install steps",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		captenConfig, err := config.GetCaptenConfig()
 		if err != nil {
-			clog.Logger.Errorf("%v", err)
+			clog.Logger.Errorf("failed to read capten config, %v", err)
 			return
 		}
 
-		err = execActionIfEnabled(actions.Actions.InstallDefaultAppGroup, func() error {
-			if err = k8s.CreateOrUpdateClusterIssuer(captenConfig); err != nil {
-				return errors.WithMessage(err, "failed to create cstorPoolCluster")
-			}
-			clog.Logger.Info("Configured Certificate Issuer on Cluster")
-			return nil
-		})
+		state, err := setup.LoadState(captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.SetupAppsStateFile))
 		if err != nil {
-			clog.Logger.Errorf("%v", err)
+			clog.Logger.Errorf("loading setup apps state failed, %v", err)
 			return
 		}
 
-		err = execActionIfEnabled(actions.Actions.ConfigureCstorPool, func() error {
-			err = k8s.CreateCStorPoolClusterWithRetries(captenConfig)
-			if err != nil {
-				clog.Logger.Errorf("Failed to configure storage pool, %v", err)
-				return err
-			}
-			clog.Logger.Info("Configured storage pool")
-			return nil
-		})
+		renderOutput(installSteps(state.Steps))
+	},
+}
+
+// confirm asks the question on stdout and reports whether it was answered with yes
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func readSetupAppsRunFlags(cmd *cobra.Command) (opts setup.RunOptions, err error) {
+	opts.Resume, _ = cmd.Flags().GetBool("resume")
+	opts.FromStep, _ = cmd.Flags().GetString("from-step")
+	opts.OnlySteps, _ = cmd.Flags().GetStringSlice("only-step")
+	if len(opts.OnlySteps) != 0 && (opts.Resume || len(opts.FromStep) != 0) {
+		return opts, fmt.Errorf("only-step can not be used along with resume or from-step")
+	}
+	return
+}
+
+func formatStepTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+var appsDeploySubCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "install or upgrade a capten stack app on cluster",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		appName, err := readAppsNameFlags(cmd)
 		if err != nil {
-			clog.Logger.Errorf("%v", err)
+			clog.Logger.Error(err)
 			return
 		}
+		version, _ := cmd.Flags().GetString("version")
+		setValues, _ := cmd.Flags().GetStringArray("set")
 
-		err = execActionIfEnabled(actions.Actions.StoreClusterCredentials, func() error {
-			clog.Logger.Info("Storing credentails on cluster")
-			err = retry(10, 30*time.Second, func() error {
-				err = agent.StoreCredentials(captenConfig, globalValues)
-				if err != nil {
-					clog.Logger.Infof("Vault is not ready")
-					return errors.WithMessage(err, "failed to store credentials")
-				}
-				if captenConfig.CloudService == "aws" {
-					err = agent.StoreClusterCredentials(captenConfig, globalValues)
-					if err != nil {
-						return errors.WithMessage(err, "failed to store cluster credentials")
-					}
-				}
-				return nil
-			})
-			if err != nil {
-				return err
-			}
-			clog.Logger.Info("Stored credentails on cluster")
-			return nil
-		})
+		captenConfig, err := config.GetCaptenConfig()
 		if err != nil {
-			clog.Logger.Errorf("%v", err)
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		globalValues, err := app.PrepareGlobalVaules(captenConfig)
+		if err != nil {
+			clog.Logger.Errorf("applications values preparation failed, %v", err)
 			return
 		}
 
-		err = execActionIfEnabled(actions.Actions.InstallDefaultAppGroup, func() error {
-			return app.DeployApps(captenConfig, globalValues, captenConfig.DefaultAppGroupsFileName)
-		})
+		appConfig, err := app.DeployApp(captenConfig, globalValues, appName, version, setValues)
 		if err != nil {
 			clog.Logger.Errorf("%v", err)
 			return
 		}
 
-		err = execActionIfEnabled(actions.Actions.FetchLoadBalancerHost, func() error {
+		if captenConfig.AppDeployDryRun {
+			return
+		}
+		if err := agent.SyncAppOnAgent(captenConfig, appConfig); err != nil {
+			clog.Logger.Errorf("failed to sync %s app with cluster agent, %v", appName, err)
+			return
+		}
+		clog.Logger.Infof("Application %s synchronized with Cluster Agent", appName)
+	},
+}
 
-			natslbhostname, err := k8s.FetchClusterLoadBalancerHost(captenConfig.PrepareFilePath(captenConfig.ConfigDirPath,
-				captenConfig.KubeConfigFileName), "observability", captenConfig.NatsLBServiceName)
+var appsDiffSubCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "show changes to capten stack apps on cluster before upgrading them",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		appName, _ := cmd.Flags().GetString("app-name")
+		group, _ := cmd.Flags().GetString("group")
+		if (len(appName) == 0) == (len(group) == 0) {
+			clog.Logger.Error("specify either app-name or group in the command line")
+			return
+		}
 
-			if err != nil {
-				clog.Logger.Error("failed to get NatsLoadBalancerService ", err)
-			}
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
 
-			err = retry(10, 30*time.Second, func() error {
-				if err := config.UpdateLBEndpointFile(&captenConfig, "", natslbhostname); err != nil {
-					clog.Logger.Infof("LB is not updated in the capten_lb_endpoint.yaml ")
-					return errors.WithMessage(err, "failed to update LB ")
-				}
-				return nil
-			})
-			if err != nil {
-				return err
-			}
-			err = agent.StoreCredentials(captenConfig, globalValues)
-			if err != nil {
+		globalValues, err := app.PrepareGlobalVaules(captenConfig)
+		if err != nil {
+			clog.Logger.Errorf("applications values preparation failed, %v", err)
+			return
+		}
 
-				return errors.WithMessage(err, "failed to store lbip credentials")
+		var appDiffs []types.AppDiff
+		if len(appName) != 0 {
+			appDiff, diffErr := app.DiffApp(captenConfig, globalValues, appName)
+			if diffErr == nil {
+				appDiffs = append(appDiffs, appDiff)
+			}
+			err = diffErr
+		} else {
+			groupFile, groupErr := appGroupFileParameter(captenConfig, map[string]interface{}{"appGroup": group})
+			if groupErr != nil {
+				clog.Logger.Error(groupErr)
+				return
 			}
-			clog.Logger.Info("Fetched nats loadbalancer host and stored in vault")
-			return nil
-		})
+			appDiffs, err = app.DiffAppGroup(captenConfig, globalValues, groupFile)
+		}
+
+		for _, appDiff := range appDiffs {
+			fmt.Print(app.FormatAppDiff(appDiff))
+		}
 		if err != nil {
 			clog.Logger.Errorf("%v", err)
+		}
+	},
+}
+
+var appsUninstallSubCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "uninstall capten stack apps from cluster",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		appName, _ := cmd.Flags().GetString("app-name")
+		group, _ := cmd.Flags().GetString("group")
+		if (len(appName) == 0) == (len(group) == 0) {
+			clog.Logger.Error("specify either app-name or group in the command line")
 			return
 		}
 
-		err = execActionIfEnabled(actions.Actions.SynchApps, func() error {
-			clog.Logger.Info("Synchonizing Applications with Cluster Agent")
-			err = retry(12, 30*time.Second, func() error {
-				if err := agent.SyncInstalledAppConfigsOnAgent(captenConfig); err != nil {
-					clog.Logger.Infof("Capten Agent is not ready")
-					return errors.WithMessage(err, "failed to sync installed apps config in cluster")
-				}
-				return nil
-			})
-			if err != nil {
-				return err
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		var uninstalledApps []types.AppConfig
+		if len(appName) != 0 {
+			appConfig, uninstallErr := app.UninstallApp(captenConfig, appName)
+			if uninstallErr == nil {
+				uninstalledApps = append(uninstalledApps, appConfig)
 			}
-			clog.Logger.Info("Applications Synchonized with Cluster Agent")
-			return nil
-		})
+			err = uninstallErr
+		} else {
+			groupFile, groupErr := appGroupFileParameter(captenConfig, map[string]interface{}{"appGroup": group})
+			if groupErr != nil {
+				clog.Logger.Error(groupErr)
+				return
+			}
+			uninstalledApps, err = app.UninstallApps(captenConfig, groupFile)
+		}
 		if err != nil {
 			clog.Logger.Errorf("%v", err)
+		}
+
+		if len(uninstalledApps) == 0 || captenConfig.AppDeployDryRun {
+			return
+		}
+		if err := agent.SyncUninstalledAppsOnAgent(captenConfig, uninstalledApps); err != nil {
+			clog.Logger.Errorf("failed to sync uninstalled apps with cluster agent, %v", err)
+			return
+		}
+		clog.Logger.Info("Uninstalled applications synchronized with Cluster Agent")
+	},
+}
+
+var appsHistorySubCmd = &cobra.Command{
+	Use:   "history",
+	Short: "show release history of an app on cluster",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		appName, err := readAppsNameFlags(cmd)
+		if err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
 			return
 		}
 
+		revisions, err := app.GetAppHistory(captenConfig, appName)
+		if err != nil {
+			clog.Logger.Errorf("failed to fetch %s app history, %v", appName, err)
+			return
+		}
+
+		renderOutput(appRevisions(revisions))
+	},
+}
+
+var appsRollbackSubCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "rollback an app on cluster to a previous revision",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		appName, err := readAppsNameFlags(cmd)
+		if err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+		revision, _ := cmd.Flags().GetInt("revision")
+		if revision < 0 {
+			clog.Logger.Error("revision must not be negative")
+			return
+		}
+
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		appConfig, err := app.RollbackApp(captenConfig, appName, revision)
+		if err != nil {
+			clog.Logger.Errorf("failed to rollback %s app, %v", appName, err)
+			return
+		}
+
+		if captenConfig.AppDeployDryRun {
+			return
+		}
+		if err := agent.SyncAppOnAgent(captenConfig, appConfig); err != nil {
+			clog.Logger.Errorf("failed to sync %s app with cluster agent, %v", appName, err)
+			return
+		}
+		clog.Logger.Infof("Application %s synchronized with Cluster Agent", appName)
 	},
 }
 
@@ -248,10 +370,12 @@ var appsListSubCmd = &cobra.Command{
 			clog.Logger.Errorf("failed to read capten config, %v", err)
 			return
 		}
-		err = agent.ListClusterApplications(captenConfig)
+		apps, err := agent.ListClusterApplications(captenConfig)
 		if err != nil {
 			clog.Logger.Errorf("failed to fetch applications from capten cluster, %v", err)
+			return
 		}
+		renderOutput(apps)
 	},
 }
 
@@ -271,53 +395,11 @@ var appsShowSubCmd = &cobra.Command{
 			return
 		}
 
-		err = agent.ShowClusterAppData(captenConfig, appsName)
+		appData, err := agent.ShowClusterAppData(captenConfig, appsName)
 		if err != nil {
 			clog.Logger.Errorf("failed to fetch application from capten cluster, %v", err)
+			return
 		}
+		renderOutput(appData)
 	},
 }
-
-func loadSetupAppsActions(captenConfig config.CaptenConfig) (*SetupAppsActionList, error) {
-	actionsFile := captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.SetupAppsConfigFile)
-	yamlFile, err := os.ReadFile(actionsFile)
-	if err != nil {
-		return nil, err
-	}
-
-	var actions SetupAppsActionList
-	err = yaml.Unmarshal(yamlFile, &actions)
-	if err != nil {
-		return nil, err
-	}
-	return &actions, err
-}
-
-func isEnabled(actionConfig map[string]interface{}) bool {
-	enabledVal, ok := actionConfig["enabled"]
-	if !ok {
-		return false
-	}
-	enabled, ok := enabledVal.(bool)
-	if !ok {
-		return false
-	}
-	return enabled
-}
-
-func execActionIfEnabled(actionConfig map[string]interface{}, f func() error) error {
-	if isEnabled(actionConfig) {
-		return f()
-	}
-	return nil
-}
-
-func retry(retries int, interval time.Duration, f func() error) (err error) {
-	for i := 0; i <= retries; i++ {
-		if err = f(); err == nil {
-			return nil
-		}
-		time.Sleep(interval)
-	}
-	return
-}
@@ -4,7 +4,10 @@ import (
 	"capten/pkg/clog"
 	"capten/pkg/cluster"
 	"capten/pkg/config"
+	"capten/pkg/output"
 	"fmt"
+	"path/filepath"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -33,6 +36,23 @@ var clusterCreateSubCmd = &cobra.Command{
 			return
 		}
 
+		planFile, _ := cmd.Flags().GetString("plan-file")
+		if len(planFile) != 0 {
+			planFile, err = filepath.Abs(planFile)
+			if err != nil {
+				clog.Logger.Errorf("invalid plan file path, %v", err)
+				return
+			}
+
+			err = cluster.ApplyPlan(captenConfig, planFile)
+			if err != nil {
+				clog.Logger.Errorf("failed to create cluster from plan %s, %v", planFile, err)
+				return
+			}
+			clog.Logger.Info("Cluster Created")
+			return
+		}
+
 		err = cluster.Create(captenConfig)
 		if err != nil {
 			clog.Logger.Errorf("failed to create cluster, %v", err)
@@ -43,6 +63,50 @@ var clusterCreateSubCmd = &cobra.Command{
 	},
 }
 
+var clusterPlanSubCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "cluster plan operation",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		cloudService, clusterType, err := readAndValidClusterFlags(cmd)
+		if err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+		captenConfig.CloudService = cloudService
+		captenConfig.ClusterType = clusterType
+
+		planFile, _ := cmd.Flags().GetString("out")
+		if len(planFile) == 0 {
+			planFile = captenConfig.PrepareFilePath(captenConfig.TerraformTemplateDirPath, captenConfig.TerraformPlanFileName)
+		}
+		planFile, err = filepath.Abs(planFile)
+		if err != nil {
+			clog.Logger.Errorf("invalid plan file path, %v", err)
+			return
+		}
+
+		planSummary, err := cluster.Plan(captenConfig, planFile)
+		if err != nil {
+			clog.Logger.Errorf("failed to plan cluster, %v", err)
+			return
+		}
+
+		renderOutput(newClusterPlan(planFile, planSummary))
+		if output.IsTable() {
+			fmt.Println(color.New(color.FgGreen).Sprint("Plan:"),
+				fmt.Sprintf("%d to add, %d to change, %d to destroy", planSummary.Add, planSummary.Change, planSummary.Destroy))
+			fmt.Println(color.New(color.FgGreen).Sprint("Plan File:"), planFile)
+		}
+	},
+}
+
 var clusterDestroySubCmd = &cobra.Command{
 	Use:   "destroy",
 	Short: "cluster destroy operation",
@@ -69,6 +133,34 @@ var clusterDestroySubCmd = &cobra.Command{
 	},
 }
 
+var clusterStatusSubCmd = &cobra.Command{
+	Use:          "status",
+	Short:        "check the health of the cluster agent, vault, nodes, core apps and certs",
+	Long:         ``,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			return fmt.Errorf("failed to read capten config, %v", err)
+		}
+
+		warnDays, _ := cmd.Flags().GetInt("warn-days")
+		report := cluster.Health(captenConfig, time.Duration(warnDays)*24*time.Hour)
+		renderOutput(report)
+		if output.IsTable() {
+			if report.Status == cluster.HealthHealthy {
+				clog.Logger.Info(report.Summary())
+			} else {
+				clog.Logger.Warn(report.Summary())
+			}
+		}
+		if report.Failed() {
+			return fmt.Errorf("cluster health check failed")
+		}
+		return nil
+	},
+}
+
 var showClusterInfoSubCmd = &cobra.Command{
 	Use:   "info",
 	Short: "cluster show info",
@@ -79,7 +171,6 @@ var showClusterInfoSubCmd = &cobra.Command{
 			clog.Logger.Error("failed to read capten config", err)
 			return
 		}
-		fmt.Println(color.New(color.FgGreen).Sprint("Cluster LB Host:"), captenConfig.LoadBalancerHost)
-		fmt.Println(color.New(color.FgGreen).Sprint("Capten Agent Hostname:"), captenConfig.AgentHostName)
+		renderOutput(clusterInfo{LoadBalancerHost: captenConfig.LoadBalancerHost, AgentHostName: captenConfig.AgentHostName})
 	},
 }
@@ -236,9 +236,11 @@ var resourceListSubCmd = &cobra.Command{
 			clog.Logger.Errorf("failed to read capten config, %v", err)
 			return
 		}
-		err = agent.ListClusterResources(captenConfig, resourceType)
+		resources, err := agent.ListClusterResources(captenConfig, resourceType)
 		if err != nil {
 			clog.Logger.Errorf("failed to list cluster resources, %v", err)
+			return
 		}
+		renderOutput(resources)
 	},
 }
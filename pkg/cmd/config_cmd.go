@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"capten/pkg/clog"
+	"capten/pkg/config"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var configViewSubCmd = &cobra.Command{
+	Use:   "view",
+	Short: "view the effective capten config and the source of each value",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		values, err := config.GetCaptenConfigValues()
+		if values == nil {
+			clog.Logger.Errorf("failed to load capten config, %v", err)
+			return
+		}
+
+		renderOutput(configValues(values))
+
+		if err != nil {
+			clog.Logger.Error(err)
+		}
+	},
+}
+
+var configGetSubCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "get a value from the cluster values files",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			clog.Logger.Error("specify the config key in the command line")
+			return
+		}
+
+		value, err := config.GetValuesFileValue(args[0])
+		if err != nil {
+			clog.Logger.Errorf("failed to get config value, %v", err)
+			return
+		}
+		fmt.Println(value)
+	},
+}
+
+var configSetSubCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "set a value in the cluster values files",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			clog.Logger.Error("specify the config key and value in the command line")
+			return
+		}
+
+		filePath, err := config.SetValuesFileValue(args[0], args[1])
+		if err != nil {
+			clog.Logger.Errorf("failed to set config value, %v", err)
+			return
+		}
+		clog.Logger.Infof("Updated %s in %s", args[0], filePath)
+	},
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"capten/pkg/clog"
+	"capten/pkg/config"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func readContextNameArg(args []string) (string, error) {
+	if len(args) != 1 || len(args[0]) == 0 {
+		return "", fmt.Errorf("specify the name of the context in the command line")
+	}
+	return args[0], nil
+}
+
+var contextAddSubCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "add a cluster context",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, err := readContextNameArg(args)
+		if err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+
+		fromDir, _ := cmd.Flags().GetString("from-dir")
+		if empty, _ := cmd.Flags().GetBool("empty"); empty {
+			fromDir = ""
+		} else if len(fromDir) == 0 {
+			fromDir, err = os.Getwd()
+			if err != nil {
+				clog.Logger.Errorf("error getting current directory, %v", err)
+				return
+			}
+		}
+
+		contexts, err := config.LoadClusterContexts()
+		if err != nil {
+			clog.Logger.Errorf("failed to load contexts, %v", err)
+			return
+		}
+
+		clusterContext, err := contexts.Add(name, fromDir)
+		if err != nil {
+			clog.Logger.Errorf("failed to add context, %v", err)
+			return
+		}
+
+		if err := contexts.Save(); err != nil {
+			clog.Logger.Errorf("failed to save contexts, %v", err)
+			return
+		}
+		clog.Logger.Infof("Context %s added in %s", name, clusterContext.DirPath)
+	},
+}
+
+var contextUseSubCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "switch the current cluster context",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, err := readContextNameArg(args)
+		if err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+
+		contexts, err := config.LoadClusterContexts()
+		if err != nil {
+			clog.Logger.Errorf("failed to load contexts, %v", err)
+			return
+		}
+
+		if err := contexts.Use(name); err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+
+		if err := contexts.Save(); err != nil {
+			clog.Logger.Errorf("failed to save contexts, %v", err)
+			return
+		}
+		clog.Logger.Infof("Switched to context %s", name)
+	},
+}
+
+var contextListSubCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list cluster contexts",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		contexts, err := config.LoadClusterContexts()
+		if err != nil {
+			clog.Logger.Errorf("failed to load contexts, %v", err)
+			return
+		}
+
+		renderOutput(contextList(*contexts))
+	},
+}
+
+var contextDeleteSubCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "delete a cluster context and its files",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, err := readContextNameArg(args)
+		if err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+
+		contexts, err := config.LoadClusterContexts()
+		if err != nil {
+			clog.Logger.Errorf("failed to load contexts, %v", err)
+			return
+		}
+
+		if err := contexts.Delete(name); err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+
+		if err := contexts.Save(); err != nil {
+			clog.Logger.Errorf("failed to save contexts, %v", err)
+			return
+		}
+		clog.Logger.Infof("Context %s deleted", name)
+	},
+}
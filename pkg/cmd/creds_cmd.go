@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"capten/pkg/agent"
+	"capten/pkg/clog"
+	"capten/pkg/config"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func readCredentialRefArg(args []string) (agent.CredentialRef, error) {
+	if len(args) != 1 || len(args[0]) == 0 {
+		return agent.CredentialRef{}, fmt.Errorf("specify the credential as <type>/<entity>/<identifier> in the command line")
+	}
+	return agent.ParseCredentialRef(args[0])
+}
+
+func readCredentialValues(values, fromFiles []string) (map[string]string, error) {
+	credential := map[string]string{}
+	for _, value := range values {
+		key, keyValue, ok := strings.Cut(value, "=")
+		if !ok || len(key) == 0 {
+			return nil, fmt.Errorf("invalid credential value '%s', expected key=value", value)
+		}
+		credential[key] = keyValue
+	}
+	for _, fromFile := range fromFiles {
+		key, filePath, ok := strings.Cut(fromFile, "=")
+		if !ok || len(key) == 0 || len(filePath) == 0 {
+			return nil, fmt.Errorf("invalid credential file '%s', expected key=path", fromFile)
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credential file %s, %v", filePath, err)
+		}
+		credential[key] = string(data)
+	}
+	return credential, nil
+}
+
+var credsListSubCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the credentials stored by capten in vault",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		statuses, err := agent.ListCredentials(captenConfig)
+		if err != nil {
+			clog.Logger.Errorf("failed to list credentials, %v", err)
+			return
+		}
+		renderOutput(statuses)
+	},
+}
+
+var credsGetSubCmd = &cobra.Command{
+	Use:   "get <type>/<entity>/<identifier>",
+	Short: "show a credential stored in vault, the values are masked unless --reveal is set",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		ref, err := readCredentialRefArg(args)
+		if err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+		reveal, _ := cmd.Flags().GetBool("reveal")
+
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		credential, err := agent.GetCredential(captenConfig, ref)
+		if err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+		if !reveal {
+			credential = credential.Masked()
+		}
+		renderOutput(credential)
+	},
+}
+
+var credsPutSubCmd = &cobra.Command{
+	Use:   "put <type>/<entity>/<identifier>",
+	Short: "store a credential in vault",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		ref, err := readCredentialRefArg(args)
+		if err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+		values, _ := cmd.Flags().GetStringArray("value")
+		fromFiles, _ := cmd.Flags().GetStringArray("from-file")
+		merge, _ := cmd.Flags().GetBool("merge")
+
+		credential, err := readCredentialValues(values, fromFiles)
+		if err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		if err := agent.PutCredential(captenConfig, ref, credential, merge); err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+		clog.Logger.Infof("Credential %s stored", ref)
+	},
+}
+
+var credsDeleteSubCmd = &cobra.Command{
+	Use:   "delete <type>/<entity>/<identifier>",
+	Short: "delete a credential from vault",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		ref, err := readCredentialRefArg(args)
+		if err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		if err := agent.DeleteCredential(captenConfig, ref); err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+		clog.Logger.Infof("Credential %s deleted", ref)
+	},
+}
+
+var credsRotateSubCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "generate a new password or token for an app credential and update its secrets",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		if len(name) == 0 {
+			clog.Logger.Error("specify the name of the credential config with --name")
+			return
+		}
+		restart, _ := cmd.Flags().GetBool("restart")
+		force, _ := cmd.Flags().GetBool("force")
+
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		rotated, err := agent.RotateCredential(captenConfig, name, force)
+		if err != nil {
+			clog.Logger.Errorf("failed to rotate credential, %v", err)
+			return
+		}
+		clog.Logger.Infof("Credential %s rotated, secret %s configured in %s", rotated.Ref, rotated.SecretName,
+			strings.Join(rotated.Namespaces, ", "))
+
+		if !restart {
+			clog.Logger.Info("Workloads using the secret pick up the new value when restarted, use --restart to restart them")
+			return
+		}
+		restarted, err := agent.RestartCredentialConsumers(captenConfig, rotated)
+		for _, workload := range restarted {
+			clog.Logger.Infof("Restarted %s", workload)
+		}
+		if err != nil {
+			clog.Logger.Errorf("failed to restart workloads, %v", err)
+			return
+		}
+		if len(restarted) == 0 {
+			clog.Logger.Infof("No workloads use the secret %s", rotated.SecretName)
+		}
+	},
+}
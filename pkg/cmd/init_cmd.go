@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bufio"
+	"capten/pkg/clog"
+	"capten/pkg/cluster"
+	"capten/pkg/config"
+	"capten/pkg/workspace"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+type prompter struct {
+	cmd         *cobra.Command
+	reader      *bufio.Reader
+	out         io.Writer
+	interactive bool
+}
+
+// ask returns the flag value when the flag is set, otherwise prompts until a valid value is entered
+func (p *prompter) ask(flagName, label, defaultValue string, validate func(string) error) (string, error) {
+	if p.cmd.Flags().Changed(flagName) {
+		value, _ := p.cmd.Flags().GetString(flagName)
+		return value, validate(value)
+	}
+	if !p.interactive {
+		return defaultValue, validate(defaultValue)
+	}
+
+	for {
+		fmt.Fprintf(p.out, "%s [%s]: ", label, defaultValue)
+		line, err := p.reader.ReadString('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return "", fmt.Errorf("failed to read %s, %v", label, err)
+		}
+
+		value := strings.TrimSpace(line)
+		if len(value) == 0 {
+			value = defaultValue
+		}
+		if err := validate(value); err != nil {
+			fmt.Fprintf(p.out, "%v\n", err)
+			continue
+		}
+		return value, nil
+	}
+}
+
+// askCount is ask for the int count flags, the count has to be greater than 0
+func (p *prompter) askCount(flagName, label string, defaultValue int) (int, error) {
+	if p.cmd.Flags().Changed(flagName) {
+		count, _ := p.cmd.Flags().GetInt(flagName)
+		if count < 1 {
+			return 0, fmt.Errorf("%s must be greater than 0", flagName)
+		}
+		return count, nil
+	}
+
+	value, err := p.ask(flagName, label, strconv.Itoa(defaultValue), func(value string) error {
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 1 {
+			return fmt.Errorf("%s must be a number greater than 0", label)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(value)
+}
+
+func oneOf(label string, values ...string) func(string) error {
+	return func(value string) error {
+		for _, allowed := range values {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s", label, strings.Join(values, ", "))
+	}
+}
+
+func required(label string) func(string) error {
+	return func(value string) error {
+		if len(value) == 0 {
+			return fmt.Errorf("%s is required", label)
+		}
+		return nil
+	}
+}
+
+func readInitOptions(p *prompter) (options workspace.Options, err error) {
+	cloudService, err := p.ask("cloud", "Cloud service", "aws", oneOf("cloud service", cluster.SupportedCloudServices()...))
+	if err != nil {
+		return
+	}
+
+	options = workspace.DefaultOptions(cloudService)
+	if options.ClusterType, err = p.ask("type", "Cluster type", options.ClusterType, func(clusterType string) error {
+		return validateClusterFlags(cloudService, clusterType)
+	}); err != nil {
+		return
+	}
+	if options.ClusterName, err = p.ask("cluster-name", "Cluster name", options.ClusterName, workspace.ValidateClusterName); err != nil {
+		return
+	}
+	if options.DomainName, err = p.ask("domain", "Domain name", options.DomainName, func(domainName string) error {
+		return config.CaptenClusterValues{DomainName: domainName}.Validate()
+	}); err != nil {
+		return
+	}
+	if options.Region, err = p.ask("region", "Region", options.Region, required("region")); err != nil {
+		return
+	}
+	if options.InstanceType, err = p.ask("instance-type", "Instance type", options.InstanceType, required("instance type")); err != nil {
+		return
+	}
+	if options.MasterCount, err = p.askCount("master-count", "Master node count", options.MasterCount); err != nil {
+		return
+	}
+	if options.WorkerCount, err = p.askCount("worker-count", "Worker node count", options.WorkerCount); err != nil {
+		return
+	}
+
+	socialIntegration, err := p.ask("social-integration", "Social integration (none, slack, teams)", "none",
+		oneOf("social integration", "none", "slack", "teams"))
+	if err != nil {
+		return
+	}
+	switch socialIntegration {
+	case "slack":
+		options.SocialIntegration = socialIntegration
+		if options.SlackURL, err = p.ask("slack-url", "Slack webhook url", "", required("slack url")); err != nil {
+			return
+		}
+		if options.SlackChannel, err = p.ask("slack-channel", "Slack channel", "", required("slack channel")); err != nil {
+			return
+		}
+	case "teams":
+		options.SocialIntegration = socialIntegration
+		if options.TeamsURL, err = p.ask("teams-url", "Teams webhook url", "", required("teams url")); err != nil {
+			return
+		}
+	}
+	return options, options.Validate()
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "initialize a workspace with the cluster config files",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		nonInteractive, _ := cmd.Flags().GetBool("non-interactive")
+		options, err := readInitOptions(&prompter{
+			cmd:         cmd,
+			reader:      bufio.NewReader(os.Stdin),
+			out:         os.Stdout,
+			interactive: !nonInteractive,
+		})
+		if err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+
+		captenConfig, err := config.GetCaptenWorkspaceConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		files, err := workspace.Init(captenConfig, options, force)
+		if err != nil {
+			clog.Logger.Errorf("failed to initialize workspace, %v", err)
+			if errors.Is(err, workspace.ErrWorkspaceExists) {
+				clog.Logger.Info("Use --force to overwrite the existing workspace")
+			}
+			return
+		}
+
+		for _, file := range files {
+			clog.Logger.Infof("Created %s", file)
+		}
+		clog.Logger.Info("Workspace initialized")
+		if options.CloudService == "aws" {
+			clog.Logger.Info("The aws credentials are read from the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY env variables")
+		}
+	},
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"capten/pkg/clog"
+	"capten/pkg/config"
+	"capten/pkg/output"
+	"capten/pkg/setup"
+	"capten/pkg/types"
+	"strconv"
+)
+
+func renderOutput(data output.Tabular) {
+	if list, ok := data.(output.List); ok && output.IsTable() {
+		if _, rows := list.Table(false); len(rows) == 0 {
+			clog.Logger.Info(list.EmptyMessage())
+			return
+		}
+	}
+	if err := output.Render(data); err != nil {
+		clog.Logger.Errorf("failed to render output, %v", err)
+	}
+}
+
+type installSteps []setup.StepState
+
+func (steps installSteps) Table(wide bool) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, step := range steps {
+		rows = append(rows, []string{step.Name, step.Status, formatStepTime(step.StartedAt), formatStepTime(step.FinishedAt), step.Error})
+	}
+	return []string{"Step", "Status", "Started", "Finished", "Error"}, rows
+}
+
+func (steps installSteps) EmptyMessage() string {
+	return "No apps install steps executed"
+}
+
+type appRevisions []types.AppReleaseRevision
+
+func (revisions appRevisions) Table(wide bool) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, revision := range revisions {
+		rows = append(rows, []string{strconv.Itoa(revision.Revision), revision.ChartVersion, revision.AppVersion,
+			revision.Status, formatStepTime(revision.Updated), revision.Description})
+	}
+	return []string{"Revision", "Chart Version", "App Version", "Status", "Updated", "Description"}, rows
+}
+
+func (revisions appRevisions) EmptyMessage() string {
+	return "No release history found"
+}
+
+type contextList config.ClusterContexts
+
+func (contexts contextList) Table(wide bool) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, clusterContext := range contexts.Contexts {
+		current := ""
+		if clusterContext.Name == contexts.CurrentContext {
+			current = "*"
+		}
+		rows = append(rows, []string{current, clusterContext.Name, clusterContext.DirPath})
+	}
+	return []string{"Current", "Name", "Directory"}, rows
+}
+
+func (contexts contextList) EmptyMessage() string {
+	return "No contexts added"
+}
+
+type configValues []config.ConfigValue
+
+func (values configValues) Table(wide bool) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, configValue := range values {
+		rows = append(rows, []string{configValue.Name, configValue.EnvKey, configValue.Value, configValue.Source})
+	}
+	return []string{"Key", "Env", "Value", "Source"}, rows
+}
+
+type clusterResourceChange struct {
+	Address      string `json:"address" yaml:"address"`
+	ResourceType string `json:"resourceType" yaml:"resourceType"`
+	Action       string `json:"action" yaml:"action"`
+}
+
+type clusterPlan struct {
+	PlanFile        string                  `json:"planFile" yaml:"planFile"`
+	Add             int                     `json:"add" yaml:"add"`
+	Change          int                     `json:"change" yaml:"change"`
+	Destroy         int                     `json:"destroy" yaml:"destroy"`
+	ResourceChanges []clusterResourceChange `json:"resourceChanges" yaml:"resourceChanges"`
+}
+
+func newClusterPlan(planFile string, planSummary *types.ClusterPlanSummary) clusterPlan {
+	plan := clusterPlan{
+		PlanFile:        planFile,
+		Add:             planSummary.Add,
+		Change:          planSummary.Change,
+		Destroy:         planSummary.Destroy,
+		ResourceChanges: []clusterResourceChange{},
+	}
+	for _, resourceChange := range planSummary.ResourceChanges {
+		plan.ResourceChanges = append(plan.ResourceChanges, clusterResourceChange(resourceChange))
+	}
+	return plan
+}
+
+func (plan clusterPlan) Table(wide bool) ([]string, [][]string) {
+	rows := [][]string{}
+	for _, resourceChange := range plan.ResourceChanges {
+		rows = append(rows, []string{resourceChange.Address, resourceChange.ResourceType, resourceChange.Action})
+	}
+	return []string{"Resource", "Type", "Action"}, rows
+}
+
+func (plan clusterPlan) EmptyMessage() string {
+	return "No resource changes"
+}
+
+type clusterInfo struct {
+	LoadBalancerHost string `json:"loadBalancerHost" yaml:"loadBalancerHost"`
+	AgentHostName    string `json:"agentHostName" yaml:"agentHostName"`
+}
+
+func (info clusterInfo) Table(wide bool) ([]string, [][]string) {
+	return output.AttributesTable(output.Attributes{
+		{"cluster-lb-host", info.LoadBalancerHost},
+		{"capten-agent-hostname", info.AgentHostName},
+	})
+}
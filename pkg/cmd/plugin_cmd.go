@@ -184,11 +184,12 @@ var pluginListSubCmd = &cobra.Command{
 			clog.Logger.Errorf("failed to read capten config, %v", err)
 			return
 		}
-		err = agent.ListClusterPlugins(captenConfig)
+		plugins, err := agent.ListClusterPlugins(captenConfig)
 		if err != nil {
 			clog.Logger.Errorf("failed to list cluster plugins, %v", err)
 			return
 		}
+		renderOutput(plugins)
 	},
 }
 
@@ -234,10 +235,13 @@ var pluginConfigSubCmd = &cobra.Command{
 			return
 		}
 
-		err = agent.ConfigureClusterPlugin(captenConfig, pluginName, action, actionAttributes)
+		actionData, err := agent.ConfigureClusterPlugin(captenConfig, pluginName, action, actionAttributes)
 		if err != nil {
 			clog.Logger.Errorf("failed to show cluster plugin data, %v", err)
 			return
 		}
+		if actionData != nil {
+			renderOutput(actionData)
+		}
 	},
 }
@@ -70,11 +70,12 @@ var pluginStoreListSubCmd = &cobra.Command{
 			return
 		}
 
-		err = agent.ListPluginStoreApps(captenconfig, storeType)
+		plugins, err := agent.ListPluginStoreApps(captenconfig, storeType)
 		if err != nil {
 			clog.Logger.Errorf("failed to list plugin store apps, %v", err)
 			return
 		}
+		renderOutput(plugins)
 	},
 }
 
@@ -94,11 +95,12 @@ var pluginStoreShowSubCmd = &cobra.Command{
 			return
 		}
 
-		err = agent.ShowPluginStorePlugin(captenconfig, storeType, pluginName)
+		plugin, err := agent.ShowPluginStorePlugin(captenconfig, storeType, pluginName)
 		if err != nil {
-			clog.Logger.Errorf("failed to config plugin store, %v", err)
+			clog.Logger.Errorf("failed to show plugin store plugin, %v", err)
 			return
 		}
+		renderOutput(plugin)
 	},
 }
 
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"capten/pkg/cert"
+	"capten/pkg/clog"
+	"capten/pkg/config"
+
+	"github.com/spf13/cobra"
+)
+
+var profileExportSubCmd = &cobra.Command{
+	Use:   "export",
+	Short: "export the agent endpoint, CA cert and client cert of the cluster to a profile file",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := cert.ProfileExportOptions{}
+		opts.Name, _ = cmd.Flags().GetString("name")
+		opts.ClientName, _ = cmd.Flags().GetString("client")
+		opts.FilePath, _ = cmd.Flags().GetString("file")
+
+		captenConfig, err := config.GetCaptenConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		profileFilePath, err := cert.ExportProfile(captenConfig, opts)
+		if err != nil {
+			clog.Logger.Errorf("failed to export profile, %v", err)
+			return
+		}
+		clog.Logger.Infof("Profile exported to %s, it contains the client key, share it securely", profileFilePath)
+	},
+}
+
+var profileImportSubCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "add a cluster context from a profile file",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 || len(args[0]) == 0 {
+			clog.Logger.Error("specify the profile file in the command line")
+			return
+		}
+		contextName, _ := cmd.Flags().GetString("name")
+		use, _ := cmd.Flags().GetBool("use")
+
+		captenConfig, err := config.GetCaptenWorkspaceConfig()
+		if err != nil {
+			clog.Logger.Errorf("failed to read capten config, %v", err)
+			return
+		}
+
+		profile, err := cert.LoadProfile(args[0])
+		if err != nil {
+			clog.Logger.Errorf("failed to load profile, %v", err)
+			return
+		}
+
+		clusterContext, err := cert.ImportProfile(captenConfig, profile, contextName)
+		if err != nil {
+			clog.Logger.Errorf("failed to import profile, %v", err)
+			return
+		}
+		clog.Logger.Infof("Context %s added in %s", clusterContext.Name, clusterContext.DirPath)
+
+		if !use {
+			return
+		}
+		contexts, err := config.LoadClusterContexts()
+		if err != nil {
+			clog.Logger.Errorf("failed to load contexts, %v", err)
+			return
+		}
+		if err := contexts.Use(clusterContext.Name); err != nil {
+			clog.Logger.Error(err)
+			return
+		}
+		if err := contexts.Save(); err != nil {
+			clog.Logger.Errorf("failed to save contexts, %v", err)
+			return
+		}
+		clog.Logger.Infof("Switched to context %s", clusterContext.Name)
+	},
+}
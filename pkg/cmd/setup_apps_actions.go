@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"capten/pkg/agent"
+	"capten/pkg/app"
+	"capten/pkg/cert"
+	"capten/pkg/clog"
+	"capten/pkg/config"
+	"capten/pkg/k8s"
+	"capten/pkg/setup"
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+func setupAppsActions(captenConfig *config.CaptenConfig, globalValues map[string]interface{}) map[string]setup.ActionFunc {
+	return map[string]setup.ActionFunc{
+		"create-namespace": func(ctx context.Context, parameters map[string]interface{}) error {
+			namespace := stringParameter(parameters, "namespace", captenConfig.CaptenNamespace)
+			kubeconfigPath := captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.KubeConfigFileName)
+			if err := k8s.CreateNamespaceIfNotExists(kubeconfigPath, namespace); err != nil {
+				return errors.WithMessagef(err, "%s namespace creation failed", namespace)
+			}
+			return nil
+		},
+		"install-app-group": func(ctx context.Context, parameters map[string]interface{}) error {
+			groupFile, err := appGroupFileParameter(*captenConfig, parameters)
+			if err != nil {
+				return err
+			}
+			return app.DeployApps(ctx, *captenConfig, globalValues, groupFile)
+		},
+		"update-lb-endpoint": func(ctx context.Context, parameters map[string]interface{}) error {
+			return updateLBEndpoint(captenConfig, globalValues, parameters)
+		},
+		"configure-agent-certs": func(ctx context.Context, parameters map[string]interface{}) error {
+			if err := cert.PrepareCerts(*captenConfig); err != nil {
+				return errors.WithMessage(err, "failed to generate certificate")
+			}
+			if err := cert.PublishCerts(*captenConfig); err != nil {
+				return errors.WithMessage(err, "failed to create secret for certs")
+			}
+			clog.Logger.Info("Configured Certificates for Cluster Agent")
+			return nil
+		},
+		"configure-cert-issuer": func(ctx context.Context, parameters map[string]interface{}) error {
+			if err := cert.ConfigureClusterIssuer(*captenConfig); err != nil {
+				return errors.WithMessage(err, "failed to create cluster issuer")
+			}
+			clog.Logger.Info("Configured Certificate Issuer on Cluster")
+			return nil
+		},
+		"configure-cstor-pool": func(ctx context.Context, parameters map[string]interface{}) error {
+			if err := k8s.CreateCStorPoolClusterWithRetries(*captenConfig); err != nil {
+				return errors.WithMessage(err, "failed to configure storage pool")
+			}
+			clog.Logger.Info("Configured storage pool")
+			return nil
+		},
+		"store-cluster-credentials": func(ctx context.Context, parameters map[string]interface{}) error {
+			clog.Logger.Info("Storing credentails on cluster")
+			if err := agent.StoreCredentials(*captenConfig, globalValues); err != nil {
+				return errors.WithMessage(err, "failed to store credentials")
+			}
+			if captenConfig.CloudService == "aws" {
+				if err := agent.StoreClusterCredentials(*captenConfig, globalValues); err != nil {
+					return errors.WithMessage(err, "failed to store cluster credentials")
+				}
+			}
+			clog.Logger.Info("Stored credentails on cluster")
+			return nil
+		},
+		"synch-apps": func(ctx context.Context, parameters map[string]interface{}) error {
+			clog.Logger.Info("Synchonizing Applications with Cluster Agent")
+			if err := agent.SyncInstalledAppConfigsOnAgent(*captenConfig); err != nil {
+				return errors.WithMessage(err, "failed to sync installed apps config in cluster")
+			}
+			clog.Logger.Info("Applications Synchonized with Cluster Agent")
+			return nil
+		},
+	}
+}
+
+func renderSetupAppGroups(captenConfig config.CaptenConfig, globalValues map[string]interface{},
+	stepConfigs []setup.StepConfig, outputDir string) error {
+	for _, stepConfig := range stepConfigs {
+		if stepConfig.Action != "install-app-group" || (stepConfig.Enabled != nil && !*stepConfig.Enabled) {
+			continue
+		}
+
+		groupFile, err := appGroupFileParameter(captenConfig, stepConfig.Parameters)
+		if err != nil {
+			return errors.WithMessagef(err, "step %s", stepConfig.Name)
+		}
+
+		clog.Logger.Infof("[step: %s] rendering apps of %s", stepConfig.Name, groupFile)
+		if err := app.RenderAppGroup(captenConfig, globalValues, groupFile, outputDir); err != nil {
+			return errors.WithMessagef(err, "step %s", stepConfig.Name)
+		}
+	}
+	return nil
+}
+
+// diffSetupAppGroups prints the diffs of the apps of each enabled install-app-group step, all diffs are
+// computed and printed before any step runs so that the install output is not mixed in
+func diffSetupAppGroups(captenConfig config.CaptenConfig, globalValues map[string]interface{}, stepConfigs []setup.StepConfig) error {
+	for _, stepConfig := range stepConfigs {
+		if stepConfig.Action != "install-app-group" || (stepConfig.Enabled != nil && !*stepConfig.Enabled) {
+			continue
+		}
+
+		groupFile, err := appGroupFileParameter(captenConfig, stepConfig.Parameters)
+		if err != nil {
+			return errors.WithMessagef(err, "step %s", stepConfig.Name)
+		}
+
+		appDiffs, err := app.DiffAppGroup(captenConfig, globalValues, groupFile)
+		for _, appDiff := range appDiffs {
+			fmt.Print(app.FormatAppDiff(appDiff))
+		}
+		if err != nil {
+			return errors.WithMessagef(err, "step %s", stepConfig.Name)
+		}
+	}
+	return nil
+}
+
+func updateLBEndpoint(captenConfig *config.CaptenConfig, globalValues map[string]interface{}, parameters map[string]interface{}) error {
+	kubeconfigPath := captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.KubeConfigFileName)
+	endpoint := stringParameter(parameters, "endpoint", "agent")
+	switch endpoint {
+	case "agent":
+		namespace := stringParameter(parameters, "namespace", "traefik")
+		serviceName := stringParameter(parameters, "serviceName", captenConfig.LBServiceName)
+		lbHostName, err := k8s.FetchClusterLoadBalancerHost(kubeconfigPath, namespace, serviceName)
+		if err != nil {
+			return errors.WithMessage(err, "failed to get LoadBalancerService")
+		}
+
+		if err := config.UpdateLBEndpointFile(captenConfig, lbHostName, ""); err != nil {
+			return errors.WithMessage(err, "failed to update LB")
+		}
+		clog.Logger.Info("Fetched cluster agent address")
+	case "nats":
+		namespace := stringParameter(parameters, "namespace", "observability")
+		serviceName := stringParameter(parameters, "serviceName", captenConfig.NatsLBServiceName)
+		natsLBHostName, err := k8s.FetchClusterLoadBalancerHost(kubeconfigPath, namespace, serviceName)
+		if err != nil {
+			return errors.WithMessage(err, "failed to get NatsLoadBalancerService")
+		}
+
+		if err := config.UpdateLBEndpointFile(captenConfig, "", natsLBHostName); err != nil {
+			return errors.WithMessage(err, "failed to update LB")
+		}
+		clog.Logger.Info("Fetched nats loadbalancer host")
+	default:
+		return fmt.Errorf("unknown lb endpoint '%s', supported endpoints: agent, nats", endpoint)
+	}
+
+	if storeCredentials, _ := parameters["storeCredentials"].(bool); storeCredentials {
+		if err := agent.StoreCredentials(*captenConfig, globalValues); err != nil {
+			return errors.WithMessage(err, "failed to store lb credentials")
+		}
+		clog.Logger.Infof("Stored %s loadbalancer host in vault", endpoint)
+	}
+	return nil
+}
+
+func appGroupFileParameter(captenConfig config.CaptenConfig, parameters map[string]interface{}) (string, error) {
+	if groupFile := stringParameter(parameters, "groupFile", ""); len(groupFile) != 0 {
+		return groupFile, nil
+	}
+
+	appGroup := stringParameter(parameters, "appGroup", "")
+	switch appGroup {
+	case "core":
+		return captenConfig.CoreAppGroupsFileName, nil
+	case "default":
+		return captenConfig.DefaultAppGroupsFileName, nil
+	default:
+		return "", fmt.Errorf("unknown app group '%s', specify appGroup (core, default) or groupFile parameter", appGroup)
+	}
+}
+
+func stringParameter(parameters map[string]interface{}, key, defaultValue string) string {
+	value, ok := parameters[key].(string)
+	if !ok || len(value) == 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -8,7 +8,6 @@ import (
 
 	"capten/pkg/clog"
 
-	"github.com/kelseyhightower/envconfig"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 )
@@ -27,6 +26,7 @@ type CaptenConfig struct {
 	InterCAKeyFileName             string   `envconfig:"INTER_CERT_KEY_FILE_NAME" default:"inter-ca.key"`
 	AgentCertSecretName            string   `envconfig:"AGENT_CERT_SECRET_NAME" default:"kad-agent-cert"`
 	AgentCACertSecretName          string   `envconfig:"AGENT_CA_CERT_SECRET_NAME" default:"kad-agent-ca-cert"`
+	ClientCertSecretName           string   `envconfig:"CLIENT_CERT_SECRET_NAME" default:"capten-client-cert"`
 	AppsDirPath                    string   `envconfig:"APPS_DIR_PATH" default:"/apps/"`
 	AppsConfigDirPath              string   `envconfig:"APPS_CONFIG_DIR_PATH" default:"/apps/conf/"`
 	AppsCredentialDirPath          string   `envconfig:"APPS_CREDENTIAL_DIR_PATH" default:"credentials/"`
@@ -42,36 +42,56 @@ type CaptenConfig struct {
 	DefaultAppGroupsFileName       string   `envconfig:"DEFAULT_APP_GROUPS_FILE_NAME" default:"default_group_apps.yaml"`
 	CaptenGlobalValuesFileName     string   `envconfig:"CAPTEN_VALUES_FILE_PATH" default:"capten.yaml"`
 	CaptenHostValuesFileName       string   `envconfig:"CAPTEN_HOST_FILE_PATH" default:"capten-lb-endpoint.yaml"`
+	CaptenAgentValuesFileName      string   `envconfig:"CAPTEN_AGENT_FILE_PATH" default:"capten-agent.yaml"`
 	KubeConfigFileName             string   `envconfig:"KUBE_CONFIG_PATH" default:"kubeconfig"`
 	AWSTerraformTemplateFileName   string   `envconfig:"TERRAFORM_TEMPLATE_FILE_NAME" default:"values.aws.tmpl"`
 	TerraformVarFileName           string   `envconfig:"TERRAFORM_VAR_FILE_NAME" default:"values.tfvars"`
+	TerraformPlanFileName          string   `envconfig:"TERRAFORM_PLAN_FILE_NAME" default:"cluster.tfplan"`
 	AgentCertFileName              string   `envconfig:"AGENT_CERT_FILE_NAME" default:"agent.crt"`
 	AgentKeyFileName               string   `envconfig:"AGENT_KEY_FILE_NAME" default:"agent.key"`
 	ClientCertFileName             string   `envconfig:"CLIENT_CERT_FILE_NAME" default:"client.crt"`
 	ClientKeyFileName              string   `envconfig:"CLIENT_KEY_FILE_NAME" default:"client.key"`
 	CAFileName                     string   `envconfig:"CA_FILE_NAME" default:"ca.crt"`
 	ClientCertExportFileName       string   `envconfig:"CLIENT_CERT_EXPORT_FILE_NAME" default:"capten-client-auth-certs.zip"`
+	CRLFileName                    string   `envconfig:"CRL_FILE_NAME" default:"ca.crl"`
 	OrgName                        string   `envconfig:"ORG_NAME" default:"Intelops"`
 	RootCACommonName               string   `envconfig:"ROOT_CA_CN" default:"Capten Root CA"`
 	IntermediateCACommonName       string   `envconfig:"INTERMEDIATE_CA_CN" default:"Capten Cluster CA"`
 	AgentCertCommonName            string   `envconfig:"AGENT_CERT_CN" default:"Capten Agent"`
 	AgentDNSNamePrefixes           []string `envconfig:"AGENT_DNS_NAME_PREFIX" default:"*,vault-cred,agent"`
 	CaptenClientCertCommonName     string   `envconfig:"CAPTEN_CLIENT_CA_CN" default:"Capten Client"`
+	CertKeyAlgorithm               string   `envconfig:"CERT_KEY_ALGORITHM" default:"rsa"`
+	CertCAKeySize                  int      `envconfig:"CERT_CA_KEY_SIZE" default:"0"`
+	CertKeySize                    int      `envconfig:"CERT_KEY_SIZE" default:"0"`
+	RootCAValidityDays             int      `envconfig:"ROOT_CA_VALIDITY_DAYS" default:"1825"`
+	IntermediateCAValidityDays     int      `envconfig:"INTERMEDIATE_CA_VALIDITY_DAYS" default:"730"`
+	CertValidityDays               int      `envconfig:"CERT_VALIDITY_DAYS" default:"365"`
 	AppDeployDryRun                bool     `envconfig:"APP_DEPLOY_DRYRUN" default:"false"`
 	AppDeployDebug                 bool     `envconfig:"APP_DEPLOY_DEBUG" default:"false"`
+	AppDeployWorkers               int      `envconfig:"APP_DEPLOY_WORKERS" default:"4"`
 	ForceGenerateCerts             bool     `envconfig:"FORCE_GENERATE_CERTS" default:"false"`
+	ImportCAChainFilePath          string   `envconfig:"IMPORT_CA_CHAIN_FILE"`
+	ImportCACertFilePath           string   `envconfig:"IMPORT_CA_CERT_FILE"`
+	ImportCAKeyFilePath            string   `envconfig:"IMPORT_CA_KEY_FILE"`
+	ImportAgentCertFilePath        string   `envconfig:"IMPORT_AGENT_CERT_FILE"`
+	ImportAgentKeyFilePath         string   `envconfig:"IMPORT_AGENT_KEY_FILE"`
+	ImportClientCertFilePath       string   `envconfig:"IMPORT_CLIENT_CERT_FILE"`
+	ImportClientKeyFilePath        string   `envconfig:"IMPORT_CLIENT_KEY_FILE"`
 	UpgradeAppIfInstalled          bool     `envconfig:"UPGRADE_APP_IF_INSTALLED" default:"false"`
 	TerraformInitReconfigure       bool     `envconfig:"TERRAFORM_INIT_RECONFIGURE" default:"true"`
 	TerraformInitUpgrade           bool     `envconfig:"TERRAFORM_INIT_UPGRADE" default:"true"`
 	AgentDNSNames                  []string
 	CurrentDirPath                 string
+	ContextName                    string
+	ContextDirPath                 string
 	PoolClusterName                string `envconfig:"POOL_CLUSTER_NAME" default:"cstor-disk-pool"`
 	PoolClusterNamespace           string `envconfig:"POOL_CLUSTER_NAMESPACE" default:"openebs-cstor"`
 	SetupAppsConfigFile            string `envconfig:"SETUP_APPS_CONFIG_FILE" default:"setup_apps.yaml"`
-	AzureTerraformTemplateFileName string `envconfig:"TERRAFORM_TEMPLATE_FILE_NAME" default:"values.azure.tmpl"`
-	VaultCredWaitTime              int    `envconfig:"SETUP_APPS_CONFIG_FILE" default:"300"`
-	LBServiceName                  string `envconfig:"LBSERVICE-NAME" default:"traefik"`
-	NatsLBServiceName              string `envconfig:"NATS-LBSERVICE-NAME" default:"kubviz-client-nats-external"`
+	SetupAppsStateFile             string `envconfig:"SETUP_APPS_STATE_FILE" default:"setup_apps_state.yaml"`
+	AzureTerraformTemplateFileName string `envconfig:"TERRAFORM_AZURE_TEMPLATE_FILE_NAME" default:"values.azure.tmpl"`
+	VaultCredWaitTime              int    `envconfig:"VAULT_CRED_WAIT_TIME" default:"300"`
+	LBServiceName                  string `envconfig:"LB_SERVICE_NAME" default:"traefik"`
+	NatsLBServiceName              string `envconfig:"NATS_LB_SERVICE_NAME" default:"kubviz-client-nats-external"`
 }
 
 type CaptenClusterValues struct {
@@ -79,6 +99,7 @@ type CaptenClusterValues struct {
 	CloudService      string `yaml:"CloudService" envconfig:"CLOUD_SERVICE"`
 	ClusterType       string `yaml:"ClusterType" envconfig:"CLUSTER_TYPE"`
 	ClusterCAIssuer   string `yaml:"ClusterCAIssuer" envconfig:"CLUSTER_CA_ISSUER" default:"capten-issuer"`
+	CertSource        string `yaml:"CertSource" envconfig:"CERT_SOURCE" default:"self-signed"`
 	SocialIntegration string `yaml:"SocialIntegration" envconfig:"SOCIAL_INTEGRATION"`
 	SlackURL          string `yaml:"SlackURL" envconfig:"SLACK_URL" `
 	SlackChannel      string `yaml:"SlackChannel" envconfig:"SLACK_CHANNEL"`
@@ -90,63 +111,42 @@ type CaptenClusterHost struct {
 	NatsLoadBalancerHost string `yaml:"NatsLoadBalancerHost" envconfig:"NATS_LB_HOST"`
 }
 
-func GetCaptenConfig() (CaptenConfig, error) {
-	cfg := CaptenConfig{}
-	var captenvalues CaptenClusterValues
-	var captenhostvalue CaptenClusterHost
-
-	err := envconfig.Process("", &cfg)
-	if err != nil {
-		return cfg, err
-	}
-	cfg.CurrentDirPath, err = os.Getwd()
-	if err != nil {
-		return cfg, errors.WithMessage(err, "error getting current directory")
-	}
-	err = addCurrentDirToPath(cfg.CurrentDirPath)
-	if err != nil {
-		return cfg, errors.WithMessage(err, "error adding current directory to env")
-	}
+// CaptenAgentValues are the agent settings of a cluster context, the values file is optional and
+// is written for the contexts imported from a profile, which reach an agent with other settings
+type CaptenAgentValues struct {
+	AgentHostName     string `yaml:"AgentHostName,omitempty"`
+	VaultCredHostName string `yaml:"VaultCredHostName,omitempty"`
+	AgentHostPort     string `yaml:"AgentHostPort,omitempty"`
+	AgentSecure       *bool  `yaml:"AgentSecure,omitempty"`
+}
 
-	values, err := GetCaptenClusterValues(cfg.PrepareFilePath(cfg.ConfigDirPath, cfg.CaptenGlobalValuesFileName), &captenvalues)
-	if err != nil {
-		return cfg, err
+func (v CaptenAgentValues) apply(cfg *CaptenConfig, source string, sources map[string]string) {
+	for _, value := range []struct {
+		name  string
+		value string
+		dest  *string
+	}{
+		{"AgentHostName", v.AgentHostName, &cfg.AgentHostName},
+		{"VaultCredHostName", v.VaultCredHostName, &cfg.VaultCredHostName},
+		{"AgentHostPort", v.AgentHostPort, &cfg.AgentHostPort},
+	} {
+		if len(value.value) != 0 {
+			*value.dest = value.value
+			sources[value.name] = source
+		}
+	}
+	if v.AgentSecure != nil {
+		cfg.AgentSecure = *v.AgentSecure
+		sources["AgentSecure"] = source
 	}
+}
 
-	hostvalue, err := GetCaptenClusterValues(cfg.PrepareFilePath(cfg.ConfigDirPath, cfg.CaptenHostValuesFileName), &captenhostvalue)
+func GetCaptenConfig() (CaptenConfig, error) {
+	cfg, _, err := loadCaptenConfig()
 	if err != nil {
 		return cfg, err
 	}
-	if len(values.(*CaptenClusterValues).DomainName) != 0 {
-		cfg.DomainName = values.(*CaptenClusterValues).DomainName
-	}
-	if len(values.(*CaptenClusterValues).CloudService) != 0 {
-		cfg.CloudService = values.(*CaptenClusterValues).CloudService
-	}
-	if len(values.(*CaptenClusterValues).ClusterType) != 0 {
-		cfg.ClusterType = values.(*CaptenClusterValues).ClusterType
-	}
-	if len(values.(*CaptenClusterValues).SlackChannel) != 0 {
-		cfg.SlackChannel = values.(*CaptenClusterValues).SlackChannel
-	}
-	if len(values.(*CaptenClusterValues).SlackURL) != 0 {
-		cfg.SlackURL = values.(*CaptenClusterValues).SlackURL
-	}
-	if len(values.(*CaptenClusterValues).SocialIntegration) != 0 {
-		cfg.SocialIntegration = values.(*CaptenClusterValues).SocialIntegration
-	}
-	if len(values.(*CaptenClusterValues).TeamsURL) != 0 {
-		cfg.TeamsURL = values.(*CaptenClusterValues).TeamsURL
-	}
-	if len(hostvalue.(*CaptenClusterHost).LoadBalancerHost) != 0 {
-		cfg.LoadBalancerHost = hostvalue.(*CaptenClusterHost).LoadBalancerHost
-	}
-
-	cfg.AgentDNSNames = []string{}
-	for _, prefixName := range cfg.AgentDNSNamePrefixes {
-		cfg.AgentDNSNames = append(cfg.AgentDNSNames, prefixName+"."+cfg.DomainName)
-	}
-	return cfg, err
+	return cfg, cfg.Validate()
 }
 
 func (c CaptenConfig) GetCaptenAgentEndpoint() string {
@@ -180,7 +180,12 @@ func GetClusterInfo(clusterInfoFilePath string) (types.AWSClusterInfo, error) {
 	if err != nil {
 		return values, errors.WithMessagef(err, "failed to unmarshal cluster info file, %s", clusterInfoFilePath)
 	}
-	return values, err
+
+	err = resolveSecretRefs(&values)
+	if err != nil {
+		return values, errors.WithMessagef(err, "failed to resolve secrets of cluster info file, %s", clusterInfoFilePath)
+	}
+	return values, nil
 }
 func GetClusterInfoAzure(clusterInfoFilePath string) (types.AzureClusterInfo, error) {
 	var values types.AzureClusterInfo
@@ -193,15 +198,42 @@ func GetClusterInfoAzure(clusterInfoFilePath string) (types.AzureClusterInfo, er
 	if err != nil {
 		return values, errors.WithMessagef(err, "failed to unmarshal cluster info file, %s", clusterInfoFilePath)
 	}
-	return values, err
+
+	err = resolveSecretRefs(&values)
+	if err != nil {
+		return values, errors.WithMessagef(err, "failed to resolve secrets of cluster info file, %s", clusterInfoFilePath)
+	}
+	return values, nil
 }
 
 func (c CaptenConfig) PrepareFilePath(dir, path string) string {
-	return fmt.Sprintf("%s%s%s", c.CurrentDirPath, dir, path)
+	return fmt.Sprintf("%s%s%s", c.baseDirPath(dir), dir, path)
 }
 
 func (c CaptenConfig) PrepareDirPath(dir string) string {
-	return fmt.Sprintf("%s%s", c.CurrentDirPath, dir)
+	return fmt.Sprintf("%s%s", c.baseDirPath(dir), dir)
+}
+
+func (c CaptenConfig) baseDirPath(dir string) string {
+	if len(c.ContextDirPath) == 0 {
+		return c.CurrentDirPath
+	}
+	for _, contextDir := range c.contextDirs() {
+		if dir == contextDir {
+			return c.ContextDirPath
+		}
+	}
+	return c.CurrentDirPath
+}
+
+func (c *CaptenConfig) applyClusterContext() error {
+	clusterContext, err := resolveClusterContext()
+	if err != nil {
+		return err
+	}
+	c.ContextName = clusterContext.Name
+	c.ContextDirPath = clusterContext.DirPath
+	return nil
 }
 
 func addCurrentDirToPath(dir string) error {
@@ -248,34 +280,10 @@ func UpdateClusterValues(cfg *CaptenConfig, cloudService, clusterType string) er
 }
 
 func GetCaptenHostConfig() (CaptenClusterHost, error) {
-	cfg := CaptenConfig{}
-	var captenhostvalue CaptenClusterHost
-	err := envconfig.Process("", &cfg)
-	if err != nil {
-		return cfg.CaptenClusterHost, err
-	}
-	cfg.CurrentDirPath, err = os.Getwd()
-	if err != nil {
-		return cfg.CaptenClusterHost, errors.WithMessage(err, "error getting current directory")
-	}
-	err = addCurrentDirToPath(cfg.CurrentDirPath)
-	if err != nil {
-		return cfg.CaptenClusterHost, errors.WithMessage(err, "error adding current directory to env")
-	}
-	hostvalue, err := GetCaptenClusterValues(cfg.PrepareFilePath(cfg.ConfigDirPath, cfg.CaptenHostValuesFileName), &captenhostvalue)
-	if err != nil {
-		return cfg.CaptenClusterHost, err
-	}
-
-	if len(hostvalue.(*CaptenClusterHost).LoadBalancerHost) != 0 {
-		cfg.LoadBalancerHost = hostvalue.(*CaptenClusterHost).LoadBalancerHost
-	}
-	if len(hostvalue.(*CaptenClusterHost).NatsLoadBalancerHost) != 0 {
-		cfg.NatsLoadBalancerHost = hostvalue.(*CaptenClusterHost).NatsLoadBalancerHost
-	}
-	return captenhostvalue, err
-
+	cfg, _, err := loadCaptenConfig()
+	return cfg.CaptenClusterHost, err
 }
+
 func UpdateLBEndpointFile(cfg *CaptenConfig, lbhostname string, natsLbHostName string) error {
 	// Read YAML file contents
 	hostValuesPath := cfg.PrepareFilePath(cfg.ConfigDirPath, cfg.CaptenHostValuesFileName)
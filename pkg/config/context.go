@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	contextsFileName = "contexts.yaml"
+	contextsDirName  = "contexts"
+)
+
+var selectedContext string
+
+type ClusterContext struct {
+	Name    string `json:"name" yaml:"name"`
+	DirPath string `json:"dirPath" yaml:"dirPath"`
+}
+
+type ClusterContexts struct {
+	CurrentContext string           `json:"currentContext" yaml:"currentContext"`
+	Contexts       []ClusterContext `json:"contexts" yaml:"contexts"`
+	filePath       string
+}
+
+func SetContext(name string) {
+	selectedContext = name
+}
+
+func CaptenHomeDir() (string, error) {
+	if homeDir := os.Getenv("CAPTEN_HOME"); len(homeDir) != 0 {
+		return homeDir, nil
+	}
+
+	userHomeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.WithMessage(err, "error getting user home directory")
+	}
+	return filepath.Join(userHomeDir, ".capten"), nil
+}
+
+func LoadClusterContexts() (*ClusterContexts, error) {
+	homeDir, err := CaptenHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	contexts := &ClusterContexts{filePath: filepath.Join(homeDir, contextsFileName)}
+	data, err := os.ReadFile(contexts.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return contexts, nil
+		}
+		return nil, errors.WithMessagef(err, "failed to read contexts file, %s", contexts.filePath)
+	}
+
+	err = yaml.Unmarshal(data, contexts)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to unmarshal contexts file, %s", contexts.filePath)
+	}
+	return contexts, nil
+}
+
+func (c *ClusterContexts) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.filePath), 0700); err != nil {
+		return errors.WithMessagef(err, "failed to create directory %s", filepath.Dir(c.filePath))
+	}
+
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return errors.WithMessage(err, "failed to marshal contexts")
+	}
+
+	err = os.WriteFile(c.filePath, data, 0600)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to write contexts file, %s", c.filePath)
+	}
+	return nil
+}
+
+func (c *ClusterContexts) Get(name string) (ClusterContext, bool) {
+	for _, clusterContext := range c.Contexts {
+		if clusterContext.Name == name {
+			return clusterContext, true
+		}
+	}
+	return ClusterContext{}, false
+}
+
+func (c *ClusterContexts) Add(name, sourceDirPath string) (ClusterContext, error) {
+	if len(name) == 0 || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return ClusterContext{}, fmt.Errorf("invalid context name '%s'", name)
+	}
+	if _, ok := c.Get(name); ok {
+		return ClusterContext{}, fmt.Errorf("context '%s' already exists", name)
+	}
+
+	cfg := CaptenConfig{}
+	if err := envconfig.Process("", &cfg); err != nil {
+		return ClusterContext{}, err
+	}
+
+	clusterContext := ClusterContext{
+		Name:    name,
+		DirPath: filepath.Join(filepath.Dir(c.filePath), contextsDirName, name),
+	}
+	for _, dir := range cfg.contextDirs() {
+		contextDir := filepath.Join(clusterContext.DirPath, dir)
+		if err := os.MkdirAll(contextDir, 0700); err != nil {
+			return ClusterContext{}, errors.WithMessagef(err, "failed to create directory %s", contextDir)
+		}
+
+		if len(sourceDirPath) == 0 {
+			continue
+		}
+		if err := copyDirFiles(filepath.Join(sourceDirPath, dir), contextDir); err != nil {
+			return ClusterContext{}, err
+		}
+	}
+
+	c.Contexts = append(c.Contexts, clusterContext)
+	if len(c.CurrentContext) == 0 {
+		c.CurrentContext = name
+	}
+	return clusterContext, nil
+}
+
+func (c *ClusterContexts) Use(name string) error {
+	if _, ok := c.Get(name); !ok {
+		return fmt.Errorf("context '%s' not found", name)
+	}
+	c.CurrentContext = name
+	return nil
+}
+
+func (c *ClusterContexts) Delete(name string) error {
+	clusterContext, ok := c.Get(name)
+	if !ok {
+		return fmt.Errorf("context '%s' not found", name)
+	}
+
+	if err := os.RemoveAll(clusterContext.DirPath); err != nil {
+		return errors.WithMessagef(err, "failed to remove context directory %s", clusterContext.DirPath)
+	}
+
+	contexts := []ClusterContext{}
+	for _, existing := range c.Contexts {
+		if existing.Name != name {
+			contexts = append(contexts, existing)
+		}
+	}
+	c.Contexts = contexts
+	if c.CurrentContext == name {
+		c.CurrentContext = ""
+	}
+	return nil
+}
+
+func resolveClusterContext() (ClusterContext, error) {
+	name := selectedContext
+	if len(name) == 0 {
+		name = os.Getenv("CAPTEN_CONTEXT")
+	}
+
+	contexts, err := LoadClusterContexts()
+	if err != nil {
+		return ClusterContext{}, err
+	}
+	if len(name) == 0 {
+		name = contexts.CurrentContext
+	}
+	if len(name) == 0 {
+		return ClusterContext{}, nil
+	}
+
+	clusterContext, ok := contexts.Get(name)
+	if !ok {
+		return ClusterContext{}, fmt.Errorf("context '%s' not found", name)
+	}
+	return clusterContext, nil
+}
+
+func (c CaptenConfig) contextDirs() []string {
+	return []string{c.ConfigDirPath, c.CertDirPath, c.AppsTempDirPath}
+}
+
+func copyDirFiles(sourceDir, destDir string) error {
+	entries, err := os.ReadDir(sourceDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.WithMessagef(err, "failed to read directory %s", sourceDir)
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if err := copyFile(filepath.Join(sourceDir, entry.Name()), filepath.Join(destDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(sourcePath, destPath string) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to open %s", sourcePath)
+	}
+	defer source.Close()
+
+	dest, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to create %s", destPath)
+	}
+	defer dest.Close()
+
+	if _, err := io.Copy(dest, source); err != nil {
+		return errors.WithMessagef(err, "failed to copy %s", sourcePath)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClusterContexts(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("CAPTEN_HOME", homeDir)
+	t.Setenv("CAPTEN_CONTEXT", "")
+
+	sourceDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(sourceDir, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "config", "kubeconfig"), []byte("dev"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	contexts, err := LoadClusterContexts()
+	if err != nil {
+		t.Fatalf("LoadClusterContexts() error = %v", err)
+	}
+	if _, err := contexts.Add("dev", sourceDir); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := contexts.Add("prod", ""); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := contexts.Add("dev", ""); err == nil {
+		t.Errorf("Add() expected error for duplicate context")
+	}
+	if err := contexts.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	clusterContext, err := resolveClusterContext()
+	if err != nil || clusterContext.Name != "dev" {
+		t.Fatalf("resolveClusterContext() = %v, %v, want dev", clusterContext, err)
+	}
+	captenConfig := CaptenConfig{ConfigDirPath: "/config/", CertDirPath: "/cert/", AppsDirPath: "/apps/",
+		CurrentDirPath: "/work", ContextDirPath: clusterContext.DirPath}
+	kubeconfig, err := os.ReadFile(captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, "kubeconfig"))
+	if err != nil || string(kubeconfig) != "dev" {
+		t.Errorf("context kubeconfig = %s, %v, want dev", kubeconfig, err)
+	}
+	if got := captenConfig.PrepareDirPath(captenConfig.AppsDirPath); got != "/work/apps/" {
+		t.Errorf("PrepareDirPath() = %s, want /work/apps/", got)
+	}
+
+	SetContext("prod")
+	clusterContext, err = resolveClusterContext()
+	SetContext("")
+	if err != nil || clusterContext.Name != "prod" {
+		t.Errorf("resolveClusterContext() = %v, %v, want prod", clusterContext, err)
+	}
+
+	contexts, err = LoadClusterContexts()
+	if err != nil {
+		t.Fatalf("LoadClusterContexts() error = %v", err)
+	}
+	if err := contexts.Use("stage"); err == nil {
+		t.Errorf("Use() expected error for unknown context")
+	}
+	if err := contexts.Delete("dev"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(contexts.CurrentContext) != 0 || len(contexts.Contexts) != 1 {
+		t.Errorf("Delete() left contexts %v, current %s", contexts.Contexts, contexts.CurrentContext)
+	}
+	if _, err := os.Stat(clusterContext.DirPath); err != nil {
+		t.Errorf("Delete() removed other context directory, %v", err)
+	}
+}
+
+func TestClusterContextsJSON(t *testing.T) {
+	contexts := ClusterContexts{
+		CurrentContext: "dev",
+		Contexts:       []ClusterContext{{Name: "dev", DirPath: "/capten/contexts/dev"}},
+		filePath:       "/capten/contexts.yaml",
+	}
+	data, err := json.Marshal(contexts)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"currentContext":"dev","contexts":[{"name":"dev","dirPath":"/capten/contexts/dev"}]}`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
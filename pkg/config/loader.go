@@ -0,0 +1,374 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+var (
+	flagOverrides      = map[string]string{}
+	flagOverridesErr   error
+	domainNameRegex    = regexp.MustCompile(`^(?i)([a-z0-9]([-a-z0-9]*[a-z0-9])?\.)+[a-z]{2,}$`)
+	validCloudServices = []string{"aws", "azure"}
+	// the cluster types of the app groups, cluster create, plan and destroy check the provisioners themselves
+	validClusterTypes   = []string{"talos", "cloud-managed"}
+	validIntegrations   = []string{"slack", "teams"}
+	validCertSources    = []string{"self-signed", "import", "cert-manager"}
+	validKeyAlgorithms  = []string{"rsa", "ecdsa", "ed25519"}
+	computedConfigField = []string{"AgentDNSNames", "CurrentDirPath", "ContextName", "ContextDirPath"}
+	contextDirFields    = []string{"ConfigDirPath", "CertDirPath", "AppsTempDirPath"}
+)
+
+type ConfigValue struct {
+	Name   string `json:"name" yaml:"name"`
+	EnvKey string `json:"envKey" yaml:"envKey"`
+	Value  string `json:"value" yaml:"value"`
+	Source string `json:"source" yaml:"source"`
+}
+
+type configField struct {
+	name         string
+	envKey       string
+	defaultValue string
+	hasDefault   bool
+	value        reflect.Value
+}
+
+func SetFlagOverrides(overrides []string) error {
+	flagOverrides, flagOverridesErr = map[string]string{}, nil
+	for _, override := range overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok || len(key) == 0 {
+			// kept so that loading the config fails instead of silently ignoring the override
+			flagOverridesErr = fmt.Errorf("invalid config override '%s', expected key=value", override)
+			return flagOverridesErr
+		}
+		flagOverrides[key] = value
+	}
+	return nil
+}
+
+func GetCaptenConfigValues() ([]ConfigValue, error) {
+	cfg, sources, err := loadCaptenConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	configValues := []ConfigValue{}
+	for _, field := range configFields(&cfg) {
+		configValues = append(configValues, ConfigValue{
+			Name:   field.name,
+			EnvKey: field.envKey,
+			Value:  formatFieldValue(field.value),
+			Source: sources[field.name],
+		})
+	}
+	return configValues, cfg.Validate()
+}
+
+func GetCaptenWorkspaceConfig() (CaptenConfig, error) {
+	cfg, _, err := loadCaptenConfigLayers(false)
+	return cfg, err
+}
+
+func loadCaptenConfig() (CaptenConfig, map[string]string, error) {
+	return loadCaptenConfigLayers(true)
+}
+
+func loadCaptenConfigLayers(readValuesFiles bool) (CaptenConfig, map[string]string, error) {
+	cfg := CaptenConfig{}
+	sources := map[string]string{}
+	if flagOverridesErr != nil {
+		return cfg, sources, flagOverridesErr
+	}
+	fields := configFields(&cfg)
+	if err := validateEnvKeys(fields); err != nil {
+		return cfg, sources, err
+	}
+
+	for _, field := range fields {
+		if !field.hasDefault {
+			continue
+		}
+		if err := setFieldValue(field.value, field.defaultValue); err != nil {
+			return cfg, sources, errors.WithMessagef(err, "invalid default value of %s", field.name)
+		}
+		sources[field.name] = SourceDefault
+	}
+
+	var err error
+	cfg.CurrentDirPath, err = os.Getwd()
+	if err != nil {
+		return cfg, sources, errors.WithMessage(err, "error getting current directory")
+	}
+	err = addCurrentDirToPath(cfg.CurrentDirPath)
+	if err != nil {
+		return cfg, sources, errors.WithMessage(err, "error adding current directory to env")
+	}
+
+	// directories of the values files are resolved before the files are read
+	if err := applyEnvValues(fields, sources, contextDirFields); err != nil {
+		return cfg, sources, err
+	}
+	if err := applyFlagOverrides(fields, sources, contextDirFields); err != nil {
+		return cfg, sources, err
+	}
+	err = cfg.applyClusterContext()
+	if err != nil {
+		return cfg, sources, err
+	}
+
+	if readValuesFiles {
+		clusterValuesPath := cfg.PrepareFilePath(cfg.ConfigDirPath, cfg.CaptenGlobalValuesFileName)
+		var clusterValues CaptenClusterValues
+		if _, err := GetCaptenClusterValues(clusterValuesPath, &clusterValues); err != nil {
+			return cfg, sources, err
+		}
+		applyFileValues(&cfg.CaptenClusterValues, &clusterValues, SourceFile+" ("+clusterValuesPath+")", sources)
+
+		hostValuesPath := cfg.PrepareFilePath(cfg.ConfigDirPath, cfg.CaptenHostValuesFileName)
+		var hostValues CaptenClusterHost
+		if _, err := GetCaptenClusterValues(hostValuesPath, &hostValues); err != nil {
+			return cfg, sources, err
+		}
+		applyFileValues(&cfg.CaptenClusterHost, &hostValues, SourceFile+" ("+hostValuesPath+")", sources)
+
+		agentValuesPath := cfg.PrepareFilePath(cfg.ConfigDirPath, cfg.CaptenAgentValuesFileName)
+		if _, err := os.Stat(agentValuesPath); err == nil {
+			var agentValues CaptenAgentValues
+			if _, err := GetCaptenClusterValues(agentValuesPath, &agentValues); err != nil {
+				return cfg, sources, err
+			}
+			agentValues.apply(&cfg, SourceFile+" ("+agentValuesPath+")", sources)
+		}
+	}
+
+	if err := applyEnvValues(fields, sources, nil); err != nil {
+		return cfg, sources, err
+	}
+
+	if err := applyFlagOverrides(fields, sources, nil); err != nil {
+		return cfg, sources, err
+	}
+
+	cfg.AgentDNSNames = []string{}
+	for _, prefixName := range cfg.AgentDNSNamePrefixes {
+		cfg.AgentDNSNames = append(cfg.AgentDNSNames, prefixName+"."+cfg.DomainName)
+	}
+	return cfg, sources, nil
+}
+
+func (v CaptenClusterValues) Validate() error {
+	return joinValidationErrors(v.validationErrors())
+}
+
+func (c CaptenConfig) Validate() error {
+	validationErrors := c.CaptenClusterValues.validationErrors()
+	if !strings.HasPrefix(c.AgentHostPort, ":") {
+		validationErrors = append(validationErrors, fmt.Sprintf("AgentHostPort '%s' must be in ':<port>' format", c.AgentHostPort))
+	}
+	if c.AppDeployWorkers < 0 {
+		validationErrors = append(validationErrors, "AppDeployWorkers must not be negative")
+	}
+	if c.VaultCredWaitTime < 0 {
+		validationErrors = append(validationErrors, "VaultCredWaitTime must not be negative")
+	}
+	if !slices.Contains(validKeyAlgorithms, c.CertKeyAlgorithm) {
+		validationErrors = append(validationErrors, fmt.Sprintf("CertKeyAlgorithm '%s' is not supported, supported algorithms: %s",
+			c.CertKeyAlgorithm, strings.Join(validKeyAlgorithms, ", ")))
+	}
+	if c.CertCAKeySize < 0 || c.CertKeySize < 0 {
+		validationErrors = append(validationErrors, "CertCAKeySize and CertKeySize must not be negative")
+	}
+	if c.RootCAValidityDays <= 0 || c.IntermediateCAValidityDays <= 0 || c.CertValidityDays <= 0 {
+		validationErrors = append(validationErrors, "RootCAValidityDays, IntermediateCAValidityDays and CertValidityDays must be positive")
+	} else if c.IntermediateCAValidityDays > c.RootCAValidityDays || c.CertValidityDays > c.IntermediateCAValidityDays {
+		validationErrors = append(validationErrors, "CertValidityDays must not exceed IntermediateCAValidityDays, which must not exceed RootCAValidityDays")
+	}
+
+	return joinValidationErrors(validationErrors)
+}
+
+func (v CaptenClusterValues) validationErrors() []string {
+	validationErrors := []string{}
+	if !domainNameRegex.MatchString(v.DomainName) {
+		validationErrors = append(validationErrors, fmt.Sprintf("DomainName '%s' is not a valid domain name", v.DomainName))
+	}
+	validationErrors = append(validationErrors, clusterTypeValidationErrors(v.CloudService, v.ClusterType)...)
+	if len(v.SocialIntegration) != 0 && !slices.Contains(validIntegrations, v.SocialIntegration) {
+		validationErrors = append(validationErrors, fmt.Sprintf("SocialIntegration '%s' is not supported, supported integrations: %s",
+			v.SocialIntegration, strings.Join(validIntegrations, ", ")))
+	}
+	if len(v.CertSource) != 0 && !slices.Contains(validCertSources, v.CertSource) {
+		validationErrors = append(validationErrors, fmt.Sprintf("CertSource '%s' is not supported, supported cert sources: %s",
+			v.CertSource, strings.Join(validCertSources, ", ")))
+	}
+	return validationErrors
+}
+
+func clusterTypeValidationErrors(cloudService, clusterType string) []string {
+	validationErrors := []string{}
+	if len(cloudService) != 0 && !slices.Contains(validCloudServices, cloudService) {
+		validationErrors = append(validationErrors, fmt.Sprintf("CloudService '%s' is not supported, supported cloud services: %s",
+			cloudService, strings.Join(validCloudServices, ", ")))
+	}
+	if len(clusterType) != 0 && !slices.Contains(validClusterTypes, clusterType) {
+		validationErrors = append(validationErrors, fmt.Sprintf("ClusterType '%s' is not supported, supported types: %s",
+			clusterType, strings.Join(validClusterTypes, ", ")))
+	}
+	return validationErrors
+}
+
+func joinValidationErrors(validationErrors []string) error {
+	if len(validationErrors) != 0 {
+		return fmt.Errorf("invalid capten config: %s", strings.Join(validationErrors, "; "))
+	}
+	return nil
+}
+
+func configFields(cfg *CaptenConfig) []configField {
+	fields := []configField{}
+	collectConfigFields(reflect.ValueOf(cfg).Elem(), &fields)
+	return fields
+}
+
+func collectConfigFields(structValue reflect.Value, fields *[]configField) {
+	structType := structValue.Type()
+	for index := 0; index < structType.NumField(); index++ {
+		fieldType := structType.Field(index)
+		if fieldType.Anonymous && fieldType.Type.Kind() == reflect.Struct {
+			collectConfigFields(structValue.Field(index), fields)
+			continue
+		}
+		if slices.Contains(computedConfigField, fieldType.Name) {
+			continue
+		}
+
+		defaultValue, hasDefault := fieldType.Tag.Lookup("default")
+		*fields = append(*fields, configField{
+			name:         fieldType.Name,
+			envKey:       fieldType.Tag.Get("envconfig"),
+			defaultValue: defaultValue,
+			hasDefault:   hasDefault,
+			value:        structValue.Field(index),
+		})
+	}
+}
+
+func validateEnvKeys(fields []configField) error {
+	envKeyFields := map[string]string{}
+	for _, field := range fields {
+		if len(field.envKey) == 0 {
+			continue
+		}
+		if existing, ok := envKeyFields[field.envKey]; ok {
+			return fmt.Errorf("env key %s is used by both %s and %s", field.envKey, existing, field.name)
+		}
+		envKeyFields[field.envKey] = field.name
+	}
+	return nil
+}
+
+func applyFileValues(dest, src interface{}, source string, sources map[string]string) {
+	destValue := reflect.ValueOf(dest).Elem()
+	srcValue := reflect.ValueOf(src).Elem()
+	for index := 0; index < srcValue.NumField(); index++ {
+		if srcValue.Field(index).IsZero() {
+			continue
+		}
+		destValue.Field(index).Set(srcValue.Field(index))
+		sources[srcValue.Type().Field(index).Name] = source
+	}
+}
+
+func applyEnvValues(fields []configField, sources map[string]string, onlyFields []string) error {
+	for _, field := range fields {
+		if len(field.envKey) == 0 || (onlyFields != nil && !slices.Contains(onlyFields, field.name)) {
+			continue
+		}
+		value, ok := os.LookupEnv(field.envKey)
+		if !ok {
+			continue
+		}
+		if err := setFieldValue(field.value, value); err != nil {
+			return errors.WithMessagef(err, "invalid value of env %s", field.envKey)
+		}
+		sources[field.name] = SourceEnv + " (" + field.envKey + ")"
+	}
+	return nil
+}
+
+func applyFlagOverrides(fields []configField, sources map[string]string, onlyFields []string) error {
+	for key, value := range flagOverrides {
+		index := slices.IndexFunc(fields, func(field configField) bool {
+			return field.name == key || (len(field.envKey) != 0 && field.envKey == key)
+		})
+		if index < 0 {
+			return fmt.Errorf("unknown config key '%s'", key)
+		}
+		if onlyFields != nil && !slices.Contains(onlyFields, fields[index].name) {
+			continue
+		}
+		if err := setFieldValue(fields[index].value, value); err != nil {
+			return errors.WithMessagef(err, "invalid value of config override %s", key)
+		}
+		sources[fields[index].name] = SourceFlag
+	}
+	return nil
+}
+
+func setFieldValue(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(boolValue)
+	case reflect.Int:
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(intValue))
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported config type %s", field.Type())
+		}
+		values := []string{}
+		if len(value) != 0 {
+			values = strings.Split(value, ",")
+		}
+		field.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported config type %s", field.Type())
+	}
+	return nil
+}
+
+func formatFieldValue(field reflect.Value) string {
+	if field.Kind() == reflect.Slice {
+		values := []string{}
+		for index := 0; index < field.Len(); index++ {
+			values = append(values, fmt.Sprintf("%v", field.Index(index).Interface()))
+		}
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprintf("%v", field.Interface())
+}
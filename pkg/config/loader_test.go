@@ -0,0 +1,143 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadCaptenConfig(t *testing.T) {
+	workDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workDir, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	clusterValues := "DomainName: file.example.com\nCloudService: aws\nClusterType: talos\n"
+	if err := os.WriteFile(filepath.Join(workDir, "config", "capten.yaml"), []byte(clusterValues), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, "config", "capten-lb-endpoint.yaml"), []byte("LoadBalancerHost: 10.0.0.1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, "config", "capten-agent.yaml"), []byte("AgentHostName: agent\nAgentSecure: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(workDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(currentDir) })
+	t.Setenv("PATH", os.Getenv("PATH"))
+	t.Setenv("CAPTEN_HOME", t.TempDir())
+	t.Setenv("CAPTEN_CONTEXT", "")
+	t.Setenv("CLUSTER_TYPE", "cloud-managed")
+	t.Setenv("APP_DEPLOY_WORKERS", "8")
+	t.Setenv("CLUSTER_LB_HOST", "10.0.0.2")
+
+	if err := SetFlagOverrides([]string{"APP_DEPLOY_WORKERS=2", "AgentDNSNamePrefixes=agent"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = SetFlagOverrides(nil) })
+
+	cfg, sources, err := loadCaptenConfig()
+	if err != nil {
+		t.Fatalf("loadCaptenConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		got        interface{}
+		want       interface{}
+		wantSource string
+	}{
+		{"AgentHostName", cfg.AgentHostName, "agent", SourceFile + " (" + filepath.Join(workDir, "config", "capten-agent.yaml") + ")"},
+		{"AgentSecure", cfg.AgentSecure, false, SourceFile + " (" + filepath.Join(workDir, "config", "capten-agent.yaml") + ")"},
+		{"AgentHostPort", cfg.AgentHostPort, ":443", SourceDefault},
+		{"DomainName", cfg.DomainName, "file.example.com", SourceFile + " (" + filepath.Join(workDir, "config", "capten.yaml") + ")"},
+		{"CloudService", cfg.CloudService, "aws", SourceFile + " (" + filepath.Join(workDir, "config", "capten.yaml") + ")"},
+		{"ClusterType", cfg.ClusterType, "cloud-managed", SourceEnv + " (CLUSTER_TYPE)"},
+		{"LoadBalancerHost", cfg.LoadBalancerHost, "10.0.0.2", SourceEnv + " (CLUSTER_LB_HOST)"},
+		{"AppDeployWorkers", cfg.AppDeployWorkers, 2, SourceFlag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+			}
+			if sources[tt.name] != tt.wantSource {
+				t.Errorf("%s source = %s, want %s", tt.name, sources[tt.name], tt.wantSource)
+			}
+		})
+	}
+	if len(cfg.AgentDNSNames) != 1 || cfg.AgentDNSNames[0] != "agent.file.example.com" {
+		t.Errorf("AgentDNSNames = %v, want [agent.file.example.com]", cfg.AgentDNSNames)
+	}
+
+	if err := SetFlagOverrides([]string{"UnknownKey=value"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := loadCaptenConfig(); err == nil {
+		t.Errorf("loadCaptenConfig() expected error for unknown config key")
+	}
+	if err := SetFlagOverrides([]string{"DomainName"}); err == nil {
+		t.Errorf("SetFlagOverrides() expected error for override without value")
+	}
+	if _, _, err := loadCaptenConfig(); err == nil {
+		t.Errorf("loadCaptenConfig() expected error for invalid config override")
+	}
+}
+
+func TestValidateEnvKeys(t *testing.T) {
+	if err := validateEnvKeys(configFields(&CaptenConfig{})); err != nil {
+		t.Errorf("validateEnvKeys() error = %v", err)
+	}
+	fields := []configField{{name: "First", envKey: "KEY"}, {name: "Second", envKey: "KEY"}}
+	if err := validateEnvKeys(fields); err == nil {
+		t.Errorf("validateEnvKeys() expected error for duplicated env key")
+	}
+}
+
+func TestCaptenConfig_Validate(t *testing.T) {
+	validConfig := CaptenConfig{
+		CaptenClusterValues:        CaptenClusterValues{DomainName: "dev.intelops.app", CloudService: "aws", ClusterType: "talos"},
+		AgentHostPort:              ":443",
+		CertKeyAlgorithm:           "rsa",
+		RootCAValidityDays:         1825,
+		IntermediateCAValidityDays: 730,
+		CertValidityDays:           365,
+	}
+
+	tests := []struct {
+		name    string
+		update  func(*CaptenConfig)
+		wantErr bool
+	}{
+		{"valid", func(c *CaptenConfig) {}, false},
+		{"empty cloud service", func(c *CaptenConfig) { c.CloudService = "" }, false},
+		{"invalid domain name", func(c *CaptenConfig) { c.DomainName = "dev_intelops" }, true},
+		{"unsupported cloud service", func(c *CaptenConfig) { c.CloudService = "gcp" }, true},
+		{"unsupported cluster type", func(c *CaptenConfig) { c.ClusterType = "k3s" }, true},
+		{"unsupported integration", func(c *CaptenConfig) { c.SocialIntegration = "discord" }, true},
+		{"unsupported cert source", func(c *CaptenConfig) { c.CertSource = "vault" }, true},
+		{"cert-manager cert source", func(c *CaptenConfig) { c.CertSource = "cert-manager" }, false},
+		{"invalid agent port", func(c *CaptenConfig) { c.AgentHostPort = "443" }, true},
+		{"negative workers", func(c *CaptenConfig) { c.AppDeployWorkers = -1 }, true},
+		{"ecdsa keys", func(c *CaptenConfig) { c.CertKeyAlgorithm, c.CertCAKeySize = "ecdsa", 384 }, false},
+		{"unsupported key algorithm", func(c *CaptenConfig) { c.CertKeyAlgorithm = "dsa" }, true},
+		{"negative key size", func(c *CaptenConfig) { c.CertKeySize = -1 }, true},
+		{"zero cert validity", func(c *CaptenConfig) { c.CertValidityDays = 0 }, true},
+		{"cert outliving intermediate CA", func(c *CaptenConfig) { c.CertValidityDays = 1000 }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig
+			tt.update(&cfg)
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
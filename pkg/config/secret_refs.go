@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var secretRefRegex = regexp.MustCompile(`\$\{(env|file):([^}]+)\}`)
+
+// resolveSecretRefs replaces ${env:NAME} and ${file:/path} references in the string fields
+// of the cluster info, the resolved values are only kept in memory
+func resolveSecretRefs(clusterInfo interface{}) error {
+	structValue := reflect.ValueOf(clusterInfo).Elem()
+	for index := 0; index < structValue.NumField(); index++ {
+		field := structValue.Field(index)
+		fieldName := structValue.Type().Field(index).Name
+		switch {
+		case field.Kind() == reflect.String:
+			value, err := resolveSecretRef(field.String())
+			if err != nil {
+				return errors.WithMessagef(err, "failed to resolve %s", fieldName)
+			}
+			field.SetString(value)
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+			for itemIndex := 0; itemIndex < field.Len(); itemIndex++ {
+				value, err := resolveSecretRef(field.Index(itemIndex).String())
+				if err != nil {
+					return errors.WithMessagef(err, "failed to resolve %s", fieldName)
+				}
+				field.Index(itemIndex).SetString(value)
+			}
+		}
+	}
+	return nil
+}
+
+func resolveSecretRef(value string) (string, error) {
+	var resolveErr error
+	resolved := secretRefRegex.ReplaceAllStringFunc(value, func(ref string) string {
+		match := secretRefRegex.FindStringSubmatch(ref)
+		switch match[1] {
+		case "env":
+			envValue, ok := os.LookupEnv(match[2])
+			if !ok {
+				resolveErr = fmt.Errorf("env %s is not set", match[2])
+			}
+			return envValue
+		default:
+			data, err := os.ReadFile(match[2])
+			if err != nil {
+				resolveErr = errors.WithMessagef(err, "failed to read secret file %s", match[2])
+			}
+			return strings.TrimRight(string(data), "\r\n")
+		}
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
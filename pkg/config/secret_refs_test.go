@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"capten/pkg/types"
+)
+
+func Test_resolveSecretRef(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, []byte("file-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CAPTEN_TEST_SECRET", "env-secret")
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"plain value", "accesskey", "accesskey", false},
+		{"env reference", "${env:CAPTEN_TEST_SECRET}", "env-secret", false},
+		{"file reference", "${file:" + secretFile + "}", "file-secret", false},
+		{"embedded reference", "secret_key=${env:CAPTEN_TEST_SECRET}", "secret_key=env-secret", false},
+		{"missing env", "${env:CAPTEN_TEST_MISSING_SECRET}", "", true},
+		{"missing file", "${file:" + secretFile + ".missing}", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveSecretRef(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveSecretRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveSecretRef() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetClusterInfo_SecretRefs(t *testing.T) {
+	t.Setenv("CAPTEN_TEST_ACCESS_KEY", "access")
+	t.Setenv("CAPTEN_TEST_SECRET_KEY", "secret")
+	clusterInfoFile := filepath.Join(t.TempDir(), "aws_config.yaml")
+	data := "AwsAccessKey: ${env:CAPTEN_TEST_ACCESS_KEY}\nAwsSecretKey: \"${env:CAPTEN_TEST_SECRET_KEY}\"\n" +
+		"TerraformBackendConfigs:\n  - bucket=${env:CAPTEN_TEST_ACCESS_KEY}-state\n"
+	if err := os.WriteFile(clusterInfoFile, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := GetClusterInfo(clusterInfoFile)
+	if err != nil {
+		t.Fatalf("GetClusterInfo() error = %v", err)
+	}
+	want := types.AWSClusterInfo{AwsAccessKey: "access", AwsSecretKey: "secret", TerraformBackendConfigs: []string{"bucket=access-state"}}
+	if got.AwsAccessKey != want.AwsAccessKey || got.AwsSecretKey != want.AwsSecretKey || got.TerraformBackendConfigs[0] != want.TerraformBackendConfigs[0] {
+		t.Errorf("GetClusterInfo() = %+v, want %+v", got, want)
+	}
+}
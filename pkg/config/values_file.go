@@ -0,0 +1,295 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"capten/pkg/types"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ClusterValuesFile = "cluster"
+	HostValuesFile    = "host"
+	AWSValuesFile     = "aws"
+	AzureValuesFile   = "azure"
+)
+
+type valuesFile struct {
+	name      string
+	filePath  string
+	valueType reflect.Type
+}
+
+type valuesFileKey struct {
+	file  valuesFile
+	field reflect.StructField
+	key   string
+}
+
+func (c CaptenConfig) valuesFiles() []valuesFile {
+	return []valuesFile{
+		{ClusterValuesFile, c.PrepareFilePath(c.ConfigDirPath, c.CaptenGlobalValuesFileName), reflect.TypeOf(CaptenClusterValues{})},
+		{HostValuesFile, c.PrepareFilePath(c.ConfigDirPath, c.CaptenHostValuesFileName), reflect.TypeOf(CaptenClusterHost{})},
+		{AWSValuesFile, c.PrepareFilePath(c.ConfigDirPath, AWSValuesFile+"_config.yaml"), reflect.TypeOf(types.AWSClusterInfo{})},
+		{AzureValuesFile, c.PrepareFilePath(c.ConfigDirPath, AzureValuesFile+"_config.yaml"), reflect.TypeOf(types.AzureClusterInfo{})},
+	}
+}
+
+func GetValuesFileValue(key string) (string, error) {
+	cfg, _, err := loadCaptenConfig()
+	if err != nil {
+		return "", err
+	}
+
+	fileKey, err := cfg.resolveValuesFileKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	values := reflect.New(fileKey.file.valueType)
+	if _, err := GetCaptenClusterValues(fileKey.file.filePath, values.Interface()); err != nil {
+		return "", err
+	}
+	return formatFieldValue(values.Elem().FieldByIndex(fileKey.field.Index)), nil
+}
+
+// SetValuesFileValue updates the key in its values file, the file is only written when the config
+// with the new value applied passes validation and the updated cloud cluster info is complete
+func SetValuesFileValue(key, value string) (string, error) {
+	cfg, _, err := loadCaptenConfig()
+	if err != nil {
+		return "", err
+	}
+
+	fileKey, err := cfg.resolveValuesFileKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	fieldValue := reflect.New(fileKey.field.Type).Elem()
+	if err := setFieldValue(fieldValue, value); err != nil {
+		return "", errors.WithMessagef(err, "invalid value for %s, expected %s", fileKey.key, fileKey.field.Type)
+	}
+
+	data, err := os.ReadFile(fileKey.file.filePath)
+	if err != nil {
+		return "", errors.WithMessagef(err, "failed to read values file, %s", fileKey.file.filePath)
+	}
+
+	data, err = setYamlValue(data, fileKey.key, fieldValue)
+	if err != nil {
+		return "", errors.WithMessagef(err, "failed to update values file, %s", fileKey.file.filePath)
+	}
+
+	if err := cfg.validateValuesFileUpdate(fileKey, fieldValue, data); err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(fileKey.file.filePath, data, 0644)
+	if err != nil {
+		return "", errors.WithMessagef(err, "failed to write values file, %s", fileKey.file.filePath)
+	}
+	return fileKey.file.filePath, nil
+}
+
+func (c CaptenConfig) validateValuesFileUpdate(fileKey valuesFileKey, fieldValue reflect.Value, data []byte) error {
+	values := reflect.New(fileKey.file.valueType)
+	if err := yaml.Unmarshal(data, values.Interface()); err != nil {
+		return errors.WithMessagef(err, "failed to unmarshal updated values file, %s", fileKey.file.filePath)
+	}
+
+	switch clusterInfo := values.Interface().(type) {
+	case *types.AWSClusterInfo:
+		if err := validateAWSClusterInfo(*clusterInfo); err != nil {
+			return err
+		}
+	case *types.AzureClusterInfo:
+		if err := validateAzureClusterInfo(*clusterInfo); err != nil {
+			return err
+		}
+	default:
+		// the cluster and host values are loaded into the config, the field is found as a promoted field
+		c.setConfigField(fileKey.field.Name, fieldValue)
+	}
+	return c.Validate()
+}
+
+func (c *CaptenConfig) setConfigField(name string, value reflect.Value) {
+	reflect.ValueOf(c).Elem().FieldByName(name).Set(value)
+}
+
+func validateAWSClusterInfo(info types.AWSClusterInfo) error {
+	validationErrors := clusterInfoValidationErrors(info.Region, info.InstanceType, info.CloudService, info.ClusterType)
+	for _, count := range []struct {
+		name  string
+		value string
+	}{
+		{"MasterCount", info.MasterCount},
+		{"WorkerCount", info.WorkerCount},
+	} {
+		if countValue, err := strconv.Atoi(count.value); err != nil || countValue < 1 {
+			validationErrors = append(validationErrors, fmt.Sprintf("%s '%s' must be a positive number", count.name, count.value))
+		}
+	}
+	if len(validationErrors) != 0 {
+		return fmt.Errorf("invalid aws cluster info: %s", strings.Join(validationErrors, "; "))
+	}
+	return nil
+}
+
+func validateAzureClusterInfo(info types.AzureClusterInfo) error {
+	validationErrors := clusterInfoValidationErrors(info.Region, info.InstanceType, info.CloudService, info.ClusterType)
+	if len(info.MasterCount) == 0 || len(info.WorkerCount) == 0 {
+		validationErrors = append(validationErrors, "MasterCount and WorkerCount must list at least one node")
+	}
+	if len(info.NICs) != len(info.MasterCount) || len(info.WorkerNics) != len(info.WorkerCount) {
+		validationErrors = append(validationErrors, "NICs and WorkerNics must list a nic for each master and worker node")
+	}
+	if len(validationErrors) != 0 {
+		return fmt.Errorf("invalid azure cluster info: %s", strings.Join(validationErrors, "; "))
+	}
+	return nil
+}
+
+func clusterInfoValidationErrors(region, instanceType, cloudService, clusterType string) []string {
+	validationErrors := []string{}
+	if len(region) == 0 {
+		validationErrors = append(validationErrors, "Region is required")
+	}
+	if len(instanceType) == 0 {
+		validationErrors = append(validationErrors, "InstanceType is required")
+	}
+	return append(validationErrors, clusterTypeValidationErrors(cloudService, clusterType)...)
+}
+
+// resolveValuesFileKey accepts either "<file>.<key>" or a plain key, a plain key present in
+// several files resolves to the cluster values file first and then to the current cloud service file
+func (c CaptenConfig) resolveValuesFileKey(key string) (valuesFileKey, error) {
+	fileName, fieldName, qualified := strings.Cut(key, ".")
+	if !qualified {
+		fileName, fieldName = "", key
+	}
+
+	matches := []valuesFileKey{}
+	for _, file := range c.valuesFiles() {
+		if qualified && file.name != fileName {
+			continue
+		}
+		if fileKey, ok := lookupValuesFileKey(file, fieldName); ok {
+			matches = append(matches, fileKey)
+		}
+	}
+
+	if len(matches) > 1 {
+		for _, preferred := range []string{ClusterValuesFile, c.CloudService} {
+			index := slices.IndexFunc(matches, func(match valuesFileKey) bool { return match.file.name == preferred })
+			if index >= 0 {
+				return matches[index], nil
+			}
+		}
+
+		qualifiedKeys := []string{}
+		for _, match := range matches {
+			qualifiedKeys = append(qualifiedKeys, match.file.name+"."+match.key)
+		}
+		return valuesFileKey{}, fmt.Errorf("config key '%s' is ambiguous, use one of %s", key, strings.Join(qualifiedKeys, ", "))
+	}
+	if len(matches) == 0 {
+		return valuesFileKey{}, fmt.Errorf("unknown config key '%s'", key)
+	}
+	return matches[0], nil
+}
+
+func lookupValuesFileKey(file valuesFile, name string) (valuesFileKey, bool) {
+	for index := 0; index < file.valueType.NumField(); index++ {
+		field := file.valueType.Field(index)
+		yamlKey := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if len(yamlKey) == 0 {
+			yamlKey = field.Name
+		}
+		if name == field.Name || name == yamlKey {
+			return valuesFileKey{file: file, field: field, key: yamlKey}, true
+		}
+	}
+	return valuesFileKey{}, false
+}
+
+// setYamlValue updates the key in place on the yaml node tree, so that comments
+// and the order of the other keys are kept as they are in the file
+func setYamlValue(data []byte, key string, value reflect.Value) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if document.Kind == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) != 1 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("values file is not a yaml mapping")
+	}
+
+	mapping := document.Content[0]
+	var valueNode *yaml.Node
+	for index := 0; index+1 < len(mapping.Content); index += 2 {
+		if mapping.Content[index].Value == key {
+			valueNode = mapping.Content[index+1]
+			break
+		}
+	}
+	if valueNode == nil {
+		valueNode = &yaml.Node{}
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, valueNode)
+	}
+	setYamlNodeValue(valueNode, value)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func setYamlNodeValue(node *yaml.Node, value reflect.Value) {
+	if value.Kind() == reflect.Slice {
+		itemStyle := yaml.Style(0)
+		if node.Kind == yaml.SequenceNode && len(node.Content) != 0 {
+			itemStyle = node.Content[0].Style
+		}
+		if node.Kind != yaml.SequenceNode {
+			node.Style = 0
+		}
+		node.Kind, node.Tag, node.Value = yaml.SequenceNode, "!!seq", ""
+		node.Content = []*yaml.Node{}
+		for index := 0; index < value.Len(); index++ {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str",
+				Value: value.Index(index).String(), Style: itemStyle})
+		}
+		return
+	}
+
+	style := node.Style
+	if node.Kind != yaml.ScalarNode || value.Kind() != reflect.String {
+		style = 0
+	}
+	tag := "!!str"
+	switch value.Kind() {
+	case reflect.Bool:
+		tag = "!!bool"
+	case reflect.Int:
+		tag = "!!int"
+	}
+	node.Kind, node.Tag, node.Value, node.Style, node.Content = yaml.ScalarNode, tag, formatFieldValue(value), style, nil
+}
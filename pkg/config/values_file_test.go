@@ -0,0 +1,170 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_setYamlValue(t *testing.T) {
+	data := `# cluster settings
+Region: "us-west-2" # aws region
+MasterCount: "1"
+TerraformBackendConfigs:
+  - "bucket=capten-talos-state"
+TraefikHttpPort: 32080
+`
+	tests := []struct {
+		name  string
+		key   string
+		value interface{}
+		want  string
+	}{
+		{
+			name:  "quoted string keeps comments and order",
+			key:   "Region",
+			value: "eu-west-1",
+			want: `# cluster settings
+Region: "eu-west-1" # aws region
+MasterCount: "1"
+TerraformBackendConfigs:
+  - "bucket=capten-talos-state"
+TraefikHttpPort: 32080
+`,
+		},
+		{
+			name:  "int value",
+			key:   "TraefikHttpPort",
+			value: 30080,
+			want: `# cluster settings
+Region: "us-west-2" # aws region
+MasterCount: "1"
+TerraformBackendConfigs:
+  - "bucket=capten-talos-state"
+TraefikHttpPort: 30080
+`,
+		},
+		{
+			name:  "list value",
+			key:   "TerraformBackendConfigs",
+			value: []string{"bucket=state", "region=us-west-2"},
+			want: `# cluster settings
+Region: "us-west-2" # aws region
+MasterCount: "1"
+TerraformBackendConfigs:
+  - "bucket=state"
+  - "region=us-west-2"
+TraefikHttpPort: 32080
+`,
+		},
+		{
+			name:  "missing key is appended",
+			key:   "VpcName",
+			value: "true",
+			want: `# cluster settings
+Region: "us-west-2" # aws region
+MasterCount: "1"
+TerraformBackendConfigs:
+  - "bucket=capten-talos-state"
+TraefikHttpPort: 32080
+VpcName: "true"
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := setYamlValue([]byte(data), tt.key, reflect.ValueOf(tt.value))
+			if err != nil {
+				t.Fatalf("setYamlValue() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("setYamlValue() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptenConfig_resolveValuesFileKey(t *testing.T) {
+	cfg := CaptenConfig{CaptenClusterValues: CaptenClusterValues{CloudService: "azure"}}
+
+	tests := []struct {
+		name     string
+		key      string
+		wantFile string
+		wantKey  string
+		wantErr  bool
+	}{
+		{"cluster values key", "DomainName", ClusterValuesFile, "DomainName", false},
+		{"host key", "NatsLoadBalancerHost", HostValuesFile, "NatsLoadBalancerHost", false},
+		{"cluster values preferred", "CloudService", ClusterValuesFile, "CloudService", false},
+		{"current cloud service preferred", "Region", AzureValuesFile, "Region", false},
+		{"qualified key", "aws.Region", AWSValuesFile, "Region", false},
+		{"field name of yaml key", "PublicIPName", AzureValuesFile, "PublicIpName", false},
+		{"unknown key", "Unknown", "", "", true},
+		{"unknown qualified key", "aws.NICs", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cfg.resolveValuesFileKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveValuesFileKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.file.name != tt.wantFile || got.key != tt.wantKey {
+				t.Errorf("resolveValuesFileKey() = %s.%s, want %s.%s", got.file.name, got.key, tt.wantFile, tt.wantKey)
+			}
+		})
+	}
+
+	cfg.CloudService = ""
+	if _, err := cfg.resolveValuesFileKey("Region"); err == nil {
+		t.Errorf("resolveValuesFileKey() expected error for ambiguous key")
+	}
+}
+
+func TestCaptenConfig_validateValuesFileUpdate(t *testing.T) {
+	cfg := CaptenConfig{
+		CaptenClusterValues:        CaptenClusterValues{DomainName: "dev.intelops.app", CloudService: "aws", ClusterType: "talos"},
+		AgentHostPort:              ":443",
+		CertKeyAlgorithm:           "rsa",
+		RootCAValidityDays:         1825,
+		IntermediateCAValidityDays: 730,
+		CertValidityDays:           365,
+	}
+	awsInfo := "Region: us-west-2\nInstanceType: m6i.xlarge\nMasterCount: \"1\"\nWorkerCount: \"3\"\n"
+	azureInfo := "Region: centralindia\nInstanceType: Standard_D4_v3\nMasterCount: [m1]\nWorkerCount: [w1]\nNICs: [n1]\nWorkerNics: [wn1]\n"
+
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		data    string
+		wantErr bool
+	}{
+		{"valid domain name", "DomainName", "prod.intelops.app", "DomainName: prod.intelops.app\n", false},
+		{"invalid domain name", "DomainName", "intelops", "DomainName: intelops\n", true},
+		{"unsupported cluster type", "ClusterType", "k3s", "ClusterType: k3s\n", true},
+		{"valid aws info", "aws.WorkerCount", "3", awsInfo, false},
+		{"zero aws worker count", "aws.WorkerCount", "0", strings.Replace(awsInfo, `"3"`, `"0"`, 1), true},
+		{"missing aws region", "aws.Region", "", strings.Replace(awsInfo, "us-west-2", `""`, 1), true},
+		{"valid azure info", "azure.Region", "centralindia", azureInfo, false},
+		{"azure worker without nic", "azure.WorkerNics", "", strings.Replace(azureInfo, "[wn1]", "[]", 1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileKey, err := cfg.resolveValuesFileKey(tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fieldValue := reflect.New(fileKey.field.Type).Elem()
+			if err := setFieldValue(fieldValue, tt.value); err != nil {
+				t.Fatal(err)
+			}
+			if err := cfg.validateValuesFileUpdate(fileKey, fieldValue, []byte(tt.data)); (err != nil) != tt.wantErr {
+				t.Errorf("validateValuesFileUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if cfg.DomainName != "dev.intelops.app" {
+		t.Errorf("validateValuesFileUpdate() changed the config, DomainName = %s", cfg.DomainName)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"html/template"
 
 	"os"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -16,7 +18,9 @@ import (
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/storage/driver"
 
 	"capten/pkg/clog"
 	"capten/pkg/config"
@@ -26,8 +30,11 @@ import (
 const (
 	folderPrmission os.FileMode = 0755
 	filePrmission   os.FileMode = 0644
+	maxHistory                  = 256
 )
 
+var repoMutex sync.Mutex
+
 type Client struct {
 	Settings       *cli.EnvSettings
 	defaultTimeout time.Duration
@@ -58,24 +65,8 @@ func (h *Client) Install(ctx context.Context, appConfig *types.AppConfig) (alrea
 	settings := cli.New()
 	settings.KubeConfig = h.Settings.KubeConfig
 	settings.SetNamespace(appConfig.Namespace)
-	r, err := repo.NewChartRepository(repoEntry, getter.All(settings))
-	if err != nil {
-		err = errors.Wrap(err, "failed to create new repo")
-		return
-	}
-
-	r.CachePath = settings.RepositoryCache
-	_, err = r.DownloadIndexFile()
-	if err != nil {
-		err = errors.Wrap(err, "unable to download the index file")
-		return
-	}
-
-	var repoFile repo.File
-	repoFile.Update(repoEntry)
-	err = repoFile.WriteFile(settings.RepositoryConfig, 0644)
+	err = addRepository(settings, repoEntry)
 	if err != nil {
-		err = errors.Wrap(err, "failed to write the helm-chart path")
 		return
 	}
 
@@ -105,6 +96,33 @@ func (h *Client) Install(ctx context.Context, appConfig *types.AppConfig) (alrea
 	return
 }
 
+func addRepository(settings *cli.EnvSettings, repoEntry *repo.Entry) error {
+	repoMutex.Lock()
+	defer repoMutex.Unlock()
+
+	r, err := repo.NewChartRepository(repoEntry, getter.All(settings))
+	if err != nil {
+		return errors.Wrap(err, "failed to create new repo")
+	}
+
+	r.CachePath = settings.RepositoryCache
+	_, err = r.DownloadIndexFile()
+	if err != nil {
+		return errors.Wrap(err, "unable to download the index file")
+	}
+
+	repoFile, err := repo.LoadFile(settings.RepositoryConfig)
+	if err != nil {
+		repoFile = repo.NewFile()
+	}
+	repoFile.Update(repoEntry)
+	err = repoFile.WriteFile(settings.RepositoryConfig, 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to write the helm-chart path")
+	}
+	return nil
+}
+
 func (h *Client) installApp(ctx context.Context, settings *cli.EnvSettings, actionConfig *action.Configuration, appConfig *types.AppConfig) error {
 	action.NewList(&action.Configuration{})
 	client := action.NewInstall(actionConfig)
@@ -125,29 +143,14 @@ func (h *Client) installApp(ctx context.Context, settings *cli.EnvSettings, acti
 		return errors.Wrap(err, "failed load chart")
 	}
 
-	if len(appConfig.TemplateValues) == 0 {
-		_, err = client.Run(chartReq, nil)
-		if err != nil {
-			return errors.Wrap(err, "failed chart install run")
-		}
-		return nil
-	}
-
-	appValuesFile, err := h.prepareAppValues(appConfig)
+	vals, err := h.appValues(settings, appConfig)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = os.Remove(appValuesFile) }()
 
-	valueOpts := &values.Options{ValueFiles: []string{appValuesFile}}
-	vals, err := valueOpts.MergeValues(getter.All(settings))
+	releaseInfo, err := client.RunWithContext(ctx, chartReq, vals)
 	if err != nil {
-		return errors.Wrap(err, "failed to merge chart values")
-	}
-
-	releaseInfo, err := client.Run(chartReq, vals)
-	if err != nil {
-		return errors.Wrap(err, "failed chart install run with values")
+		return errors.Wrap(err, "failed chart install run")
 	}
 
 	clog.Logger.Debugf("release info: %v", releaseInfo)
@@ -173,35 +176,124 @@ func (h *Client) upgradeApp(ctx context.Context, settings *cli.EnvSettings, acti
 		return errors.Wrap(err, "failed load chart")
 	}
 
-	if len(appConfig.TemplateValues) == 0 {
-		_, err = client.Run(appConfig.ReleaseName, chartReq, nil)
-		if err != nil {
-			return errors.Wrap(err, "failed chart upgrade run")
+	vals, err := h.appValues(settings, appConfig)
+	if err != nil {
+		return err
+	}
+
+	releaseInfo, err := client.RunWithContext(ctx, appConfig.ReleaseName, chartReq, vals)
+	if err != nil {
+		return errors.Wrap(err, "failed chart upgrade run")
+	}
+
+	clog.Logger.Debugf("release info: %v", releaseInfo)
+	return nil
+}
+
+func (h *Client) Uninstall(ctx context.Context, appConfig *types.AppConfig) (notInstalled bool, err error) {
+	actionConfig, err := h.newActionConfig(appConfig.Namespace)
+	if err != nil {
+		return
+	}
+
+	client := action.NewUninstall(actionConfig)
+	client.Timeout = h.defaultTimeout
+	client.DryRun = h.captenConfig.AppDeployDryRun
+	client.Wait = true
+	_, err = client.Run(appConfig.ReleaseName)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return true, nil
 		}
-		return nil
+		err = errors.Wrap(err, "failed chart uninstall run")
+		return
 	}
+	return
+}
 
-	appValuesFile, err := h.prepareAppValues(appConfig)
+func (h *Client) History(ctx context.Context, namespace, releaseName string) ([]types.AppReleaseRevision, error) {
+	actionConfig, err := h.newActionConfig(namespace)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer func() { _ = os.Remove(appValuesFile) }()
 
-	valueOpts := &values.Options{ValueFiles: []string{appValuesFile}}
-	vals, err := valueOpts.MergeValues(getter.All(settings))
+	client := action.NewHistory(actionConfig)
+	client.Max = maxHistory
+	releases, err := client.Run(releaseName)
 	if err != nil {
-		return errors.Wrap(err, "failed to merge chart values")
+		return nil, errors.Wrapf(err, "failed to get history of release %s", releaseName)
 	}
 
-	releaseInfo, err := client.Run(appConfig.ReleaseName, chartReq, vals)
+	return releaseRevisions(releases), nil
+}
+
+// LatestRevision returns the latest revision of the release, notInstalled is set when the release does not exist
+func (h *Client) LatestRevision(ctx context.Context, namespace, releaseName string) (revision types.AppReleaseRevision, notInstalled bool, err error) {
+	revisions, err := h.History(ctx, namespace, releaseName)
 	if err != nil {
-		return errors.Wrap(err, "failed chart upgrade run with values")
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return revision, true, nil
+		}
+		return revision, false, err
 	}
+	if len(revisions) == 0 {
+		return revision, true, nil
+	}
+	return revisions[len(revisions)-1], false, nil
+}
 
-	clog.Logger.Debugf("release info: %v", releaseInfo)
+func releaseRevisions(releases []*release.Release) []types.AppReleaseRevision {
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].Version < releases[j].Version
+	})
+
+	revisions := []types.AppReleaseRevision{}
+	for _, rel := range releases {
+		revision := types.AppReleaseRevision{Revision: rel.Version}
+		if rel.Chart != nil && rel.Chart.Metadata != nil {
+			revision.ChartVersion = rel.Chart.Metadata.Version
+			revision.AppVersion = rel.Chart.Metadata.AppVersion
+		}
+		if rel.Info != nil {
+			revision.Status = rel.Info.Status.String()
+			revision.Updated = rel.Info.LastDeployed.Time
+			revision.Description = rel.Info.Description
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions
+}
+
+func (h *Client) Rollback(ctx context.Context, namespace, releaseName string, revision int) error {
+	actionConfig, err := h.newActionConfig(namespace)
+	if err != nil {
+		return err
+	}
+
+	client := action.NewRollback(actionConfig)
+	client.Version = revision
+	client.Timeout = h.defaultTimeout
+	client.DryRun = h.captenConfig.AppDeployDryRun
+	client.Wait = true
+	if err := client.Run(releaseName); err != nil {
+		return errors.Wrapf(err, "failed to rollback release %s", releaseName)
+	}
 	return nil
 }
 
+func (h *Client) newActionConfig(namespace string) (*action.Configuration, error) {
+	settings := cli.New()
+	settings.KubeConfig = h.Settings.KubeConfig
+	settings.SetNamespace(namespace)
+
+	actionConfig := new(action.Configuration)
+	err := actionConfig.Init(settings.RESTClientGetter(), namespace, "", LogHelmDebug)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to setup actionConfig for helm")
+	}
+	return actionConfig, nil
+}
+
 func (h *Client) IsAppInstalled(actionConfig *action.Configuration, releaseName string) (bool, error) {
 	releaseClient := action.NewList(actionConfig)
 	releases, err := releaseClient.Run()
@@ -227,6 +319,30 @@ func LogHelmDebug(format string, v ...interface{}) {
 	clog.Logger.Debug(format, v)
 }
 
+// appValues renders the values template of the app, the set values of the app are
+// merged after the rendered values as with helm --set
+func (h *Client) appValues(settings *cli.EnvSettings, appConfig *types.AppConfig) (map[string]interface{}, error) {
+	if len(appConfig.TemplateValues) == 0 && len(appConfig.SetValues) == 0 {
+		return nil, nil
+	}
+
+	valueOpts := &values.Options{Values: appConfig.SetValues}
+	if len(appConfig.TemplateValues) != 0 {
+		appValuesFile, err := h.prepareAppValues(appConfig)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = os.Remove(appValuesFile) }()
+		valueOpts.ValueFiles = []string{appValuesFile}
+	}
+
+	vals, err := valueOpts.MergeValues(getter.All(settings))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to merge chart values")
+	}
+	return vals, nil
+}
+
 func (h *Client) prepareAppValues(appConfig *types.AppConfig) (string, error) {
 	transformedData, err := executeAppConfigTemplate(appConfig.TemplateValues, appConfig.OverrideValues)
 	if err != nil {
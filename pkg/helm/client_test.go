@@ -4,12 +4,16 @@ import (
 	"capten/pkg/config"
 	"capten/pkg/types"
 	"context"
+	"os"
 	"reflect"
 	"testing"
 	"time"
 
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -245,3 +249,88 @@ func TestClient_prepareAppValues(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_appValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		appConfig *types.AppConfig
+		want      map[string]interface{}
+	}{
+		{
+			name: "Set values merged after template values",
+			appConfig: &types.AppConfig{
+				Name:           "test-app",
+				TemplateValues: []byte("image:\n  tag: {{.tag}}\nreplicas: 1\n"),
+				OverrideValues: map[string]interface{}{"tag": "v1"},
+				SetValues:      []string{"image.tag=v0.2.1", "extra.key=x"},
+			},
+			want: map[string]interface{}{
+				"image":    map[string]interface{}{"tag": "v0.2.1"},
+				"replicas": float64(1),
+				"extra":    map[string]interface{}{"key": "x"},
+			},
+		},
+		{
+			name: "Set values without template values",
+			appConfig: &types.AppConfig{
+				Name:      "test-app",
+				SetValues: []string{"image.tag=v0.2.1"},
+			},
+			want: map[string]interface{}{
+				"image": map[string]interface{}{"tag": "v0.2.1"},
+			},
+		},
+		{
+			name:      "No values",
+			appConfig: &types.AppConfig{Name: "test-app"},
+			want:      nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Client{
+				Settings:     cli.New(),
+				captenConfig: config.CaptenConfig{CurrentDirPath: t.TempDir() + "/", AppValuesTempDirPath: "tmp/"},
+			}
+			if err := os.MkdirAll(h.captenConfig.PrepareDirPath(h.captenConfig.AppValuesTempDirPath), 0755); err != nil {
+				t.Fatal(err)
+			}
+			got, err := h.appValues(h.Settings, tt.appConfig)
+			if err != nil {
+				t.Errorf("Client.appValues() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Client.appValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_releaseRevisions(t *testing.T) {
+	deployed := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	releases := []*release.Release{
+		{
+			Version: 2,
+			Chart:   &chart.Chart{Metadata: &chart.Metadata{Version: "0.2.0", AppVersion: "v0.2.0"}},
+			Info:    &release.Info{Status: release.StatusDeployed, LastDeployed: helmtime.Time{Time: deployed}, Description: "Upgrade complete"},
+		},
+		{
+			Version: 1,
+			Chart:   &chart.Chart{Metadata: &chart.Metadata{Version: "0.1.0", AppVersion: "v0.1.0"}},
+			Info:    &release.Info{Status: release.StatusSuperseded, Description: "Install complete"},
+		},
+		{
+			Version: 3,
+		},
+	}
+
+	want := []types.AppReleaseRevision{
+		{Revision: 1, ChartVersion: "0.1.0", AppVersion: "v0.1.0", Status: "superseded", Description: "Install complete"},
+		{Revision: 2, ChartVersion: "0.2.0", AppVersion: "v0.2.0", Status: "deployed", Updated: deployed, Description: "Upgrade complete"},
+		{Revision: 3},
+	}
+	if got := releaseRevisions(releases); !reflect.DeepEqual(got, want) {
+		t.Errorf("releaseRevisions() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,219 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"capten/pkg/types"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func (h *Client) Diff(ctx context.Context, appConfig *types.AppConfig) (types.AppDiff, error) {
+	appDiff := types.AppDiff{Name: appConfig.Name}
+
+	settings := cli.New()
+	settings.KubeConfig = h.Settings.KubeConfig
+	settings.SetNamespace(appConfig.Namespace)
+	err := addRepository(settings, &repo.Entry{Name: appConfig.RepoName, URL: appConfig.RepoURL})
+	if err != nil {
+		return appDiff, err
+	}
+
+	actionConfig, err := h.newActionConfig(appConfig.Namespace)
+	if err != nil {
+		return appDiff, err
+	}
+
+	deployedValues, deployedManifest := "", ""
+	deployedRelease, err := action.NewGet(actionConfig).Run(appConfig.ReleaseName)
+	switch {
+	case err == nil:
+		appDiff.Installed = true
+		deployedValues, err = marshalValues(deployedRelease.Config)
+		if err != nil {
+			return appDiff, err
+		}
+		deployedManifest = deployedRelease.Manifest
+	case errors.Is(err, driver.ErrReleaseNotFound):
+	default:
+		return appDiff, errors.Wrapf(err, "failed to get release %s", appConfig.ReleaseName)
+	}
+
+	vals, err := h.appValues(settings, appConfig)
+	if err != nil {
+		return appDiff, err
+	}
+	desiredValues, err := marshalValues(vals)
+	if err != nil {
+		return appDiff, err
+	}
+
+	desiredRelease, err := h.renderRelease(settings, actionConfig, appConfig, vals, appDiff.Installed)
+	if err != nil {
+		return appDiff, err
+	}
+
+	appDiff.ValuesDiff, err = unifiedDiff(deployedValues, desiredValues, "values")
+	if err != nil {
+		return appDiff, err
+	}
+	appDiff.ManifestDiff, err = unifiedDiff(deployedManifest, desiredRelease.Manifest, "manifest")
+	if err != nil {
+		return appDiff, err
+	}
+	return appDiff, nil
+}
+
+func (h *Client) renderRelease(settings *cli.EnvSettings, actionConfig *action.Configuration, appConfig *types.AppConfig,
+	vals map[string]interface{}, installed bool) (*release.Release, error) {
+	if installed {
+		client := action.NewUpgrade(actionConfig)
+		client.Namespace = appConfig.Namespace
+		client.Version = appConfig.Version
+		client.DryRun = true
+		client.ResetValues = true
+
+		chartReq, err := loadChart(client.ChartPathOptions, appConfig.ChartName, settings)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := client.Run(appConfig.ReleaseName, chartReq, vals)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed chart upgrade dry run")
+		}
+		return rel, nil
+	}
+
+	client := action.NewInstall(actionConfig)
+	client.Namespace = appConfig.Namespace
+	client.ReleaseName = appConfig.ReleaseName
+	client.Version = appConfig.Version
+	client.DryRun = true
+
+	chartReq, err := loadChart(client.ChartPathOptions, appConfig.ChartName, settings)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := client.Run(chartReq, vals)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed chart install dry run")
+	}
+	return rel, nil
+}
+
+func (h *Client) Template(ctx context.Context, appConfig *types.AppConfig) (values string, manifest string, err error) {
+	settings := cli.New()
+	settings.KubeConfig = h.Settings.KubeConfig
+	settings.SetNamespace(appConfig.Namespace)
+	err = addRepository(settings, &repo.Entry{Name: appConfig.RepoName, URL: appConfig.RepoURL})
+	if err != nil {
+		return
+	}
+
+	actionConfig := &action.Configuration{Log: LogHelmDebug}
+	client := action.NewInstall(actionConfig)
+	client.Namespace = appConfig.Namespace
+	client.ReleaseName = appConfig.ReleaseName
+	client.Version = appConfig.Version
+	client.DryRun = true
+	client.ClientOnly = true
+	client.Replace = true
+	client.IncludeCRDs = true
+
+	chartReq, err := loadChart(client.ChartPathOptions, appConfig.ChartName, settings)
+	if err != nil {
+		return
+	}
+
+	vals, err := h.appValues(settings, appConfig)
+	if err != nil {
+		return
+	}
+	values, err = marshalValues(vals)
+	if err != nil {
+		return
+	}
+
+	rel, err := client.Run(chartReq, vals)
+	if err != nil {
+		err = errors.Wrap(err, "failed chart template run")
+		return
+	}
+	manifest = releaseManifest(rel)
+	return
+}
+
+func releaseManifest(rel *release.Release) string {
+	var sb strings.Builder
+	sb.WriteString(strings.TrimSpace(rel.Manifest))
+	for _, hook := range rel.Hooks {
+		fmt.Fprintf(&sb, "\n---\n# Source: %s\n%s", hook.Path, strings.TrimSpace(hook.Manifest))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func loadChart(chartPathOptions action.ChartPathOptions, chartName string, settings *cli.EnvSettings) (*chart.Chart, error) {
+	cp, err := chartPathOptions.LocateChart(chartName, settings)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to locate chart locate")
+	}
+	chartReq, err := loader.Load(cp)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed load chart")
+	}
+	return chartReq, nil
+}
+
+func marshalValues(vals map[string]interface{}) (string, error) {
+	if len(vals) == 0 {
+		return "", nil
+	}
+	data, err := yaml.Marshal(vals)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal chart values")
+	}
+	return string(data), nil
+}
+
+func unifiedDiff(deployed, desired, name string) (string, error) {
+	if deployed == desired {
+		return "", nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(deployed),
+		B:        diffLines(desired),
+		FromFile: "deployed/" + name,
+		ToFile:   "desired/" + name,
+		Context:  3,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to diff %s", name)
+	}
+	return diff, nil
+}
+
+func diffLines(content string) []string {
+	content = strings.TrimSpace(content)
+	if len(content) == 0 {
+		return nil
+	}
+
+	lines := strings.Split(content, "\n")
+	for index := range lines {
+		lines[index] += "\n"
+	}
+	return lines
+}
@@ -0,0 +1,60 @@
+package helm
+
+import (
+	"testing"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func Test_unifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		deployed string
+		desired  string
+		want     string
+	}{
+		{
+			name:     "No changes",
+			deployed: "image:\n  tag: v1\n",
+			desired:  "image:\n  tag: v1\n",
+			want:     "",
+		},
+		{
+			name:     "Changed value",
+			deployed: "image:\n  tag: v1\n",
+			desired:  "image:\n  tag: v2\n",
+			want:     "--- deployed/values\n+++ desired/values\n@@ -1,2 +1,2 @@\n image:\n-  tag: v1\n+  tag: v2\n",
+		},
+		{
+			name:     "Not installed",
+			deployed: "",
+			desired:  "replicas: 1\n",
+			want:     "--- deployed/values\n+++ desired/values\n@@ -0,0 +1 @@\n+replicas: 1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := unifiedDiff(tt.deployed, tt.desired, "values")
+			if err != nil {
+				t.Fatalf("unifiedDiff() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("unifiedDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_releaseManifest(t *testing.T) {
+	rel := &release.Release{
+		Manifest: "---\n# Source: kad/templates/deployment.yaml\nkind: Deployment\n",
+		Hooks: []*release.Hook{
+			{Path: "kad/templates/job.yaml", Manifest: "kind: Job\n"},
+		},
+	}
+
+	want := "---\n# Source: kad/templates/deployment.yaml\nkind: Deployment\n---\n# Source: kad/templates/job.yaml\nkind: Job\n"
+	if got := releaseManifest(rel); got != want {
+		t.Errorf("releaseManifest() = %q, want %q", got, want)
+	}
+}
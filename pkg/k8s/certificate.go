@@ -0,0 +1,116 @@
+package k8s
+
+import (
+	"capten/pkg/clog"
+	"capten/pkg/config"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmetav1 "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	cmclient "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	certificateReadyRetries  = 60
+	certificateRetryInterval = 5 * time.Second
+)
+
+type CertificateRequest struct {
+	Name       string
+	Namespace  string
+	SecretName string
+	CommonName string
+	DNSNames   []string
+	Usages     []certmanagerv1.KeyUsage
+}
+
+// RequestCertificate creates or updates a cert-manager Certificate issued by the cluster issuer of the
+// capten config, waits for it to be ready and returns the data of the secret holding the issued cert
+func RequestCertificate(captenConfig config.CaptenConfig, request CertificateRequest) (map[string][]byte, error) {
+	kubeconfigPath := captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.KubeConfigFileName)
+	kubeConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error while building kubeconfig")
+	}
+	cmClient, err := cmclient.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := certmanagerv1.CertificateSpec{
+		SecretName: request.SecretName,
+		CommonName: request.CommonName,
+		DNSNames:   request.DNSNames,
+		Usages:     request.Usages,
+		IssuerRef: cmmetav1.ObjectReference{
+			Name: captenConfig.ClusterCAIssuer,
+			Kind: certmanagerv1.ClusterIssuerKind,
+		},
+	}
+
+	certificates := cmClient.CertmanagerV1().Certificates(request.Namespace)
+	certificate, err := certificates.Get(context.Background(), request.Name, metav1.GetOptions{})
+	if err != nil && k8serrors.IsNotFound(err) {
+		certificate = &certmanagerv1.Certificate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      request.Name,
+				Namespace: request.Namespace,
+			},
+			Spec: spec,
+		}
+		certificate, err = certificates.Create(context.Background(), certificate, metav1.CreateOptions{})
+		if err != nil {
+			return nil, errors.WithMessagef(err, "error in creating certificate %s", request.Name)
+		}
+	} else if err != nil {
+		return nil, errors.WithMessagef(err, "error in getting certificate %s", request.Name)
+	} else {
+		certificate.Spec = spec
+		certificate, err = certificates.Update(context.Background(), certificate, metav1.UpdateOptions{})
+		if err != nil {
+			return nil, errors.WithMessagef(err, "error in updating certificate %s", request.Name)
+		}
+	}
+	clog.Logger.Debugf("Certificate %s requested from issuer %s", request.Name, captenConfig.ClusterCAIssuer)
+
+	generation := certificate.Generation
+	err = retry(certificateReadyRetries, certificateRetryInterval, func() error {
+		certificate, err := certificates.Get(context.Background(), request.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if !isCertificateReady(certificate, generation) {
+			return fmt.Errorf("certificate %s is not ready", request.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "certificate %s is not issued by %s", request.Name, captenConfig.ClusterCAIssuer)
+	}
+
+	clientSet, err := GetK8SClient(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := clientSet.CoreV1().Secrets(request.Namespace).Get(context.Background(), request.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "error in getting secret %s of certificate %s", request.SecretName, request.Name)
+	}
+	return secret.Data, nil
+}
+
+func isCertificateReady(certificate *certmanagerv1.Certificate, generation int64) bool {
+	for _, condition := range certificate.Status.Conditions {
+		if condition.Type == certmanagerv1.CertificateConditionReady {
+			return condition.Status == cmmetav1.ConditionTrue && condition.ObservedGeneration >= generation
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package k8s
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetNotReadyNodes returns the number of cluster nodes and the names of the nodes which are not ready
+func GetNotReadyNodes(ctx context.Context, kubeconfigPath string) (nodeCount int, notReadyNodes []string, err error) {
+	clientSet, err := GetK8SClient(kubeconfigPath)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	nodes, err := clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return 0, nil, errors.WithMessage(err, "failed to list cluster nodes")
+	}
+
+	notReadyNodes = []string{}
+	for _, node := range nodes.Items {
+		if !isNodeReady(node) {
+			notReadyNodes = append(notReadyNodes, node.Name)
+		}
+	}
+	return len(nodes.Items), notReadyNodes, nil
+}
+
+func isNodeReady(node corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -47,6 +47,32 @@ func CreateOrUpdateCertSecrets(captenConfig config.CaptenConfig) error {
 	return createOrUpdateAgentCACert(captenConfig, clientSet)
 }
 
+// CreateOrUpdateAgentCertSecrets creates the agent cert and CA cert secrets,
+// used when the intermediate CA key is not available for the cluster issuer
+func CreateOrUpdateAgentCertSecrets(captenConfig config.CaptenConfig) error {
+	kubeconfigPath := captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.KubeConfigFileName)
+	clientSet, err := GetK8SClient(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	err = createOrUpdateAgentCertSecret(captenConfig, clientSet)
+	if err != nil {
+		return err
+	}
+	return createOrUpdateAgentCACert(captenConfig, clientSet)
+}
+
+// CreateOrUpdateAgentCACertSecret creates the agent CA cert secret only,
+// used when the agent cert secret is managed by cert-manager
+func CreateOrUpdateAgentCACertSecret(captenConfig config.CaptenConfig) error {
+	kubeconfigPath := captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.KubeConfigFileName)
+	clientSet, err := GetK8SClient(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+	return createOrUpdateAgentCACert(captenConfig, clientSet)
+}
+
 func createOrUpdateAgentCertSecret(captenConfig config.CaptenConfig, k8sClient *kubernetes.Clientset) error {
 	certData, err := os.ReadFile(captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.AgentCertFileName))
 	if err != nil {
@@ -92,6 +118,13 @@ func createOrUpdateAgentCACert(captenConfig config.CaptenConfig, k8sClient *kube
 		},
 		Type: corev1.SecretTypeOpaque,
 	}
+
+	crlData, err := os.ReadFile(captenConfig.PrepareFilePath(captenConfig.CertDirPath, captenConfig.CRLFileName))
+	if err == nil {
+		secret.Data["ca.crl"] = crlData
+	} else if !os.IsNotExist(err) {
+		return errors.WithMessage(err, "error while reading ca crl")
+	}
 	return createOrUpdateSecret(k8sClient, secret)
 }
 
@@ -0,0 +1,128 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// WaitForSecretValue waits until a key of the secret holds the value, the secrets
+// configured from vault are synced by the external secrets operator with a delay
+func WaitForSecretValue(kubeconfigPath, namespace, secretName, value string) error {
+	clientSet, err := GetK8SClient(kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	return retry(60, 5*time.Second, func() error {
+		secret, err := clientSet.CoreV1().Secrets(namespace).Get(context.Background(), secretName, metav1.GetOptions{})
+		if err != nil {
+			return errors.WithMessagef(err, "failed to get secret %s/%s", namespace, secretName)
+		}
+		for _, data := range secret.Data {
+			if bytes.Equal(data, []byte(value)) {
+				return nil
+			}
+		}
+		return fmt.Errorf("secret %s/%s is not synced with the new value", namespace, secretName)
+	})
+}
+
+// RestartSecretConsumers rolls out the deployments, statefulsets and daemonsets of the namespace
+// which use the secret in an env or a volume, the names of the restarted workloads are returned
+func RestartSecretConsumers(kubeconfigPath, namespace, secretName string) ([]string, error) {
+	clientSet, err := GetK8SClient(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	patch := []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		restartedAtAnnotation, time.Now().Format(time.RFC3339)))
+	restarted := []string{}
+
+	deployments, err := clientSet.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return restarted, errors.WithMessagef(err, "failed to list deployments in %s", namespace)
+	}
+	for _, deployment := range deployments.Items {
+		if !podSpecUsesSecret(deployment.Spec.Template.Spec, secretName) {
+			continue
+		}
+		_, err := clientSet.AppsV1().Deployments(namespace).Patch(ctx, deployment.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return restarted, errors.WithMessagef(err, "failed to restart deployment %s/%s", namespace, deployment.Name)
+		}
+		restarted = append(restarted, "deployment/"+deployment.Name)
+	}
+
+	statefulSets, err := clientSet.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return restarted, errors.WithMessagef(err, "failed to list statefulsets in %s", namespace)
+	}
+	for _, statefulSet := range statefulSets.Items {
+		if !podSpecUsesSecret(statefulSet.Spec.Template.Spec, secretName) {
+			continue
+		}
+		_, err := clientSet.AppsV1().StatefulSets(namespace).Patch(ctx, statefulSet.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return restarted, errors.WithMessagef(err, "failed to restart statefulset %s/%s", namespace, statefulSet.Name)
+		}
+		restarted = append(restarted, "statefulset/"+statefulSet.Name)
+	}
+
+	daemonSets, err := clientSet.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return restarted, errors.WithMessagef(err, "failed to list daemonsets in %s", namespace)
+	}
+	for _, daemonSet := range daemonSets.Items {
+		if !podSpecUsesSecret(daemonSet.Spec.Template.Spec, secretName) {
+			continue
+		}
+		_, err := clientSet.AppsV1().DaemonSets(namespace).Patch(ctx, daemonSet.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return restarted, errors.WithMessagef(err, "failed to restart daemonset %s/%s", namespace, daemonSet.Name)
+		}
+		restarted = append(restarted, "daemonset/"+daemonSet.Name)
+	}
+	return restarted, nil
+}
+
+func podSpecUsesSecret(podSpec corev1.PodSpec, secretName string) bool {
+	for _, volume := range podSpec.Volumes {
+		if volume.Secret != nil && volume.Secret.SecretName == secretName {
+			return true
+		}
+		if volume.Projected == nil {
+			continue
+		}
+		for _, source := range volume.Projected.Sources {
+			if source.Secret != nil && source.Secret.Name == secretName {
+				return true
+			}
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil && envFrom.SecretRef.Name == secretName {
+				return true
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == secretName {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPodSpecUsesSecret(t *testing.T) {
+	secretKeyEnv := corev1.EnvVar{Name: "PASSWORD", ValueFrom: &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "clickhouse-secret"}, Key: "password"}}}
+	tests := []struct {
+		name    string
+		podSpec corev1.PodSpec
+		want    bool
+	}{
+		{name: "Env secret key", podSpec: corev1.PodSpec{Containers: []corev1.Container{{Env: []corev1.EnvVar{secretKeyEnv}}}}, want: true},
+		{name: "Init container env", podSpec: corev1.PodSpec{InitContainers: []corev1.Container{{Env: []corev1.EnvVar{secretKeyEnv}}}}, want: true},
+		{name: "Env from secret", podSpec: corev1.PodSpec{Containers: []corev1.Container{{EnvFrom: []corev1.EnvFromSource{
+			{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "clickhouse-secret"}}}}}}}, want: true},
+		{name: "Secret volume", podSpec: corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: "creds", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "clickhouse-secret"}}}}}, want: true},
+		{name: "Projected volume", podSpec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "creds", VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+				{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "clickhouse-secret"}}}}}}}}}, want: true},
+		{name: "Other secret", podSpec: corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: "creds", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "nats-token"}}}}}},
+		{name: "No secrets", podSpec: corev1.PodSpec{Containers: []corev1.Container{{Env: []corev1.EnvVar{{Name: "MODE", Value: "dev"}}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := podSpecUsesSecret(tt.podSpec, "clickhouse-secret"); got != tt.want {
+				t.Errorf("podSpecUsesSecret() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	FormatTable = "table"
+	FormatWide  = "wide"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+var (
+	Formats = []string{FormatTable, FormatWide, FormatJSON, FormatYAML}
+	format  = FormatTable
+)
+
+// Tabular is implemented by the results of the list and show commands, wide adds the
+// columns which are left out of the default table. JSON and YAML output marshal the value itself
+type Tabular interface {
+	Table(wide bool) (headers []string, rows [][]string)
+}
+
+// List is implemented by the list results, an empty list is reported with the message
+// instead of an empty table
+type List interface {
+	Tabular
+	EmptyMessage() string
+}
+
+func SetFormat(outputFormat string) error {
+	if len(outputFormat) == 0 {
+		outputFormat = FormatTable
+	}
+	for _, supported := range Formats {
+		if outputFormat == supported {
+			format = outputFormat
+			return nil
+		}
+	}
+	return fmt.Errorf("output format '%s' is not supported, supported formats: %s", outputFormat, strings.Join(Formats, ", "))
+}
+
+func IsTable() bool {
+	return format == FormatTable || format == FormatWide
+}
+
+func Render(data Tabular) error {
+	return Write(os.Stdout, format, data)
+}
+
+func Write(w io.Writer, outputFormat string, data Tabular) error {
+	switch outputFormat {
+	case FormatJSON:
+		encoded, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return errors.WithMessage(err, "failed to marshal json output")
+		}
+		_, err = fmt.Fprintln(w, string(encoded))
+		return err
+	case FormatYAML:
+		encoded, err := yaml.Marshal(data)
+		if err != nil {
+			return errors.WithMessage(err, "failed to marshal yaml output")
+		}
+		_, err = w.Write(encoded)
+		return err
+	case FormatTable, FormatWide:
+		headers, rows := data.Table(outputFormat == FormatWide)
+		table := tablewriter.NewWriter(w)
+		table.SetHeader(headers)
+		table.SetAutoWrapText(outputFormat != FormatWide)
+		table.AppendBulk(rows)
+		table.Render()
+		return nil
+	default:
+		return fmt.Errorf("output format '%s' is not supported", outputFormat)
+	}
+}
+
+// Attributes renders a single item as an attribute and value table
+type Attributes [][2]string
+
+func AttributesTable(attributes Attributes) (headers []string, rows [][]string) {
+	for _, attribute := range attributes {
+		rows = append(rows, []string{attribute[0], attribute[1]})
+	}
+	return []string{"Attribute", "Value"}, rows
+}
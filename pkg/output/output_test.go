@@ -0,0 +1,99 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type testItem struct {
+	Name   string `json:"name" yaml:"name"`
+	Status string `json:"status" yaml:"status"`
+}
+
+type testItems []testItem
+
+func (items testItems) Table(wide bool) ([]string, [][]string) {
+	headers := []string{"Name"}
+	if wide {
+		headers = append(headers, "Status")
+	}
+	rows := [][]string{}
+	for _, item := range items {
+		row := []string{item.Name}
+		if wide {
+			row = append(row, item.Status)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+func TestWrite(t *testing.T) {
+	items := testItems{{Name: "tekton", Status: "Installed"}}
+	tests := []struct {
+		name        string
+		format      string
+		contains    []string
+		notContains []string
+		wantErr     bool
+	}{
+		{name: "table", format: FormatTable, contains: []string{"NAME", "tekton"}, notContains: []string{"Installed"}},
+		{name: "wide", format: FormatWide, contains: []string{"NAME", "STATUS", "tekton", "Installed"}},
+		{name: "json", format: FormatJSON, contains: []string{`"name": "tekton"`, `"status": "Installed"`}},
+		{name: "yaml", format: FormatYAML, contains: []string{"- name: tekton", "  status: Installed"}},
+		{name: "unsupported", format: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Write(&buf, tt.format, items)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Write() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Write() output does not contain %q\n%s", want, buf.String())
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(buf.String(), unwanted) {
+					t.Errorf("Write() output contains %q\n%s", unwanted, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteEmptyList(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, testItems{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != "[]" {
+		t.Errorf("Write() = %q, want []", got)
+	}
+}
+
+func TestSetFormat(t *testing.T) {
+	defer SetFormat(FormatTable)
+	tests := []struct {
+		format    string
+		wantErr   bool
+		wantTable bool
+	}{
+		{format: "", wantTable: true},
+		{format: FormatWide, wantTable: true},
+		{format: FormatYAML},
+		{format: "csv", wantErr: true},
+	}
+	for _, tt := range tests {
+		err := SetFormat(tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SetFormat(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+		}
+		if !tt.wantErr && IsTable() != tt.wantTable {
+			t.Errorf("SetFormat(%q) IsTable() = %v, want %v", tt.format, IsTable(), tt.wantTable)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package setup
+
+import (
+	"capten/pkg/clog"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Step struct {
+	Name              string
+	Enabled           bool
+	DependsOn         []string
+	ContinueOnFailure bool
+	Retries           int
+	RetryInterval     time.Duration
+	Timeout           time.Duration
+	Run               func(ctx context.Context) error
+}
+
+type RunOptions struct {
+	Resume    bool
+	FromStep  string
+	OnlySteps []string
+}
+
+type Runner struct {
+	state  *State
+	values map[string]interface{}
+}
+
+func NewRunner(state *State, values map[string]interface{}) *Runner {
+	return &Runner{state: state, values: values}
+}
+
+func (r *Runner) Run(steps []Step, opts RunOptions) error {
+	steps, err := orderSteps(steps)
+	if err != nil {
+		return err
+	}
+
+	selected, err := selectSteps(steps, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.Resume || len(opts.OnlySteps) != 0 || len(opts.FromStep) != 0 {
+		for key, value := range r.state.Values {
+			if _, ok := r.values[key]; !ok {
+				r.values[key] = value
+			}
+		}
+	} else {
+		r.state.Reset()
+	}
+
+	// steps that failed, are disabled or were skipped in this run, their dependents are skipped
+	unavailable := map[string]string{}
+	for _, step := range steps {
+		if !selected[step.Name] {
+			continue
+		}
+
+		if !step.Enabled {
+			unavailable[step.Name] = StepStatusDisabled
+			r.state.Set(StepState{Name: step.Name, Status: StepStatusDisabled})
+			if err := r.state.Save(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if dependency, status := unavailableDependency(step, unavailable); len(dependency) != 0 {
+			clog.Logger.Infof("[step: %s] skipped, dependency %s %s", step.Name, dependency, status)
+			unavailable[step.Name] = StepStatusSkipped
+			r.state.Set(StepState{Name: step.Name, Status: StepStatusSkipped,
+				Error: fmt.Sprintf("dependency %s %s", dependency, status)})
+			if err := r.state.Save(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if opts.Resume && r.state.IsCompleted(step.Name) {
+			clog.Logger.Infof("[step: %s] already completed, skipped", step.Name)
+			continue
+		}
+
+		if err := r.runStep(step); err != nil {
+			unavailable[step.Name] = StepStatusFailed
+			if step.ContinueOnFailure {
+				clog.Logger.Errorf("[step: %s] failed, %v", step.Name, err)
+				continue
+			}
+			return errors.WithMessagef(err, "step %s failed", step.Name)
+		}
+	}
+	return nil
+}
+
+func unavailableDependency(step Step, unavailable map[string]string) (dependency, status string) {
+	for _, dependency := range step.DependsOn {
+		if status, ok := unavailable[dependency]; ok {
+			return dependency, status
+		}
+	}
+	return "", ""
+}
+
+func (r *Runner) runStep(step Step) error {
+	existingKeys := map[string]bool{}
+	for key := range r.values {
+		existingKeys[key] = true
+	}
+
+	stepState := StepState{Name: step.Name, StartedAt: time.Now()}
+	err := runWithRetries(step)
+	stepState.FinishedAt = time.Now()
+	if err != nil {
+		stepState.Status = StepStatusFailed
+		stepState.Error = err.Error()
+	} else {
+		stepState.Status = StepStatusCompleted
+		for key, value := range r.values {
+			if !existingKeys[key] {
+				r.state.Values[key] = value
+			}
+		}
+	}
+
+	r.state.Set(stepState)
+	if saveErr := r.state.Save(); saveErr != nil {
+		clog.Logger.Errorf("failed to save setup state, %v", saveErr)
+	}
+	return err
+}
+
+func runWithRetries(step Step) (err error) {
+	for attempt := 0; attempt <= step.Retries; attempt++ {
+		if attempt > 0 {
+			clog.Logger.Infof("[step: %s] attempt %d failed, retrying in %s, %v", step.Name, attempt, step.RetryInterval, err)
+			time.Sleep(step.RetryInterval)
+		}
+
+		if err = runWithTimeout(step); err == nil {
+			return nil
+		}
+	}
+	return
+}
+
+// runWithTimeout cancels the context of the step once the timeout passes and waits for the step to return,
+// so a timed out step never runs alongside its retry or the next steps
+func runWithTimeout(step Step) error {
+	if step.Timeout == 0 {
+		return step.Run(context.Background())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), step.Timeout)
+	defer cancel()
+
+	err := step.Run(ctx)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return errors.WithMessagef(err, "timed out after %s", step.Timeout)
+	}
+	return err
+}
+
+func selectSteps(steps []Step, opts RunOptions) (map[string]bool, error) {
+	stepNames := []string{}
+	for _, step := range steps {
+		stepNames = append(stepNames, step.Name)
+	}
+
+	if len(opts.OnlySteps) != 0 && len(opts.FromStep) != 0 {
+		return nil, fmt.Errorf("only-step and from-step can not be used together")
+	}
+
+	selected := map[string]bool{}
+	switch {
+	case len(opts.OnlySteps) != 0:
+		for _, name := range opts.OnlySteps {
+			if !slices.Contains(stepNames, name) {
+				return nil, fmt.Errorf("unknown step '%s', supported steps: %s", name, strings.Join(stepNames, ", "))
+			}
+			selected[name] = true
+		}
+	case len(opts.FromStep) != 0:
+		index := slices.Index(stepNames, opts.FromStep)
+		if index < 0 {
+			return nil, fmt.Errorf("unknown step '%s', supported steps: %s", opts.FromStep, strings.Join(stepNames, ", "))
+		}
+		for _, name := range stepNames[index:] {
+			selected[name] = true
+		}
+	default:
+		for _, name := range stepNames {
+			selected[name] = true
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,202 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRunner_Run(t *testing.T) {
+	type args struct {
+		opts       RunOptions
+		completed  []string
+		failStep   string
+		skipSecond bool
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantRun []string
+		wantErr bool
+	}{
+		{
+			name:    "Run all steps",
+			args:    args{},
+			wantRun: []string{"step-1", "step-2", "step-3"},
+		},
+		{
+			name:    "Resume skips completed steps",
+			args:    args{opts: RunOptions{Resume: true}, completed: []string{"step-1"}},
+			wantRun: []string{"step-2", "step-3"},
+		},
+		{
+			name:    "Run from step",
+			args:    args{opts: RunOptions{FromStep: "step-2"}},
+			wantRun: []string{"step-2", "step-3"},
+		},
+		{
+			name:    "Run only steps",
+			args:    args{opts: RunOptions{OnlySteps: []string{"step-3", "step-1"}}},
+			wantRun: []string{"step-1", "step-3"},
+		},
+		{
+			name:    "Disabled step is not run",
+			args:    args{skipSecond: true},
+			wantRun: []string{"step-1", "step-3"},
+		},
+		{
+			name:    "Failed step stops the run",
+			args:    args{failStep: "step-2"},
+			wantRun: []string{"step-1", "step-2"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown step",
+			args:    args{opts: RunOptions{FromStep: "step-4"}},
+			wantRun: []string{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := LoadState(filepath.Join(t.TempDir(), "state.yaml"))
+			if err != nil {
+				t.Fatalf("LoadState() error = %v", err)
+			}
+			for _, name := range tt.args.completed {
+				state.Set(StepState{Name: name, Status: StepStatusCompleted})
+			}
+
+			gotRun := []string{}
+			steps := []Step{}
+			for _, name := range []string{"step-1", "step-2", "step-3"} {
+				stepName := name
+				steps = append(steps, Step{
+					Name:    stepName,
+					Enabled: !(tt.args.skipSecond && stepName == "step-2"),
+					Run: func(context.Context) error {
+						gotRun = append(gotRun, stepName)
+						if stepName == tt.args.failStep {
+							return errors.New("step failed")
+						}
+						return nil
+					},
+				})
+			}
+
+			err = NewRunner(state, map[string]interface{}{}).Run(steps, tt.args.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Runner.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(gotRun, tt.wantRun) {
+				t.Errorf("Runner.Run() ran %v, want %v", gotRun, tt.wantRun)
+			}
+		})
+	}
+}
+
+func TestRunner_RunSkipsDependents(t *testing.T) {
+	tests := []struct {
+		name        string
+		failStep    string
+		disableStep string
+		wantRun     []string
+		wantStatus  map[string]string
+	}{
+		{
+			name:     "Dependents of failed step skipped",
+			failStep: "step-1",
+			wantRun:  []string{"step-1", "step-4"},
+			wantStatus: map[string]string{"step-1": StepStatusFailed, "step-2": StepStatusSkipped,
+				"step-3": StepStatusSkipped, "step-4": StepStatusCompleted},
+		},
+		{
+			name:        "Dependents of disabled step skipped",
+			disableStep: "step-1",
+			wantRun:     []string{"step-4"},
+			wantStatus: map[string]string{"step-1": StepStatusDisabled, "step-2": StepStatusSkipped,
+				"step-3": StepStatusSkipped, "step-4": StepStatusCompleted},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := LoadState(filepath.Join(t.TempDir(), "state.yaml"))
+			if err != nil {
+				t.Fatalf("LoadState() error = %v", err)
+			}
+
+			gotRun := []string{}
+			newStep := func(name string, dependsOn ...string) Step {
+				return Step{
+					Name:              name,
+					Enabled:           name != tt.disableStep,
+					DependsOn:         dependsOn,
+					ContinueOnFailure: true,
+					Run: func(context.Context) error {
+						gotRun = append(gotRun, name)
+						if name == tt.failStep {
+							return errors.New("step failed")
+						}
+						return nil
+					},
+				}
+			}
+			steps := []Step{newStep("step-1"), newStep("step-2", "step-1"), newStep("step-3", "step-2"), newStep("step-4")}
+
+			if err := NewRunner(state, map[string]interface{}{}).Run(steps, RunOptions{}); err != nil {
+				t.Errorf("Runner.Run() error = %v", err)
+			}
+			if !reflect.DeepEqual(gotRun, tt.wantRun) {
+				t.Errorf("Runner.Run() ran %v, want %v", gotRun, tt.wantRun)
+			}
+			for _, stepState := range state.Steps {
+				if stepState.Status != tt.wantStatus[stepState.Name] {
+					t.Errorf("step %s status = %s, want %s", stepState.Name, stepState.Status, tt.wantStatus[stepState.Name])
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_RunRestoresValues(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state.yaml")
+	state, err := LoadState(stateFile)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+
+	values := map[string]interface{}{"DomainName": "example.com"}
+	steps := []Step{
+		{Name: "store-credentials", Enabled: true, Run: func(context.Context) error {
+			values["natsTokenSecretName"] = "nats-token"
+			return nil
+		}},
+		{Name: "install-apps", Enabled: true, Run: func(context.Context) error {
+			return errors.New("install failed")
+		}},
+	}
+	if err := NewRunner(state, values).Run(steps, RunOptions{}); err == nil {
+		t.Fatalf("Runner.Run() expected error")
+	}
+
+	state, err = LoadState(stateFile)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if !state.IsCompleted("store-credentials") || state.IsCompleted("install-apps") {
+		t.Errorf("unexpected step states %v", state.Steps)
+	}
+
+	resumedValues := map[string]interface{}{"DomainName": "example.com"}
+	steps[1].Run = func(context.Context) error {
+		if resumedValues["natsTokenSecretName"] != "nats-token" {
+			return errors.New("missing value from completed step")
+		}
+		return nil
+	}
+	if err := NewRunner(state, resumedValues).Run(steps, RunOptions{Resume: true}); err != nil {
+		t.Errorf("Runner.Run() resume error = %v", err)
+	}
+}
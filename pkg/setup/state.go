@@ -0,0 +1,95 @@
+package setup
+
+import (
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	StepStatusCompleted = "completed"
+	StepStatusFailed    = "failed"
+	StepStatusSkipped   = "skipped"
+	StepStatusDisabled  = "disabled"
+
+	filePrmission os.FileMode = 0644
+)
+
+type StepState struct {
+	Name       string    `json:"name" yaml:"name"`
+	Status     string    `json:"status" yaml:"status"`
+	StartedAt  time.Time `json:"startedAt,omitempty" yaml:"startedAt,omitempty"`
+	FinishedAt time.Time `json:"finishedAt,omitempty" yaml:"finishedAt,omitempty"`
+	Error      string    `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type State struct {
+	Steps  []StepState            `yaml:"steps"`
+	Values map[string]interface{} `yaml:"values,omitempty"`
+
+	filePath string
+}
+
+func LoadState(stateFilePath string) (*State, error) {
+	state := &State{filePath: stateFilePath, Values: map[string]interface{}{}}
+	data, err := os.ReadFile(stateFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, errors.WithMessagef(err, "failed to read setup state file, %s", stateFilePath)
+	}
+
+	err = yaml.Unmarshal(data, state)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to unmarshal setup state file, %s", stateFilePath)
+	}
+	if state.Values == nil {
+		state.Values = map[string]interface{}{}
+	}
+	return state, nil
+}
+
+func (s *State) Save() error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return errors.WithMessage(err, "failed to marshal setup state")
+	}
+
+	err = os.WriteFile(s.filePath, data, filePrmission)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to write setup state file, %s", s.filePath)
+	}
+	return nil
+}
+
+func (s *State) Reset() {
+	s.Steps = []StepState{}
+	s.Values = map[string]interface{}{}
+}
+
+func (s *State) Get(name string) (StepState, bool) {
+	for _, step := range s.Steps {
+		if step.Name == name {
+			return step, true
+		}
+	}
+	return StepState{}, false
+}
+
+func (s *State) Set(stepState StepState) {
+	for index, step := range s.Steps {
+		if step.Name == stepState.Name {
+			s.Steps[index] = stepState
+			return
+		}
+	}
+	s.Steps = append(s.Steps, stepState)
+}
+
+func (s *State) IsCompleted(name string) bool {
+	step, ok := s.Get(name)
+	return ok && step.Status == StepStatusCompleted
+}
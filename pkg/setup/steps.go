@@ -0,0 +1,144 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+type ActionFunc func(ctx context.Context, parameters map[string]interface{}) error
+
+type StepsConfig struct {
+	Steps []StepConfig `yaml:"steps"`
+}
+
+type StepConfig struct {
+	Name              string                 `yaml:"name"`
+	Action            string                 `yaml:"action"`
+	Enabled           *bool                  `yaml:"enabled"`
+	DependsOn         []string               `yaml:"dependsOn"`
+	ContinueOnFailure bool                   `yaml:"continueOnFailure"`
+	Retries           int                    `yaml:"retries"`
+	RetryInterval     time.Duration          `yaml:"retryInterval"`
+	Timeout           time.Duration          `yaml:"timeout"`
+	Parameters        map[string]interface{} `yaml:"parameters"`
+}
+
+func LoadStepsConfig(stepsFilePath string) ([]StepConfig, error) {
+	data, err := os.ReadFile(stepsFilePath)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to read steps file, %s", stepsFilePath)
+	}
+
+	var stepsConfig StepsConfig
+	err = yaml.Unmarshal(data, &stepsConfig)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to unmarshal steps file, %s", stepsFilePath)
+	}
+
+	if len(stepsConfig.Steps) == 0 {
+		return nil, fmt.Errorf("no steps defined in %s", stepsFilePath)
+	}
+	return stepsConfig.Steps, nil
+}
+
+func BuildSteps(stepConfigs []StepConfig, actions map[string]ActionFunc) ([]Step, error) {
+	steps := []Step{}
+	for _, stepConfig := range stepConfigs {
+		action, ok := actions[stepConfig.Action]
+		if !ok {
+			return nil, fmt.Errorf("step '%s' has unknown action '%s'", stepConfig.Name, stepConfig.Action)
+		}
+
+		if stepConfig.Retries < 0 || stepConfig.RetryInterval < 0 || stepConfig.Timeout < 0 {
+			return nil, fmt.Errorf("step '%s' has negative retry or timeout settings", stepConfig.Name)
+		}
+
+		parameters := stepConfig.Parameters
+		if parameters == nil {
+			parameters = map[string]interface{}{}
+		}
+
+		steps = append(steps, Step{
+			Name:              stepConfig.Name,
+			Enabled:           stepConfig.Enabled == nil || *stepConfig.Enabled,
+			DependsOn:         stepConfig.DependsOn,
+			ContinueOnFailure: stepConfig.ContinueOnFailure,
+			Retries:           stepConfig.Retries,
+			RetryInterval:     stepConfig.RetryInterval,
+			Timeout:           stepConfig.Timeout,
+			Run: func(ctx context.Context) error {
+				return action(ctx, parameters)
+			},
+		})
+	}
+
+	if _, err := orderSteps(steps); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+func orderSteps(steps []Step) ([]Step, error) {
+	stepIndex := map[string]int{}
+	for index, step := range steps {
+		if len(step.Name) == 0 {
+			return nil, fmt.Errorf("step %d has no name", index+1)
+		}
+		if _, ok := stepIndex[step.Name]; ok {
+			return nil, fmt.Errorf("step '%s' is defined more than once", step.Name)
+		}
+		stepIndex[step.Name] = index
+	}
+
+	for _, step := range steps {
+		for _, dependency := range step.DependsOn {
+			if dependency == step.Name {
+				return nil, fmt.Errorf("step '%s' depends on itself", step.Name)
+			}
+			if _, ok := stepIndex[dependency]; !ok {
+				return nil, fmt.Errorf("step '%s' depends on unknown step '%s'", step.Name, dependency)
+			}
+		}
+	}
+
+	ordered := []Step{}
+	placed := map[string]bool{}
+	for len(ordered) < len(steps) {
+		progressed := false
+		for _, step := range steps {
+			if placed[step.Name] || !dependenciesPlaced(step, placed) {
+				continue
+			}
+			ordered = append(ordered, step)
+			placed[step.Name] = true
+			progressed = true
+			break
+		}
+
+		if !progressed {
+			pending := []string{}
+			for _, step := range steps {
+				if !placed[step.Name] {
+					pending = append(pending, step.Name)
+				}
+			}
+			return nil, fmt.Errorf("steps have cyclic dependencies: %s", strings.Join(pending, ", "))
+		}
+	}
+	return ordered, nil
+}
+
+func dependenciesPlaced(step Step, placed map[string]bool) bool {
+	for _, dependency := range step.DependsOn {
+		if !placed[dependency] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,190 @@
+package setup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testActions(names ...string) map[string]ActionFunc {
+	actions := map[string]ActionFunc{}
+	for _, name := range names {
+		actions[name] = func(context.Context, map[string]interface{}) error { return nil }
+	}
+	return actions
+}
+
+func TestLoadStepsConfig(t *testing.T) {
+	stepConfigs, err := LoadStepsConfig("../../config/setup_apps.yaml")
+	if err != nil {
+		t.Fatalf("LoadStepsConfig() error = %v", err)
+	}
+
+	actions := testActions("create-namespace", "install-app-group", "update-lb-endpoint", "configure-agent-certs",
+		"configure-cert-issuer", "configure-cstor-pool", "store-cluster-credentials", "synch-apps")
+	if _, err := BuildSteps(stepConfigs, actions); err != nil {
+		t.Errorf("BuildSteps() error = %v", err)
+	}
+
+	if _, err := LoadStepsConfig("invalid_file.yaml"); err == nil {
+		t.Errorf("LoadStepsConfig() expected error for missing file")
+	}
+}
+
+func TestBuildSteps(t *testing.T) {
+	enabled := false
+	tests := []struct {
+		name        string
+		stepConfigs []StepConfig
+		wantOrder   []string
+		wantErr     bool
+	}{
+		{
+			name: "Dependencies ordered before dependents",
+			stepConfigs: []StepConfig{
+				{Name: "install-apps", Action: "noop", DependsOn: []string{"store-credentials"}},
+				{Name: "create-namespace", Action: "noop"},
+				{Name: "store-credentials", Action: "noop", DependsOn: []string{"create-namespace"}, Enabled: &enabled},
+			},
+			wantOrder: []string{"create-namespace", "store-credentials", "install-apps"},
+		},
+		{
+			name: "Unknown action",
+			stepConfigs: []StepConfig{
+				{Name: "create-namespace", Action: "unknown"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown dependency",
+			stepConfigs: []StepConfig{
+				{Name: "create-namespace", Action: "noop", DependsOn: []string{"missing"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Duplicate step",
+			stepConfigs: []StepConfig{
+				{Name: "create-namespace", Action: "noop"},
+				{Name: "create-namespace", Action: "noop"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Cyclic dependencies",
+			stepConfigs: []StepConfig{
+				{Name: "step-1", Action: "noop", DependsOn: []string{"step-3"}},
+				{Name: "step-2", Action: "noop", DependsOn: []string{"step-1"}},
+				{Name: "step-3", Action: "noop", DependsOn: []string{"step-2"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Negative retries",
+			stepConfigs: []StepConfig{
+				{Name: "create-namespace", Action: "noop", Retries: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Timeout with retries",
+			stepConfigs: []StepConfig{
+				{Name: "create-namespace", Action: "noop", Retries: 2, Timeout: time.Minute},
+			},
+			wantOrder: []string{"create-namespace"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := BuildSteps(tt.stepConfigs, testActions("noop"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildSteps() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			ordered, err := orderSteps(steps)
+			if err != nil {
+				t.Fatalf("orderSteps() error = %v", err)
+			}
+			for index, step := range ordered {
+				if step.Name != tt.wantOrder[index] {
+					t.Errorf("orderSteps() step %d = %s, want %s", index, step.Name, tt.wantOrder[index])
+				}
+			}
+		})
+	}
+}
+
+func Test_runWithRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		step       Step
+		failTimes  int
+		blockTimes int
+		wantCalls  int
+		wantErr    bool
+	}{
+		{
+			name:      "Succeeds after retries",
+			step:      Step{Name: "step", Retries: 2},
+			failTimes: 2,
+			wantCalls: 3,
+		},
+		{
+			name:      "Fails when retries exhausted",
+			step:      Step{Name: "step", Retries: 1},
+			failTimes: 3,
+			wantCalls: 2,
+			wantErr:   true,
+		},
+		{
+			name:       "Times out",
+			step:       Step{Name: "step", Timeout: 10 * time.Millisecond},
+			blockTimes: 1,
+			wantCalls:  1,
+			wantErr:    true,
+		},
+		{
+			name:       "Timed out attempt cancelled before retry",
+			step:       Step{Name: "step", Retries: 1, Timeout: 10 * time.Millisecond},
+			blockTimes: 1,
+			wantCalls:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls, running int32
+			tt.step.Run = func(ctx context.Context) error {
+				call := atomic.AddInt32(&calls, 1)
+				if atomic.AddInt32(&running, 1) > 1 {
+					t.Errorf("attempt %d overlaps a previous attempt", call)
+				}
+				defer atomic.AddInt32(&running, -1)
+
+				if int(call) <= tt.blockTimes {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(time.Second):
+						t.Errorf("attempt %d not cancelled after the timeout", call)
+						return errors.New("step failed")
+					}
+				}
+				if int(call) <= tt.failTimes {
+					return errors.New("step failed")
+				}
+				return nil
+			}
+			if err := runWithRetries(tt.step); (err != nil) != tt.wantErr {
+				t.Errorf("runWithRetries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := int(atomic.LoadInt32(&calls)); got != tt.wantCalls {
+				t.Errorf("runWithRetries() calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
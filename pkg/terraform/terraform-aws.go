@@ -3,16 +3,20 @@ package terraform
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/hc-install/product"
 	"github.com/hashicorp/hc-install/releases"
 	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
 	"github.com/pkg/errors"
 
 	"capten/pkg/clog"
 	"capten/pkg/config"
+	"capten/pkg/output"
 	"capten/pkg/types"
 )
 
@@ -46,19 +50,37 @@ func NewAws(captenConfig config.CaptenConfig, config types.AWSClusterInfo) (*ter
 
 	tf.SetLogger(clog.Logger)
 	//set the output files, defaulted to terminal
-	tf.SetStdout(os.Stdout)
+	tf.SetStdout(terraformStdout())
 	tf.SetStderr(os.Stderr)
 	return &terraform{config: config, exec: tf, captenConfig: captenConfig}, nil
 }
 
+// terraformStdout keeps stdout for the rendered document when the output format is json or yaml
+func terraformStdout() io.Writer {
+	if output.IsTable() {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
 func (t *terraform) initAws() error {
 
 	backendConfigOptionsStr := []string{
 		"region=" + t.config.Region,
-		"access_key=" + t.config.AwsAccessKey,
-		"secret_key=" + t.config.AwsSecretKey,
 	}
 	backendConfigOptionsStr = append(backendConfigOptionsStr, t.config.TerraformBackendConfigs...)
+
+	// credentials are passed only in the env of the terraform commands, backend config options
+	// are visible in the process list and are saved in the .terraform directory
+	if len(t.config.AwsAccessKey) != 0 {
+		if err := t.exec.SetEnv(terraformEnv(map[string]string{
+			"AWS_ACCESS_KEY_ID":     t.config.AwsAccessKey,
+			"AWS_SECRET_ACCESS_KEY": t.config.AwsSecretKey,
+		})); err != nil {
+			return errors.WithMessage(err, "error setting aws credentials env")
+		}
+	}
+
 	initOptions := make([]tfexec.InitOption, 0)
 	for _, backendConfigOption := range backendConfigOptionsStr {
 		initOptions = append(initOptions, tfexec.BackendConfig(backendConfigOption))
@@ -73,6 +95,24 @@ func (t *terraform) initAws() error {
 	return nil
 }
 
+// terraformEnv returns the process env with the given env added, SetEnv replaces the env of the
+// terraform commands. The env managed by tfexec is left out, SetEnv refuses it
+func terraformEnv(env map[string]string) map[string]string {
+	processEnv := map[string]string{}
+	for _, keyValue := range os.Environ() {
+		if key, value, ok := strings.Cut(keyValue, "="); ok {
+			processEnv[key] = value
+		}
+	}
+	for _, key := range tfexec.ProhibitedEnv(processEnv) {
+		delete(processEnv, key)
+	}
+	for key, value := range env {
+		processEnv[key] = value
+	}
+	return processEnv
+}
+
 func (t *terraform) initCommon() error {
 	if t.captenConfig.CloudService == "azure" {
 		err := t.initAzure()
@@ -98,7 +138,7 @@ func (t *terraform) Apply() error {
 		return errors.WithMessage(err, "error running show")
 	}
 
-	varFile := fmt.Sprintf("%s%s%s", t.captenConfig.CurrentDirPath, t.captenConfig.TerraformTemplateDirPath, t.captenConfig.TerraformVarFileName)
+	varFile := t.captenConfig.PrepareFilePath(t.captenConfig.TerraformTemplateDirPath, t.captenConfig.TerraformVarFileName)
 	_, err = t.exec.Plan(context.Background(), tfexec.VarFile(varFile))
 	if err != nil {
 		return errors.WithMessage(err, "error running plan")
@@ -120,7 +160,121 @@ func (t *terraform) Destroy() error {
 		return errors.WithMessage(err, "error running show")
 	}
 
-	varFile := fmt.Sprintf("%s%s%s", t.captenConfig.CurrentDirPath, t.captenConfig.TerraformTemplateDirPath, t.captenConfig.TerraformVarFileName)
+	varFile := t.captenConfig.PrepareFilePath(t.captenConfig.TerraformTemplateDirPath, t.captenConfig.TerraformVarFileName)
 	return t.exec.Destroy(context.Background(), tfexec.VarFile(varFile))
 }
 
+func (t *terraform) Plan(planFile string) (*types.ClusterPlanSummary, error) {
+	if err := t.initCommon(); err != nil {
+		return nil, err
+	}
+
+	varFile := t.captenConfig.PrepareFilePath(t.captenConfig.TerraformTemplateDirPath, t.captenConfig.TerraformVarFileName)
+	_, err := t.exec.Plan(context.Background(), tfexec.VarFile(varFile), tfexec.Out(planFile))
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running plan")
+	}
+
+	// the plan file contains the variable values including the cloud credentials
+	if err := os.Chmod(planFile, 0600); err != nil {
+		return nil, errors.WithMessage(err, "error restricting plan file permissions")
+	}
+
+	plan, err := t.exec.ShowPlanFile(context.Background(), planFile)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running show plan")
+	}
+	return summarizePlan(plan), nil
+}
+
+func (t *terraform) ApplyPlan(planFile string) error {
+	if err := t.initCommon(); err != nil {
+		return err
+	}
+
+	if err := t.exec.Apply(context.Background(), tfexec.DirOrPlan(planFile)); err != nil {
+		return errors.WithMessage(err, "error running apply")
+	}
+	return nil
+}
+
+func (t *terraform) Status() (string, error) {
+	if err := t.initCommon(); err != nil {
+		return "", err
+	}
+
+	state, err := t.exec.Show(context.Background())
+	if err != nil {
+		return "", errors.WithMessage(err, "error running show")
+	}
+
+	if state == nil || state.Values == nil || state.Values.RootModule == nil {
+		return "not provisioned", nil
+	}
+	return fmt.Sprintf("provisioned, %d resources", countStateResources(state.Values.RootModule)), nil
+}
+
+func (t *terraform) Outputs() (map[string]string, error) {
+	if err := t.initCommon(); err != nil {
+		return nil, err
+	}
+
+	outputs, err := t.exec.Output(context.Background())
+	if err != nil {
+		return nil, errors.WithMessage(err, "error running output")
+	}
+
+	values := map[string]string{}
+	for name, output := range outputs {
+		if output.Sensitive {
+			values[name] = "<sensitive>"
+			continue
+		}
+		values[name] = strings.Trim(string(output.Value), "\"")
+	}
+	return values, nil
+}
+
+func countStateResources(module *tfjson.StateModule) int {
+	count := len(module.Resources)
+	for _, childModule := range module.ChildModules {
+		count += countStateResources(childModule)
+	}
+	return count
+}
+
+func summarizePlan(plan *tfjson.Plan) *types.ClusterPlanSummary {
+	summary := &types.ClusterPlanSummary{ResourceChanges: []types.ClusterResourceChange{}}
+	for _, resourceChange := range plan.ResourceChanges {
+		if resourceChange.Change == nil {
+			continue
+		}
+
+		var action string
+		actions := resourceChange.Change.Actions
+		switch {
+		case actions.Replace():
+			action = "replace"
+			summary.Add++
+			summary.Destroy++
+		case actions.Create():
+			action = "create"
+			summary.Add++
+		case actions.Update():
+			action = "update"
+			summary.Change++
+		case actions.Delete():
+			action = "destroy"
+			summary.Destroy++
+		default:
+			continue
+		}
+
+		summary.ResourceChanges = append(summary.ResourceChanges, types.ClusterResourceChange{
+			Address:      resourceChange.Address,
+			ResourceType: resourceChange.Type,
+			Action:       action,
+		})
+	}
+	return summary
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"capten/pkg/agent/pb/agentpb"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
@@ -54,6 +55,32 @@ type AppConfig struct {
 	APIEndpoint         string                 `yaml:"APIEndpoint"`
 	UIModuleEndpoint    string                 `yaml:"UIModuleEndpoint"`
 	InstallStatus       string                 `yaml:"InstallStatus"`
+	DependsOn           []string               `yaml:"DependsOn"`
+	SetValues           []string               `yaml:"-"`
+}
+
+type AppReleaseRevision struct {
+	Revision     int       `json:"revision" yaml:"revision"`
+	ChartVersion string    `json:"chartVersion" yaml:"chartVersion"`
+	AppVersion   string    `json:"appVersion" yaml:"appVersion"`
+	Status       string    `json:"status" yaml:"status"`
+	Updated      time.Time `json:"updated" yaml:"updated"`
+	Description  string    `json:"description" yaml:"description"`
+}
+
+type AppReleaseStatus struct {
+	Name         string `json:"name" yaml:"name"`
+	Namespace    string `json:"namespace" yaml:"namespace"`
+	ChartVersion string `json:"chartVersion,omitempty" yaml:"chartVersion,omitempty"`
+	Status       string `json:"status" yaml:"status"`
+	Error        string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type AppDiff struct {
+	Name         string
+	Installed    bool
+	ValuesDiff   string
+	ManifestDiff string
 }
 
 type AWSClusterInfo struct {
@@ -134,3 +161,16 @@ type CredentialAppConfig struct {
 	CredentialType       string   `yaml:"credentialType"`
 	UserName             string   `yaml:"userName"`
 }
+
+type ClusterPlanSummary struct {
+	Add             int
+	Change          int
+	Destroy         int
+	ResourceChanges []ClusterResourceChange
+}
+
+type ClusterResourceChange struct {
+	Address      string
+	ResourceType string
+	Action       string
+}
@@ -0,0 +1,254 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"capten"
+	"capten/pkg/config"
+	"capten/pkg/types"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	clusterNameRegex   = regexp.MustCompile(`^[a-z][a-z0-9-]{0,19}$`)
+	ErrWorkspaceExists = errors.New("workspace already exists")
+)
+
+type Options struct {
+	ClusterName       string
+	CloudService      string
+	ClusterType       string
+	DomainName        string
+	Region            string
+	InstanceType      string
+	MasterCount       int
+	WorkerCount       int
+	SocialIntegration string
+	SlackURL          string
+	SlackChannel      string
+	TeamsURL          string
+}
+
+type workspaceFile struct {
+	path string
+	data []byte
+}
+
+func DefaultOptions(cloudService string) Options {
+	options := Options{
+		ClusterName:  "capten",
+		CloudService: cloudService,
+		ClusterType:  "talos",
+		DomainName:   "dev.intelops.app",
+	}
+	switch cloudService {
+	case "aws":
+		options.Region, options.InstanceType = "us-west-2", "m6i.xlarge"
+		options.MasterCount, options.WorkerCount = 1, 5
+	case "azure":
+		options.Region, options.InstanceType = "centralindia", "Standard_D4_v3"
+		options.MasterCount, options.WorkerCount = 3, 5
+	}
+	return options
+}
+
+func (o Options) Validate() error {
+	validationErrors := []string{}
+	if err := ValidateClusterName(o.ClusterName); err != nil {
+		validationErrors = append(validationErrors, err.Error())
+	}
+	if len(o.CloudService) == 0 {
+		validationErrors = append(validationErrors, "cloud service is required")
+	}
+	if len(o.Region) == 0 {
+		validationErrors = append(validationErrors, "region is required")
+	}
+	if len(o.InstanceType) == 0 {
+		validationErrors = append(validationErrors, "instance type is required")
+	}
+	if o.MasterCount < 1 || o.WorkerCount < 1 {
+		validationErrors = append(validationErrors, "master and worker node counts must be at least 1")
+	}
+	switch o.SocialIntegration {
+	case "slack":
+		if len(o.SlackURL) == 0 || len(o.SlackChannel) == 0 {
+			validationErrors = append(validationErrors, "slack url and channel are required for slack integration")
+		}
+	case "teams":
+		if len(o.TeamsURL) == 0 {
+			validationErrors = append(validationErrors, "teams url is required for teams integration")
+		}
+	}
+
+	if err := o.clusterValues().Validate(); err != nil {
+		validationErrors = append(validationErrors, err.Error())
+	}
+	if len(validationErrors) != 0 {
+		return fmt.Errorf("invalid workspace options: %s", strings.Join(validationErrors, "; "))
+	}
+	return nil
+}
+
+func ValidateClusterName(name string) error {
+	if !clusterNameRegex.MatchString(name) {
+		return fmt.Errorf("cluster name '%s' must start with a letter and contain up to 20 lower case letters, digits or '-'", name)
+	}
+	return nil
+}
+
+// Init writes the config files of a new workspace, existing files are only overwritten with force
+func Init(captenConfig config.CaptenConfig, options Options, force bool) ([]string, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	files, err := workspaceFiles(captenConfig, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if !force {
+		existingFiles := []string{}
+		for _, file := range files {
+			if _, err := os.Stat(file.path); err == nil {
+				existingFiles = append(existingFiles, file.path)
+			}
+		}
+		if len(existingFiles) != 0 {
+			return nil, errors.WithMessagef(ErrWorkspaceExists, "found %s", strings.Join(existingFiles, ", "))
+		}
+	}
+
+	writtenFiles := []string{}
+	for _, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file.path), 0755); err != nil {
+			return writtenFiles, errors.WithMessagef(err, "failed to create directory %s", filepath.Dir(file.path))
+		}
+		if err := os.WriteFile(file.path, file.data, 0644); err != nil {
+			return writtenFiles, errors.WithMessagef(err, "failed to write %s", file.path)
+		}
+		writtenFiles = append(writtenFiles, file.path)
+	}
+	return writtenFiles, nil
+}
+
+func workspaceFiles(captenConfig config.CaptenConfig, options Options) ([]workspaceFile, error) {
+	var clusterInfo interface{}
+	var templateFileName string
+	switch options.CloudService {
+	case "aws":
+		clusterInfo, templateFileName = options.awsClusterInfo(), captenConfig.AWSTerraformTemplateFileName
+	case "azure":
+		clusterInfo, templateFileName = options.azureClusterInfo(), captenConfig.AzureTerraformTemplateFileName
+	default:
+		return nil, fmt.Errorf("cloud service '%s' is not supported", options.CloudService)
+	}
+
+	files := []workspaceFile{}
+	for _, values := range []struct {
+		path  string
+		value interface{}
+	}{
+		{captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.CaptenGlobalValuesFileName), options.clusterValues()},
+		{captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.CaptenHostValuesFileName), config.CaptenClusterHost{}},
+		{captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, options.CloudService+"_config.yaml"), clusterInfo},
+	} {
+		data, err := yaml.Marshal(values.value)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to marshal %s", values.path)
+		}
+		files = append(files, workspaceFile{path: values.path, data: data})
+	}
+
+	for _, defaultFile := range []struct {
+		path         string
+		embeddedPath string
+	}{
+		{captenConfig.PrepareFilePath(captenConfig.ConfigDirPath, captenConfig.SetupAppsConfigFile), "config/setup_apps.yaml"},
+		{captenConfig.PrepareFilePath(captenConfig.TerraformTemplateDirPath, templateFileName), "templates/k3s/values." + options.CloudService + ".tmpl"},
+	} {
+		data, err := capten.DefaultFiles.ReadFile(defaultFile.embeddedPath)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed to read default file %s", defaultFile.embeddedPath)
+		}
+		files = append(files, workspaceFile{path: defaultFile.path, data: data})
+	}
+	return files, nil
+}
+
+func (o Options) clusterValues() config.CaptenClusterValues {
+	return config.CaptenClusterValues{
+		DomainName:        o.DomainName,
+		CloudService:      o.CloudService,
+		ClusterType:       o.ClusterType,
+		SocialIntegration: o.SocialIntegration,
+		SlackURL:          o.SlackURL,
+		SlackChannel:      o.SlackChannel,
+		TeamsURL:          o.TeamsURL,
+	}
+}
+
+func (o Options) awsClusterInfo() types.AWSClusterInfo {
+	return types.AWSClusterInfo{
+		AwsAccessKey:          "${env:AWS_ACCESS_KEY_ID}",
+		AwsSecretKey:          "${env:AWS_SECRET_ACCESS_KEY}",
+		AlbName:               o.ClusterName + "-alb",
+		PrivateSubnet:         "192.0.1.0/24",
+		Region:                o.Region,
+		SecurityGroupName:     o.ClusterName + "-sg",
+		VpcCidr:               "192.0.0.0/16",
+		VpcName:               o.ClusterName + "-vpc",
+		InstanceType:          o.InstanceType,
+		NodeMonitoringEnabled: "false",
+		MasterCount:           fmt.Sprint(o.MasterCount),
+		WorkerCount:           fmt.Sprint(o.WorkerCount),
+		TraefikHttpPort:       "32080",
+		TraefikHttpsPort:      "32443",
+		TalosTg:               o.ClusterName + "-tg",
+		TraefikTg80Name:       o.ClusterName + "-tg-80",
+		TraefikTg443Name:      o.ClusterName + "-tg-443",
+		TraefikLbName:         o.ClusterName + "-traefik-lb",
+		TerraformBackendConfigs: []string{
+			"bucket=" + o.ClusterName + "-talos-state",
+			"dynamodb_table=" + o.ClusterName + "-tf-state",
+		},
+		Nats_client_port:  "31675",
+		Nats_tg_4222_name: o.ClusterName + "-tg-4222",
+	}
+}
+
+func (o Options) azureClusterInfo() types.AzureClusterInfo {
+	// storage account names only allow lower case letters and digits
+	storageName := strings.ReplaceAll(o.ClusterName, "-", "")
+	return types.AzureClusterInfo{
+		Region:               o.Region,
+		MasterCount:          nodeNames(o.ClusterName+"-master", o.MasterCount),
+		WorkerCount:          nodeNames(o.ClusterName+"-worker", o.WorkerCount),
+		NICs:                 nodeNames(o.ClusterName+"-nic-master", o.MasterCount),
+		WorkerNics:           nodeNames(o.ClusterName+"-nic-worker", o.WorkerCount),
+		InstanceType:         o.InstanceType,
+		PublicIPName:         nodeNames(o.ClusterName+"-public-ip-", o.MasterCount),
+		TraefikHttpPort:      32080,
+		TraefikHttpsPort:     32443,
+		Talosrgname:          o.ClusterName + "-rg",
+		Storagergname:        o.ClusterName + "-storage-rg",
+		Storage_account_name: storageName + "images",
+		Talos_imagecont_name: storageName + "imagecont",
+		Talos_cluster_name:   o.ClusterName,
+		Nats_client_port:     31675,
+	}
+}
+
+func nodeNames(prefix string, count int) []string {
+	names := []string{}
+	for index := 1; index <= count; index++ {
+		names = append(names, fmt.Sprintf("%s%d", prefix, index))
+	}
+	return names
+}
@@ -0,0 +1,84 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"capten/pkg/config"
+	"capten/pkg/types"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		update  func(*Options)
+		wantErr bool
+	}{
+		{"default aws options", func(o *Options) {}, false},
+		{"default azure options", func(o *Options) { *o = DefaultOptions("azure") }, false},
+		{"invalid cluster name", func(o *Options) { o.ClusterName = "Capten_1" }, true},
+		{"invalid domain name", func(o *Options) { o.DomainName = "intelops" }, true},
+		{"missing region", func(o *Options) { o.Region = "" }, true},
+		{"no worker nodes", func(o *Options) { o.WorkerCount = 0 }, true},
+		{"slack without channel", func(o *Options) { o.SocialIntegration, o.SlackURL = "slack", "https://hooks.slack.com" }, true},
+		{"teams", func(o *Options) { o.SocialIntegration, o.TeamsURL = "teams", "https://teams.webhook" }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := DefaultOptions("aws")
+			tt.update(&options)
+			if err := options.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInit(t *testing.T) {
+	captenConfig := config.CaptenConfig{
+		CurrentDirPath:                 t.TempDir(),
+		ConfigDirPath:                  "/config/",
+		TerraformTemplateDirPath:       "/templates/k3s/",
+		CaptenGlobalValuesFileName:     "capten.yaml",
+		CaptenHostValuesFileName:       "capten-lb-endpoint.yaml",
+		SetupAppsConfigFile:            "setup_apps.yaml",
+		AzureTerraformTemplateFileName: "values.azure.tmpl",
+	}
+	options := DefaultOptions("azure")
+	options.ClusterName = "dev-1"
+	options.MasterCount = 2
+
+	files, err := Init(captenConfig, options, false)
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if len(files) != 5 {
+		t.Errorf("Init() created %v, want 5 files", files)
+	}
+
+	data, err := os.ReadFile(filepath.Join(captenConfig.CurrentDirPath, "config", "azure_config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var clusterInfo types.AzureClusterInfo
+	if err := yaml.Unmarshal(data, &clusterInfo); err != nil {
+		t.Fatal(err)
+	}
+	if len(clusterInfo.MasterCount) != 2 || clusterInfo.NICs[1] != "dev-1-nic-master2" || clusterInfo.Storage_account_name != "dev1images" {
+		t.Errorf("azure cluster info = %+v", clusterInfo)
+	}
+	if _, err := os.Stat(filepath.Join(captenConfig.CurrentDirPath, "templates", "k3s", "values.azure.tmpl")); err != nil {
+		t.Errorf("template file not created, %v", err)
+	}
+
+	if _, err := Init(captenConfig, options, false); !errors.Is(err, ErrWorkspaceExists) {
+		t.Errorf("Init() error = %v, want %v", err, ErrWorkspaceExists)
+	}
+	if _, err := Init(captenConfig, options, true); err != nil {
+		t.Errorf("Init() with force error = %v", err)
+	}
+}